@@ -27,6 +27,9 @@ func (t TerminalType) String() string {
 }
 
 func CheckExprConsistency(expr definition.Expr) (TerminalType, map[string]struct{}, error) {
+	if expr == nil {
+		return 0, nil, fmt.Errorf("expression is missing")
+	}
 	if _, ok := expr.(definition.Terminals); !ok {
 		terminalType := AnyTerminalType
 		children := expr.Children()
@@ -55,14 +58,21 @@ func CheckExprConsistency(expr definition.Expr) (TerminalType, map[string]struct
 		}
 		return terminalType, ruleNames, nil
 	}
-	switch expr.(type) {
+	switch peg := expr.(type) {
 	case definition.Empty:
 		return AnyTerminalType, nil, nil
 	case definition.Dot:
 		return AnyTerminalType, nil, nil
 	case definition.TextToken:
 		return ByteTerminalType, nil, nil
+	case definition.FoldedToken:
+		return ByteTerminalType, nil, nil
+	case definition.CharClass:
+		return ByteTerminalType, nil, nil
 	case definition.TextPattern:
+		if peg.Regex == nil {
+			return 0, nil, fmt.Errorf("regex of the pattern '%v' is not compiled", peg.Expr)
+		}
 		return ByteTerminalType, nil, nil
 	case definition.AtomPattern:
 		return AtomTerminalType, nil, nil
@@ -70,8 +80,10 @@ func CheckExprConsistency(expr definition.Expr) (TerminalType, map[string]struct
 		return AnyTerminalType, nil, nil
 	case definition.EndOfFile:
 		return AnyTerminalType, nil, nil
+	case definition.InvalidUTF8:
+		return ByteTerminalType, nil, nil
 	default:
-		panic(fmt.Errorf("unexpected peg expression type: %v", expr))
+		return 0, nil, fmt.Errorf("unexpected peg expression type: %#v", expr)
 	}
 }
 
@@ -15,6 +15,27 @@ func TestConsistency(t *testing.T) {
 		require.Nil(t, err)
 		require.Equal(t, ByteTerminalType, terminalType)
 	})
+	t.Run("folded tokens", func(t *testing.T) {
+		terminalType, err := CheckRulesConsistency(definition.Rules{
+			definition.NewRule("A", definition.NewJunction(definition.NewFoldedToken("select"), definition.NewDot())),
+		})
+		require.Nil(t, err)
+		require.Equal(t, ByteTerminalType, terminalType)
+		_, err = CheckRulesConsistency(definition.Rules{
+			definition.NewRule("A", definition.NewJunction(
+				definition.NewFoldedToken("select"),
+				definition.NewAtomPattern(map[string]definition.TextTerminals{"A": nil}),
+			)),
+		})
+		require.NotNil(t, err)
+	})
+	t.Run("char classes", func(t *testing.T) {
+		terminalType, err := CheckRulesConsistency(definition.Rules{
+			definition.NewRule("A", definition.NewJunction(definition.NewCharClass("[a-z]"), definition.NewDot())),
+		})
+		require.Nil(t, err)
+		require.Equal(t, ByteTerminalType, terminalType)
+	})
 	t.Run("only atoms", func(t *testing.T) {
 		terminalType, err := CheckRulesConsistency(definition.Rules{
 			definition.NewRule("A", definition.NewAtomPattern(map[string]definition.TextTerminals{})),
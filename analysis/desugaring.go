@@ -30,6 +30,8 @@ func CheckDesugaredExpr(expr definition.Expr) error {
 		return CheckDesugaredExpr(peg.Expr)
 	case definition.Negation:
 		return CheckDesugaredExpr(peg.Expr)
+	case definition.Recovery:
+		return CheckDesugaredExprs(peg.Children())
 	default:
 		return nil
 	}
@@ -68,7 +70,22 @@ func DesugarExpr(expr definition.Expr) definition.ExprCore {
 		for i := 0; i < int(peg.Min); i++ {
 			exprs = append(exprs, desugared)
 		}
-		return definition.Junction{Exprs: append(exprs, definition.Kleene{Expr: desugared})}
+		if peg.Max == 0 {
+			return definition.Junction{Exprs: append(exprs, definition.Kleene{Expr: desugared})}
+		}
+		// optional occurrences are nested in order to try next occurrence only after the previous one matched
+		var optional definition.Expr
+		for i := peg.Min; i < peg.Max; i++ {
+			if optional == nil {
+				optional = definition.Choice{Exprs: []definition.Expr{desugared, definition.Empty{}}}
+			} else {
+				optional = definition.Choice{Exprs: []definition.Expr{definition.Junction{Exprs: []definition.Expr{desugared, optional}}, definition.Empty{}}}
+			}
+		}
+		if optional != nil {
+			exprs = append(exprs, optional)
+		}
+		return definition.Junction{Exprs: exprs}
 	case definition.Junction:
 		return definition.Junction{Exprs: DesugarExprs(peg.Exprs)}
 	case definition.Choice:
@@ -77,6 +94,8 @@ func DesugarExpr(expr definition.Expr) definition.ExprCore {
 		return definition.Kleene{Expr: DesugarExpr(peg.Expr)}
 	case definition.Negation:
 		return definition.Negation{Expr: DesugarExpr(peg.Expr)}
+	case definition.Recovery:
+		return definition.Recovery{Expr: DesugarExpr(peg.Expr), Sync: DesugarExpr(peg.Sync)}
 	case definition.ExprCore:
 		return peg
 	default:
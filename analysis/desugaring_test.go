@@ -33,3 +33,27 @@ func TestDesugar(t *testing.T) {
 	)
 	t.Logf("\ninitial:\n%v\ndesugared:\n%v", r, d)
 }
+
+func TestDesugarBoundedRepetition(t *testing.T) {
+	r := definition.NewRule("S", definition.NewJunction(
+		definition.NewRepetitionRange(definition.NewSymbol("A"), 2, 2),
+		definition.NewRepetitionRange(definition.NewSymbol("B"), 1, 3),
+	))
+	d := DesugarRule(r)
+	assert.Nil(t, CheckDesugaredRule(d))
+	assert.Equal(t, d,
+		definition.NewRule("S", definition.Junction{Exprs: []definition.Expr{
+			definition.Junction{Exprs: []definition.Expr{definition.Symbol{Name: "A"}, definition.Symbol{Name: "A"}}},
+			definition.Junction{Exprs: []definition.Expr{
+				definition.Symbol{Name: "B"},
+				definition.Choice{Exprs: []definition.Expr{
+					definition.Junction{Exprs: []definition.Expr{
+						definition.Symbol{Name: "B"},
+						definition.Choice{Exprs: []definition.Expr{definition.Symbol{Name: "B"}, definition.Empty{}}},
+					}},
+					definition.Empty{},
+				}},
+			}},
+		}}),
+	)
+}
@@ -40,6 +40,10 @@ func normalizeExpr(generator *nameGenerator, expr definition.Expr) (definition.E
 	case definition.Negation:
 		normalized, rules := prepareExpr(generator, peg.Expr)
 		return definition.Negation{Expr: normalized}, rules
+	case definition.Recovery:
+		normalized, rules := prepareExpr(generator, peg.Expr)
+		sync, syncRules := prepareExpr(generator, peg.Sync)
+		return definition.Recovery{Expr: normalized, Sync: sync}, append(rules, syncRules...)
 	case definition.Junction:
 		var rules definition.Rules
 		children := make([]definition.Expr, 0, len(peg.Exprs))
@@ -110,6 +114,8 @@ func CheckNormalizedRule(rule definition.Rule) error {
 		return checkLeaf(peg.Expr)
 	case definition.Negation:
 		return checkLeaf(peg.Expr)
+	case definition.Recovery:
+		return checkLeafs(peg.Children())
 	case definition.Junction:
 		return checkLeafs(peg.Exprs)
 	case definition.Choice:
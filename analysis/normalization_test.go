@@ -58,3 +58,15 @@ func TestNormalizationWithTerminals(t *testing.T) {
 	})
 	t.Log(r, n)
 }
+
+func TestTransformationSource(t *testing.T) {
+	r := definition.Rules{
+		definition.NewRule("r", definition.NewJunction(definition.NewSymbol("#s"), definition.NewRepetition(definition.NewSymbol("#s")))),
+		definition.NewRule("#s", definition.NewTextToken(" ")),
+	}
+	_, transformation := NormalizeRules(r)
+	assert.Equal(t, "r", transformation.Source("r#0"))
+	assert.Equal(t, "r", transformation.Source("r#1"))
+	assert.Equal(t, "#s", transformation.Source("#s#0"))
+	assert.Equal(t, "unknown#1", transformation.Source("unknown#1"))
+}
@@ -1,5 +1,7 @@
 package analysis
 
+import "strings"
+
 type (
 	Transformation struct{ Forward, Backward map[string]string }
 )
@@ -11,3 +13,16 @@ func NewTransformation(forward map[string]string) Transformation {
 	}
 	return Transformation{Forward: forward, Backward: backward}
 }
+
+// Source returns the name of the original rule from which the normalized rule (root or auxiliary one) originated
+func (t Transformation) Source(normalized string) string {
+	if name, ok := t.Backward[normalized]; ok {
+		return name
+	}
+	if delimiter := strings.LastIndex(normalized, "#"); delimiter != -1 {
+		if _, ok := t.Forward[normalized[:delimiter]]; ok {
+			return normalized[:delimiter]
+		}
+	}
+	return normalized
+}
@@ -0,0 +1,57 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/sivukhin/gopeg/extension"
+	"github.com/sivukhin/gopeg/generator"
+)
+
+const usage = `usage: gopeg generate -grammar grammar.peg [-package name] [-root rule] [-utf8] [-output parser.go]`
+
+func main() {
+	if len(os.Args) < 2 || os.Args[1] != "generate" {
+		fmt.Fprintln(os.Stderr, usage)
+		os.Exit(2)
+	}
+	if err := generate(os.Args[2:]); err != nil {
+		fmt.Fprintf(os.Stderr, "gopeg: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func generate(args []string) error {
+	flags := flag.NewFlagSet("generate", flag.ExitOnError)
+	grammarPath := flags.String("grammar", "", "path to the .peg grammar")
+	packageName := flags.String("package", "parser", "name of the generated package")
+	root := flags.String("root", "", "root rule of the grammar (first rule by default)")
+	output := flags.String("output", "", "path to the generated file (stdout by default)")
+	utf8 := flags.Bool("utf8", false, "make dot consume single UTF-8 encoded character instead of single byte")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *grammarPath == "" {
+		return fmt.Errorf("grammar path is missing\n%v", usage)
+	}
+	// imports and base grammars are resolved relative to the grammar file
+	rules, err := extension.LoadFS(os.DirFS(filepath.Dir(*grammarPath)), filepath.Base(*grammarPath))
+	if err != nil {
+		return fmt.Errorf("unable to load grammar %v: %w", *grammarPath, err)
+	}
+	opts := []generator.Option{generator.WithPackage(*packageName), generator.WithRoot(*root)}
+	if *utf8 {
+		opts = append(opts, generator.WithUTF8())
+	}
+	source, err := generator.Generate(rules, opts...)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(source)
+		return err
+	}
+	return os.WriteFile(*output, source, 0o644)
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateWithImports(t *testing.T) {
+	dir := t.TempDir()
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "common.peg"), []byte("Number: [0-9]+\n"), 0o644))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "base.peg"), []byte("@import \"common.peg\" as c\nValue: c.Number\n"), 0o644))
+	require.Nil(t, os.WriteFile(filepath.Join(dir, "grammar.peg"), []byte("@extends \"base.peg\"\nValue /: \"nil\"\n"), 0o644))
+	output := filepath.Join(dir, "parser.go")
+	require.Nil(t, generate([]string{"-grammar", filepath.Join(dir, "grammar.peg"), "-package", "values", "-output", output}))
+	source, err := os.ReadFile(output)
+	require.Nil(t, err)
+	require.Contains(t, string(source), "package values")
+	require.Contains(t, string(source), "c.Number")
+
+	require.ErrorContains(t, generate([]string{"-grammar", filepath.Join(dir, "missing.peg")}), "unable to read grammar")
+}
@@ -0,0 +1,52 @@
+package definition
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// InlineAnnotation replaces the node with its children
+	InlineAnnotation = "inline"
+	// FlattenAnnotation replaces children with the same symbol by their children (useful for recursive lists)
+	FlattenAnnotation = "flatten"
+	// CollapseSingleChildAnnotation replaces the node with its child if it has exactly one child
+	CollapseSingleChildAnnotation = "collapse-single-child"
+	// RenameAnnotation changes symbol of the node to the annotation argument
+	RenameAnnotation = "rename"
+	// TokenAnnotation removes children of the node, so the whole match becomes a leaf
+	TokenAnnotation = "token"
+	// DropAnnotation removes the node together with its children
+	DropAnnotation = "drop"
+)
+
+type Annotation struct {
+	Name     string
+	Argument string
+}
+
+func NewAnnotation(name string, argument ...string) Annotation {
+	return Annotation{Name: name, Argument: strings.Join(argument, "")}
+}
+
+// ParseAnnotation parses annotation in the form @name or @name(argument)
+func ParseAnnotation(text string) (Annotation, error) {
+	if !strings.HasPrefix(text, "@") {
+		return Annotation{}, fmt.Errorf("annotation '%v' must start with @", text)
+	}
+	name, argument, hasArgument := strings.Cut(text[1:], "(")
+	if !hasArgument {
+		return Annotation{Name: name}, nil
+	}
+	if !strings.HasSuffix(argument, ")") {
+		return Annotation{}, fmt.Errorf("argument of annotation '%v' must be closed with )", text)
+	}
+	return Annotation{Name: name, Argument: strings.TrimSuffix(argument, ")")}, nil
+}
+
+func (a Annotation) String() string {
+	if a.Argument == "" {
+		return "@" + a.Name
+	}
+	return fmt.Sprintf("@%v(%v)", a.Name, a.Argument)
+}
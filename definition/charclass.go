@@ -0,0 +1,129 @@
+package definition
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// RuneRange is the inclusive range of the characters
+type RuneRange struct{ Lo, Hi rune }
+
+// ParseCharClass parses character class like [a-zA-Z_] or [^\]\n]: items are single characters or ranges of characters
+// which can be written as is (including non-ASCII characters) or with escapes \n, \r, \t, \f, \v, \xHH, \uHHHH, \UHHHHHHHH
+// and \\, \], \[, \-, \^ for the characters which have special meaning inside the class
+func ParseCharClass(expr string) (CharClass, error) {
+	if len(expr) < 2 || expr[0] != '[' || expr[len(expr)-1] != ']' {
+		return CharClass{}, fmt.Errorf("character class must be enclosed in brackets: %v", expr)
+	}
+	body := expr[1 : len(expr)-1]
+	class := CharClass{Expr: expr}
+	if strings.HasPrefix(body, "^") {
+		class.Negated = true
+		body = body[1:]
+	}
+	if body == "" {
+		return CharClass{}, fmt.Errorf("character class must not be empty: %v", expr)
+	}
+	ranges := make([]RuneRange, 0)
+	for len(body) > 0 {
+		lo, rest, err := classRune(body)
+		if err != nil {
+			return CharClass{}, fmt.Errorf("invalid character class %v: %w", expr, err)
+		}
+		hi := lo
+		if len(rest) > 1 && rest[0] == '-' {
+			hi, rest, err = classRune(rest[1:])
+			if err != nil {
+				return CharClass{}, fmt.Errorf("invalid character class %v: %w", expr, err)
+			}
+			if hi < lo {
+				return CharClass{}, fmt.Errorf("invalid character class %v: range %q-%q is reversed", expr, lo, hi)
+			}
+		}
+		ranges = append(ranges, RuneRange{Lo: lo, Hi: hi})
+		body = rest
+	}
+	class.Ranges = mergeRanges(ranges)
+	for _, r := range class.Ranges {
+		for c := r.Lo; c <= r.Hi && c < utf8.RuneSelf; c++ {
+			class.ascii[c/64] |= 1 << (c % 64)
+		}
+	}
+	return class, nil
+}
+
+func classRune(s string) (rune, string, error) {
+	if s[0] != '\\' {
+		r, size := utf8.DecodeRuneInString(s)
+		if r == utf8.RuneError && size == 1 {
+			return 0, "", fmt.Errorf("invalid UTF-8 encoding")
+		}
+		return r, s[size:], nil
+	}
+	if len(s) < 2 {
+		return 0, "", fmt.Errorf("trailing backslash")
+	}
+	switch s[1] {
+	case '\\', ']', '[', '-', '^':
+		return rune(s[1]), s[2:], nil
+	case 'n':
+		return '\n', s[2:], nil
+	case 'r':
+		return '\r', s[2:], nil
+	case 't':
+		return '\t', s[2:], nil
+	case 'f':
+		return '\f', s[2:], nil
+	case 'v':
+		return '\v', s[2:], nil
+	case 'x', 'u', 'U':
+		digits := map[byte]int{'x': 2, 'u': 4, 'U': 8}[s[1]]
+		if len(s) < 2+digits {
+			return 0, "", fmt.Errorf("escape \\%c requires %v hex digits", s[1], digits)
+		}
+		code, err := strconv.ParseUint(s[2:2+digits], 16, 32)
+		if err != nil || !utf8.ValidRune(rune(code)) {
+			return 0, "", fmt.Errorf("invalid escape %v", s[:2+digits])
+		}
+		return rune(code), s[2+digits:], nil
+	default:
+		return 0, "", fmt.Errorf("unknown escape \\%c", s[1])
+	}
+}
+
+func mergeRanges(ranges []RuneRange) []RuneRange {
+	sort.Slice(ranges, func(i, j int) bool { return ranges[i].Lo < ranges[j].Lo })
+	merged := make([]RuneRange, 0, len(ranges))
+	for _, r := range ranges {
+		if last := len(merged) - 1; last >= 0 && r.Lo <= merged[last].Hi+1 {
+			merged[last].Hi = max(merged[last].Hi, r.Hi)
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// Contains reports whether the character belongs to the class taking negation into account
+func (e CharClass) Contains(r rune) bool {
+	if r >= 0 && r < utf8.RuneSelf {
+		return (e.ascii[r/64]&(1<<(r%64)) != 0) != e.Negated
+	}
+	k := sort.Search(len(e.Ranges), func(k int) bool { return e.Ranges[k].Hi >= r })
+	return (k < len(e.Ranges) && e.Ranges[k].Lo <= r) != e.Negated
+}
+
+// Match consumes single UTF-8 encoded character; as in regular expressions invalid encoding is treated as a single U+FFFD character
+func (e CharClass) Match(data []byte) (int, bool) {
+	if len(data) == 0 {
+		return 0, false
+	}
+	if data[0] < utf8.RuneSelf {
+		return 1, e.Contains(rune(data[0]))
+	}
+	r, size := utf8.DecodeRune(data)
+	return size, e.Contains(r)
+}
@@ -0,0 +1,57 @@
+package definition
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCharClass(t *testing.T) {
+	for _, c := range []struct {
+		class, text   string
+		advance, look int
+		ok            bool
+	}{
+		{class: "[a-zA-Z_]", text: "Go", advance: 1, look: 1, ok: true},
+		{class: "[a-zA-Z_]", text: "_", advance: 1, look: 1, ok: true},
+		{class: "[a-zA-Z_]", text: "1", advance: 1, look: 1, ok: false},
+		{class: "[a-zA-Z_]", text: "", advance: 0, look: 1, ok: false},
+		{class: "[^a-z]", text: "A", advance: 1, look: 1, ok: true},
+		{class: "[^a-z]", text: "a", advance: 1, look: 1, ok: false},
+		{class: "[^a-z]", text: "я", advance: 2, look: 2, ok: true},
+		{class: "[^a-z]", text: "\xff", advance: 1, look: 1, ok: true},
+		{class: "[^a-z]", text: "\xd1", advance: 1, look: 2, ok: true},
+		{class: "[а-яё]", text: "ёж", advance: 2, look: 2, ok: true},
+		{class: "[а-яё]", text: "Ё", advance: 2, look: 2, ok: false},
+		{class: `[Ѐ-ӿ]`, text: "Ж", advance: 2, look: 2, ok: true},
+		{class: `[\U0001F600-\U0001F64F]`, text: "😀", advance: 4, look: 4, ok: true},
+		{class: `[\]\\\-\^]`, text: "^", advance: 1, look: 1, ok: true},
+		{class: `[\]\\\-\^]`, text: "-", advance: 1, look: 1, ok: true},
+		{class: `[\x00-\x1f]`, text: "\t", advance: 1, look: 1, ok: true},
+		{class: `[^\n]`, text: "\n", advance: 1, look: 1, ok: false},
+		{class: "[a-]", text: "-", advance: 1, look: 1, ok: true},
+		{class: "[-a]", text: "-", advance: 1, look: 1, ok: true},
+	} {
+		advance, look, ok := Examine[byte](NewCharClass(c.class).(Terminals), []byte(c.text), 0)
+		if advance != c.advance || look != c.look || ok != c.ok {
+			t.Errorf("class %v on %q: expected (%v, %v, %v), got (%v, %v, %v)", c.class, c.text, c.advance, c.look, c.ok, advance, look, ok)
+		}
+	}
+	if s := NewCharClass("[^a-z]").String(); s != "[^a-z]" {
+		t.Errorf("unexpected string representation: %v", s)
+	}
+}
+
+func TestParseCharClass(t *testing.T) {
+	class, err := ParseCharClass("[c-fa-dxz-я]")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []RuneRange{{'a', 'f'}, {'x', 'x'}, {'z', 'я'}}; !reflect.DeepEqual(class.Ranges, expected) {
+		t.Errorf("expected ranges %v, got %v", expected, class.Ranges)
+	}
+	for _, invalid := range []string{"a-z", "[a-z", "[]", "[^]", "[z-a]", `[\q]`, `[a\]`, `[\x1]`, `[\U00110000]`, "[\xff]"} {
+		if _, err := ParseCharClass(invalid); err == nil {
+			t.Errorf("expected error for %q", invalid)
+		}
+	}
+}
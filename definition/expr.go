@@ -3,6 +3,7 @@ package definition
 import (
 	"fmt"
 	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 )
@@ -42,6 +43,11 @@ type (
 	Repetition struct {
 		Expr Expr
 		Min  uint
+		Max  uint
+	}
+	Recovery struct {
+		Expr Expr
+		Sync Expr
 	}
 	Symbol struct {
 		Name       string
@@ -50,13 +56,24 @@ type (
 	Empty       struct{}
 	Dot         struct{}
 	TextToken   struct{ Text []byte }
+	FoldedToken struct{ Text []byte }
 	AtomPattern struct{ Matcher map[string]TextTerminals }
 	TextPattern struct {
 		Expr  string
 		Regex *regexp.Regexp
 	}
+	// CharClass matches single character which belongs to the sorted disjoint ranges (or doesn't belong to them when negated);
+	// ASCII characters are looked up in the precomputed bitset
+	CharClass struct {
+		Expr    string
+		Negated bool
+		Ranges  []RuneRange
+		ascii   [2]uint64
+	}
 	StartOfFile struct{}
 	EndOfFile   struct{}
+	// InvalidUTF8 matches single byte which doesn't start valid UTF-8 encoded character
+	InvalidUTF8 struct{}
 )
 
 func (a Atom) SelectString() string {
@@ -102,6 +119,7 @@ func wrapExpr(rootPrecedence int, item Expr) string {
 const (
 	StartOfFileBuiltinSymbol = "@sof"
 	EndOfFileBuiltinSymbol   = "@eof"
+	InvalidUTF8BuiltinSymbol = "@invalid-utf8"
 )
 
 func (e Choice) String() string   { return joinExprs(e.exprPrecedence(), e.Exprs, " / ") }
@@ -112,7 +130,11 @@ func (e Optional) String() string { return wrapExpr(e.exprPrecedence(), e.Expr)
 func (e Kleene) String() string   { return wrapExpr(e.exprPrecedence(), e.Expr) + "*" }
 func (e Repetition) String() string {
 	var suffix string
-	if e.Min == 0 {
+	if e.Max != 0 && e.Max == e.Min {
+		suffix = fmt.Sprintf("{%v}", e.Min)
+	} else if e.Max != 0 {
+		suffix = fmt.Sprintf("{%v,%v}", e.Min, e.Max)
+	} else if e.Min == 0 {
 		suffix = "*"
 	} else if e.Min == 1 {
 		suffix = "+"
@@ -121,6 +143,9 @@ func (e Repetition) String() string {
 	}
 	return wrapExpr(e.exprPrecedence(), e.Expr) + suffix
 }
+func (e Recovery) String() string {
+	return wrapExpr(e.exprPrecedence(), e.Expr) + "~" + wrapExpr(e.exprPrecedence()+1, e.Sync)
+}
 func (e Symbol) String() string {
 	attrs := make([]string, 0)
 	for key, value := range e.Attributes {
@@ -130,6 +155,7 @@ func (e Symbol) String() string {
 			attrs = append(attrs, fmt.Sprintf(`%v:%v`, key, strconv.Quote(string(value))))
 		}
 	}
+	slices.Sort(attrs)
 	if len(attrs) > 0 {
 		return fmt.Sprintf("{%v}:%v", strings.Join(attrs, ", "), e.Name)
 	}
@@ -139,8 +165,11 @@ func (e Empty) String() string       { return "@empty" }
 func (e Dot) String() string         { return "." }
 func (e StartOfFile) String() string { return StartOfFileBuiltinSymbol }
 func (e EndOfFile) String() string   { return EndOfFileBuiltinSymbol }
+func (e InvalidUTF8) String() string { return InvalidUTF8BuiltinSymbol }
 func (e TextPattern) String() string { return "=~" + strconv.Quote(e.Expr) }
 func (e TextToken) String() string   { return strconv.Quote(string(e.Text)) }
+func (e FoldedToken) String() string { return strconv.Quote(string(e.Text)) + "i" }
+func (e CharClass) String() string   { return e.Expr }
 func (e AtomPattern) String() string {
 	attributes := make([]string, 0)
 	for attributeKey, attributeMatcher := range e.Matcher {
@@ -150,6 +179,7 @@ func (e AtomPattern) String() string {
 			attributes = append(attributes, fmt.Sprintf("%v:%v", attributeKey, attributeMatcher))
 		}
 	}
+	slices.Sort(attributes)
 	return fmt.Sprintf("{%v}", strings.Join(attributes, ", "))
 }
 
@@ -160,14 +190,18 @@ func (e Ensure) exprPrecedence() int      { return 3 }
 func (e Optional) exprPrecedence() int    { return 4 }
 func (e Kleene) exprPrecedence() int      { return 4 }
 func (e Repetition) exprPrecedence() int  { return 4 }
+func (e Recovery) exprPrecedence() int    { return 4 }
 func (e Symbol) exprPrecedence() int      { return 5 }
 func (e Empty) exprPrecedence() int       { return 5 }
 func (e Dot) exprPrecedence() int         { return 5 }
 func (e TextToken) exprPrecedence() int   { return 5 }
+func (e FoldedToken) exprPrecedence() int { return 5 }
 func (e TextPattern) exprPrecedence() int { return 5 }
+func (e CharClass) exprPrecedence() int   { return 5 }
 func (e AtomPattern) exprPrecedence() int { return 5 }
 func (e StartOfFile) exprPrecedence() int { return 5 }
 func (e EndOfFile) exprPrecedence() int   { return 5 }
+func (e InvalidUTF8) exprPrecedence() int { return 5 }
 
 func (e Choice) Children() []Expr      { return e.Exprs }
 func (e Junction) Children() []Expr    { return e.Exprs }
@@ -176,35 +210,46 @@ func (e Ensure) Children() []Expr      { return []Expr{e.Expr} }
 func (e Optional) Children() []Expr    { return []Expr{e.Expr} }
 func (e Kleene) Children() []Expr      { return []Expr{e.Expr} }
 func (e Repetition) Children() []Expr  { return []Expr{e.Expr} }
+func (e Recovery) Children() []Expr    { return []Expr{e.Expr, e.Sync} }
 func (e Symbol) Children() []Expr      { return nil }
 func (e Empty) Children() []Expr       { return nil }
 func (e Dot) Children() []Expr         { return nil }
 func (e TextToken) Children() []Expr   { return nil }
+func (e FoldedToken) Children() []Expr { return nil }
 func (e TextPattern) Children() []Expr { return nil }
+func (e CharClass) Children() []Expr   { return nil }
 func (e AtomPattern) Children() []Expr { return nil }
 func (e StartOfFile) Children() []Expr { return nil }
 func (e EndOfFile) Children() []Expr   { return nil }
+func (e InvalidUTF8) Children() []Expr { return nil }
 
 func (e Choice) exprCore()      {}
 func (e Junction) exprCore()    {}
 func (e Negation) exprCore()    {}
 func (e Kleene) exprCore()      {}
+func (e Recovery) exprCore()    {}
 func (e Symbol) exprCore()      {}
 func (e Empty) exprCore()       {}
 func (e Dot) exprCore()         {}
 func (e TextToken) exprCore()   {}
+func (e FoldedToken) exprCore() {}
 func (e TextPattern) exprCore() {}
+func (e CharClass) exprCore()   {}
 func (e AtomPattern) exprCore() {}
 func (e StartOfFile) exprCore() {}
 func (e EndOfFile) exprCore()   {}
+func (e InvalidUTF8) exprCore() {}
 
 func (e Empty) isTerminal()       {}
 func (e Dot) isTerminal()         {}
 func (e TextToken) isTerminal()   {}
+func (e FoldedToken) isTerminal() {}
 func (e TextPattern) isTerminal() {}
+func (e CharClass) isTerminal()   {}
 func (e AtomPattern) isTerminal() {}
 func (e StartOfFile) isTerminal() {}
 func (e EndOfFile) isTerminal()   {}
+func (e InvalidUTF8) isTerminal() {}
 
 func NewEmpty() Expr { return Empty{} }
 func NewDot() Expr   { return Dot{} }
@@ -221,8 +266,14 @@ func NewChoice(exprs ...Expr) Expr {
 	return exprs[0]
 }
 func NewRepetition(expr Expr) Expr          { return Kleene{expr} }
-func NewRepetitionN(expr Expr, n uint) Expr { return Repetition{expr, n} }
+func NewRepetitionN(expr Expr, n uint) Expr { return Repetition{Expr: expr, Min: n} }
 func NewNegation(expr Expr) Expr            { return Negation{expr} }
+func NewRecovery(expr, sync Expr) Expr      { return Recovery{Expr: expr, Sync: sync} }
+
+// NewRepetitionRange creates repetition of the expression from min to max times; zero max means that repetition is unbounded
+func NewRepetitionRange(expr Expr, min, max uint) Expr {
+	return Repetition{Expr: expr, Min: min, Max: max}
+}
 func NewSymbol(s string, attrsOpt ...map[string][]byte) Symbol {
 	var attrs map[string][]byte
 	if len(attrsOpt) > 0 {
@@ -233,10 +284,22 @@ func NewSymbol(s string, attrsOpt ...map[string][]byte) Symbol {
 func NewOptional(expr Expr) Expr     { return Optional{expr} }
 func NewEnsure(expr Expr) Expr       { return Ensure{expr} }
 func NewTextToken(token string) Expr { return TextToken{Text: []byte(token)} }
+
+// NewFoldedToken creates token which is matched case-insensitively under Unicode simple case folding; it is written as "token"i in the grammar
+func NewFoldedToken(token string) Expr { return FoldedToken{Text: []byte(token)} }
 func NewTextPattern(regex string) Expr {
 	regex = "^" + strings.TrimPrefix(regex, "^")
 	return TextPattern{Expr: regex, Regex: regexp.MustCompile(regex)}
 }
+
+// NewCharClass creates character class from its definition like [a-zA-Z_] and panics if the definition is invalid (see ParseCharClass)
+func NewCharClass(class string) Expr {
+	parsed, err := ParseCharClass(class)
+	if err != nil {
+		panic(err)
+	}
+	return parsed
+}
 func NewAtomPattern(matcher map[string]TextTerminals) Expr {
 	return AtomPattern{Matcher: matcher}
 }
@@ -26,7 +26,7 @@ func TestExprString(t *testing.T) {
 		).String())
 	})
 	t.Run("all node types", func(t *testing.T) {
-		require.Equal(t, `@empty / . / "a" / {Text:=~"^[0-9]+$"} / {Text:"test"} / =~"^[0-9]*" / ("a" "b")* / ("a" "b")+ / ("a" "b"){2,} / ("a" "b")? / &"a"* / (&"a")* / !"a"* / (!"a")* / A`, NewChoice(
+		require.Equal(t, `@empty / . / "a" / {Text:=~"^[0-9]+$"} / {Text:"test"} / =~"^[0-9]*" / ("a" "b")* / ("a" "b")+ / ("a" "b"){2,} / ("a" "b"){2} / ("a" "b"){0,3} / ("a" "b")? / &"a"* / (&"a")* / !"a"* / (!"a")* / ("a" "b")~";" / A`, NewChoice(
 			NewEmpty(),
 			NewDot(),
 			NewTextToken("a"),
@@ -36,11 +36,14 @@ func TestExprString(t *testing.T) {
 			NewRepetition(NewJunction(NewTextToken("a"), NewTextToken("b"))),
 			NewRepetitionN(NewJunction(NewTextToken("a"), NewTextToken("b")), 1),
 			NewRepetitionN(NewJunction(NewTextToken("a"), NewTextToken("b")), 2),
+			NewRepetitionRange(NewJunction(NewTextToken("a"), NewTextToken("b")), 2, 2),
+			NewRepetitionRange(NewJunction(NewTextToken("a"), NewTextToken("b")), 0, 3),
 			NewOptional(NewJunction(NewTextToken("a"), NewTextToken("b"))),
 			NewEnsure(NewRepetition(NewTextToken("a"))),
 			NewRepetition(NewEnsure(NewTextToken("a"))),
 			NewNegation(NewRepetition(NewTextToken("a"))),
 			NewRepetition(NewNegation(NewTextToken("a"))),
+			NewRecovery(NewJunction(NewTextToken("a"), NewTextToken("b")), NewTextToken(";")),
 			NewSymbol("A"),
 		).String())
 	})
@@ -2,15 +2,23 @@ package definition
 
 import (
 	"fmt"
+	"slices"
 	"strings"
 )
 
 type (
 	Rule struct {
-		Name string
-		Expr Expr
+		Name        string
+		Expr        Expr
+		Annotations []Annotation
 	}
 	Rules []Rule
+	// OverrideReport lists rules of the base grammar which were replaced or extended by the derived grammar and rules which were added by it
+	OverrideReport struct {
+		Replaced []string
+		Extended []string
+		Added    []string
+	}
 )
 
 func (rs Rules) Combine(other ...Rules) Rules {
@@ -20,11 +28,72 @@ func (rs Rules) Combine(other ...Rules) Rules {
 	}
 	return combined
 }
+
+// Derive flattens the grammar derived from rs into ordinary rules: rules of the derived grammar replace base rules with the same name
+// (identical rules are kept as is) or are appended after the base rules, then extensions append their alternatives to the rules with the same name
+func (rs Rules) Derive(rules Rules, extensions Rules) (Rules, OverrideReport, error) {
+	var report OverrideReport
+	derived := append(Rules{}, rs...)
+	positions := make(map[string]int)
+	for k, rule := range derived {
+		positions[rule.Name] = k
+	}
+	defined := make(map[string]struct{})
+	for _, rule := range rules {
+		if _, ok := defined[rule.Name]; ok {
+			return nil, OverrideReport{}, fmt.Errorf("rule %v is defined more than once in the derived grammar", rule.Name)
+		}
+		defined[rule.Name] = struct{}{}
+		k, ok := positions[rule.Name]
+		if !ok {
+			positions[rule.Name] = len(derived)
+			derived = append(derived, rule)
+			report.Added = append(report.Added, rule.Name)
+			continue
+		}
+		if derived[k].String() != rule.String() {
+			derived[k] = rule
+			report.Replaced = append(report.Replaced, rule.Name)
+		}
+	}
+	for _, extension := range extensions {
+		k, ok := positions[extension.Name]
+		if !ok {
+			return nil, OverrideReport{}, fmt.Errorf("extended rule %v is not defined", extension.Name)
+		}
+		if len(extension.Annotations) > 0 {
+			return nil, OverrideReport{}, fmt.Errorf("extension of rule %v can't have annotations", extension.Name)
+		}
+		derived[k].Expr = NewChoice(append(slices.Clone(alternatives(derived[k].Expr)), alternatives(extension.Expr)...)...)
+		if k < len(rs) && !slices.Contains(report.Extended, extension.Name) {
+			report.Extended = append(report.Extended, extension.Name)
+		}
+	}
+	return derived, report, nil
+}
+
+func alternatives(expr Expr) []Expr {
+	if choice, ok := expr.(Choice); ok {
+		return choice.Exprs
+	}
+	return []Expr{expr}
+}
+
 func NewRule(name string, expression Expr) Rule {
 	return Rule{Name: name, Expr: expression}
 }
+
+// NewAnnotatedRule creates the rule with annotations which control the shape of its nodes in the parsing tree
+func NewAnnotatedRule(name string, expression Expr, annotations ...Annotation) Rule {
+	return Rule{Name: name, Expr: expression, Annotations: annotations}
+}
 func (r Rule) String() string {
-	return fmt.Sprintf("%v: %v", r.Name, r.Expr)
+	var b strings.Builder
+	for _, annotation := range r.Annotations {
+		b.WriteString(annotation.String())
+		b.WriteString(" ")
+	}
+	return fmt.Sprintf("%v%v: %v", b.String(), r.Name, r.Expr)
 }
 
 func (rs Rules) String() string {
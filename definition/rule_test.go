@@ -2,6 +2,7 @@ package definition
 
 import (
 	"github.com/stretchr/testify/require"
+	"slices"
 	"testing"
 )
 
@@ -25,3 +26,51 @@ func TestRuleCombination(t *testing.T) {
 	require.Equal(t, "Digit", c[0].Name)
 	require.Equal(t, "Letter", c[1].Name)
 }
+
+func TestRulesDerive(t *testing.T) {
+	base := Rules{
+		NewRule("Sequence", NewChoice(NewSymbol("#Keywords"), NewSymbol("Word"))),
+		NewRule("#Keywords", NewChoice(NewTextToken("if"), NewTextToken("else"))),
+		NewRule("Word", NewTextPattern("[a-z]+")),
+	}
+	derived, report, err := base.Derive(
+		Rules{NewRule("#Keywords", NewChoice(NewTextToken("if"), NewTextToken("while"))), NewRule("Word", NewTextPattern("[a-z]+")), NewRule("Number", NewTextPattern("[0-9]+"))},
+		Rules{NewRule("Sequence", NewSymbol("Number"))},
+	)
+	require.Nil(t, err)
+	require.Equal(t, `Sequence: #Keywords / Word / Number
+#Keywords: "if" / "while"
+Word: =~"^[a-z]+"
+Number: =~"^[0-9]+"
+`, derived.String())
+	require.Equal(t, OverrideReport{Replaced: []string{"#Keywords"}, Extended: []string{"Sequence"}, Added: []string{"Number"}}, report)
+	require.Equal(t, `Sequence: #Keywords / Word`, base[0].String())
+	sequence := derived[0].Expr.(Choice)
+	keywords := derived[slices.IndexFunc(derived, func(rule Rule) bool { return rule.Name == sequence.Exprs[0].(Symbol).Name })]
+	require.Equal(t, NewChoice(NewTextToken("if"), NewTextToken("while")), keywords.Expr)
+
+	_, _, err = base.Derive(Rules{NewRule("Word", NewTextToken("a")), NewRule("Word", NewTextToken("b"))}, nil)
+	require.NotNil(t, err)
+	_, _, err = base.Derive(nil, Rules{NewRule("Number", NewTextToken("0"))})
+	require.NotNil(t, err)
+	_, _, err = base.Derive(nil, Rules{NewAnnotatedRule("Word", NewTextToken("0"), NewAnnotation(TokenAnnotation))})
+	require.NotNil(t, err)
+}
+
+func TestAnnotatedRuleString(t *testing.T) {
+	rule := NewAnnotatedRule("Number", NewTextPattern("[0-9]+"), NewAnnotation(TokenAnnotation), NewAnnotation(RenameAnnotation, "Num"))
+	require.Equal(t, `@token @rename(Num) Number: =~"^[0-9]+"`, rule.String())
+}
+
+func TestParseAnnotation(t *testing.T) {
+	annotation, err := ParseAnnotation("@rename(Num)")
+	require.Nil(t, err)
+	require.Equal(t, Annotation{Name: RenameAnnotation, Argument: "Num"}, annotation)
+	annotation, err = ParseAnnotation("@collapse-single-child")
+	require.Nil(t, err)
+	require.Equal(t, Annotation{Name: CollapseSingleChildAnnotation}, annotation)
+	_, err = ParseAnnotation("@rename(Num")
+	require.NotNil(t, err)
+	_, err = ParseAnnotation("token")
+	require.NotNil(t, err)
+}
@@ -14,7 +14,12 @@ type (
 
 func (s Segment) Length() int { return s.End - s.Start }
 
+func (s Segments) List() []Segment { return s.segments }
+
 func BuildSegments(segments ...Segment) Segments {
+	if len(segments) == 0 {
+		return Segments{}
+	}
 	totalLength := make([]int, 0, len(segments))
 	length := 0
 	for _, segment := range segments {
@@ -3,6 +3,9 @@ package definition
 import (
 	"bytes"
 	"fmt"
+	"io"
+	"unicode"
+	"unicode/utf8"
 )
 
 func (e TextToken) Match(data []byte) (int, bool) {
@@ -11,6 +14,52 @@ func (e TextToken) Match(data []byte) (int, bool) {
 	}
 	return 0, false
 }
+func (e FoldedToken) Match(data []byte) (int, bool) {
+	advance, _, ok := e.examine(data)
+	return advance, ok
+}
+
+// equalFold reports whether runes are equal under Unicode simple case folding
+func equalFold(a, b rune) bool {
+	if a == b {
+		return true
+	}
+	if a < utf8.RuneSelf && b < utf8.RuneSelf {
+		return 'A' <= a && a <= 'Z' && a+'a'-'A' == b || 'A' <= b && b <= 'Z' && b+'a'-'A' == a
+	}
+	for r := unicode.SimpleFold(a); r != a; r = unicode.SimpleFold(r) {
+		if r == b {
+			return true
+		}
+	}
+	return false
+}
+
+// examine compares the text with the token rune by rune because folded characters can have different encoded length;
+// invalid bytes of the token match only the same bytes of the text
+func (e FoldedToken) examine(data []byte) (int, int, bool) {
+	token, offset, look := e.Text, 0, 0
+	for len(token) > 0 {
+		look = max(look, offset+runeLook(data[offset:]))
+		if !utf8.FullRune(data[offset:]) {
+			return 0, look, false
+		}
+		expected, expectedSize := utf8.DecodeRune(token)
+		actual, actualSize := utf8.DecodeRune(data[offset:])
+		expectedInvalid := expected == utf8.RuneError && expectedSize == 1
+		actualInvalid := actual == utf8.RuneError && actualSize == 1
+		if expectedInvalid || actualInvalid {
+			if expectedInvalid != actualInvalid || token[0] != data[offset] {
+				return 0, look, false
+			}
+		} else if !equalFold(expected, actual) {
+			return 0, look, false
+		}
+		token, offset = token[expectedSize:], offset+actualSize
+	}
+	return offset, look, true
+}
+
 func (e TextPattern) Match(data []byte) (int, bool) {
 	location := e.Regex.FindIndex(data)
 	if location == nil || location[0] != 0 {
@@ -19,6 +68,90 @@ func (e TextPattern) Match(data []byte) (int, bool) {
 	return location[1], true
 }
 
+// lookReader tracks how far regex engine read the input (reaching the end of input counts as reading one more element)
+type lookReader struct {
+	data   []byte
+	offset int
+	look   int
+}
+
+func (r *lookReader) ReadRune() (rune, int, error) {
+	if r.offset >= len(r.data) {
+		r.look = len(r.data) + 1
+		return 0, 0, io.EOF
+	}
+	current, size := utf8.DecodeRune(r.data[r.offset:])
+	r.offset += size
+	r.look = max(r.look, r.offset)
+	return current, size, nil
+}
+
+func (e TextPattern) examine(data []byte) (int, int, bool) {
+	reader := &lookReader{data: data}
+	location := e.Regex.FindReaderIndex(reader)
+	if location == nil || location[0] != 0 {
+		return 0, reader.look, false
+	}
+	return location[1], reader.look, true
+}
+
+// runeLook returns the amount of bytes which determine the character at the start of the data:
+// for the invalid encoding it includes the first byte which breaks the sequence, as it decided the result too
+func runeLook(data []byte) int {
+	for look := 1; look <= min(len(data), utf8.UTFMax); look++ {
+		if utf8.FullRune(data[:look]) {
+			return look
+		}
+	}
+	return len(data) + 1
+}
+
+// AcceptRune implements Dot in UTF-8 mode: it matches single UTF-8 encoded character and fails on the invalid encoding
+func AcceptRune(text []byte, start int) (int, bool) {
+	current, size := utf8.DecodeRune(text[start:])
+	if size == 0 || (current == utf8.RuneError && size == 1) {
+		return 0, false
+	}
+	return size, true
+}
+
+// ExamineRune works like AcceptRune but also returns the amount of bytes which affected the result
+func ExamineRune(text []byte, start int) (int, int, bool) {
+	advance, ok := AcceptRune(text, start)
+	return advance, runeLook(text[start:]), ok
+}
+
+// Examine works like Accept but also returns the amount of elements which affected the result:
+// position right after the end of the text counts as an element too, so appending to the text changes the look of terminals which reached the end
+func Examine[T any](terminal Terminals, text []T, start int) (int, int, bool) {
+	switch peg := terminal.(type) {
+	case StartOfFile, Empty:
+		advance, ok := Accept[T](terminal, text, start)
+		return advance, 0, ok
+	case TextToken:
+		advance, ok := Accept[T](terminal, text, start)
+		return advance, len(peg.Text), ok
+	case TextPattern:
+		textBytes, textOk := any(text[start:]).([]byte)
+		if !textOk {
+			panic(fmt.Errorf("TextPattern terminal can be used only for byte sequences, given %#v", terminal))
+		}
+		return peg.examine(textBytes)
+	case FoldedToken:
+		textBytes, textOk := any(text[start:]).([]byte)
+		if !textOk {
+			panic(fmt.Errorf("FoldedToken terminal can be used only for byte sequences, given %#v", terminal))
+		}
+		return peg.examine(textBytes)
+	case InvalidUTF8, CharClass:
+		advance, ok := Accept[T](terminal, text, start)
+		return advance, runeLook(any(text[start:]).([]byte)), ok
+	default:
+		advance, ok := Accept[T](terminal, text, start)
+		return advance, 1, ok
+	}
+}
+
 func Accept[T any](terminal Terminals, text []T, start int) (int, bool) {
 	suffix := text[start:]
 	switch peg := terminal.(type) {
@@ -39,6 +172,15 @@ func Accept[T any](terminal Terminals, text []T, start int) (int, bool) {
 			return 0, false
 		}
 		return 1, true
+	case InvalidUTF8:
+		textBytes, textOk := any(suffix).([]byte)
+		if !textOk {
+			panic(fmt.Errorf("InvalidUTF8 terminal can be used only for byte sequences, given %T", *new(T)))
+		}
+		if current, size := utf8.DecodeRune(textBytes); current == utf8.RuneError && size == 1 {
+			return 1, true
+		}
+		return 0, false
 	case TextTerminals:
 		textBytes, textOk := any(suffix).([]byte)
 		if !textOk {
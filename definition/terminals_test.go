@@ -9,3 +9,74 @@ func TestRegex(t *testing.T) {
 	a := regexp.MustCompile("^(?i)a|ab").FindIndex([]byte("ABC"))
 	t.Logf("%v", a)
 }
+
+func TestAcceptRune(t *testing.T) {
+	text := []byte("a·\xffb\xc3a\xe2\x82")
+	for _, c := range []struct {
+		start, advance, look int
+		ok                   bool
+	}{
+		{start: 0, advance: 1, look: 1, ok: true},
+		{start: 1, advance: 2, look: 2, ok: true},
+		{start: 3, advance: 0, look: 1, ok: false},
+		{start: 4, advance: 1, look: 1, ok: true},
+		{start: 5, advance: 0, look: 2, ok: false},
+		{start: 6, advance: 1, look: 1, ok: true},
+		{start: 7, advance: 0, look: 3, ok: false},
+		{start: 9, advance: 0, look: 1, ok: false},
+	} {
+		advance, look, ok := ExamineRune(text, c.start)
+		if advance != c.advance || look != c.look || ok != c.ok {
+			t.Errorf("rune at %v: expected (%v, %v, %v), got (%v, %v, %v)", c.start, c.advance, c.look, c.ok, advance, look, ok)
+		}
+	}
+}
+
+func TestInvalidUTF8(t *testing.T) {
+	text := []byte("a·\xff\xe2\x82")
+	for _, c := range []struct {
+		start, advance int
+		ok             bool
+	}{
+		{start: 0, advance: 0, ok: false},
+		{start: 1, advance: 0, ok: false},
+		{start: 2, advance: 1, ok: true},
+		{start: 3, advance: 1, ok: true},
+		{start: 4, advance: 1, ok: true},
+		{start: 6, advance: 0, ok: false},
+	} {
+		advance, ok := Accept[byte](InvalidUTF8{}, text, c.start)
+		if advance != c.advance || ok != c.ok {
+			t.Errorf("invalid utf-8 at %v: expected (%v, %v), got (%v, %v)", c.start, c.advance, c.ok, advance, ok)
+		}
+	}
+}
+
+func TestFoldedToken(t *testing.T) {
+	for _, c := range []struct {
+		token, text   string
+		advance, look int
+		ok            bool
+	}{
+		{token: "select", text: "SeLeCt *", advance: 6, look: 6, ok: true},
+		{token: "select", text: "selext", advance: 0, look: 5, ok: false},
+		{token: "select", text: "sel", advance: 0, look: 4, ok: false},
+		{token: "straße", text: "STRASSE", advance: 0, look: 5, ok: false},
+		{token: "привет", text: "ПРИВЕТ", advance: 12, look: 12, ok: true},
+		{token: "k", text: "K", advance: 3, look: 3, ok: true},
+		{token: "K", text: "K", advance: 1, look: 1, ok: true},
+		{token: "a\xff", text: "A\xff", advance: 2, look: 2, ok: true},
+		{token: "�", text: "\xff", advance: 0, look: 1, ok: false},
+		{token: "é", text: "\xc3a", advance: 0, look: 2, ok: false},
+		{token: "\xc3", text: "\xc3a", advance: 1, look: 2, ok: true},
+		{token: "", text: "a", advance: 0, look: 0, ok: true},
+	} {
+		advance, look, ok := Examine[byte](NewFoldedToken(c.token).(Terminals), []byte(c.text), 0)
+		if advance != c.advance || look != c.look || ok != c.ok {
+			t.Errorf("token %q on %q: expected (%v, %v, %v), got (%v, %v, %v)", c.token, c.text, c.advance, c.look, c.ok, advance, look, ok)
+		}
+	}
+	if s := NewFoldedToken("Select").String(); s != `"Select"i` {
+		t.Errorf("unexpected string representation: %v", s)
+	}
+}
@@ -7,7 +7,15 @@ import (
 const (
 	PegDefinitions = "Definitions"
 	PegDefinition  = "Definition"
+	PegImport      = "Import"
+	PegImportPath  = "ImportPath"
+	PegImportAlias = "ImportAlias"
+	PegBase        = "Base"
+	PegExtension   = "Extension"
 	PegName        = "Name"
+	PegParameters  = "Parameters"
+	PegParameter   = "Parameter"
+	PegArguments   = "Arguments"
 	PegRule        = "Rule"
 	PegChoice      = "Choice"
 	PegJunction    = "Junction"
@@ -16,6 +24,7 @@ const (
 	PegSymbol      = "Symbol"
 	PegSymbolToken = "SymbolToken"
 	PegSuffix      = "Suffix"
+	PegRecovery    = "Recovery"
 	PegMap         = "Map"
 	PegMapKeyValue = "MapKeyValue"
 	PegMapKey      = "MapKey"
@@ -25,14 +34,44 @@ const (
 var (
 	PegGrammarRules = definition.Rules{
 		definition.NewRule(PegDefinitions, definition.NewRepetition(definition.NewJunction(
-			definition.NewOptional(definition.NewSymbol(PegDefinition)),
+			definition.NewOptional(definition.NewChoice(definition.NewSymbol(PegImport), definition.NewSymbol(PegBase), definition.NewSymbol(PegDefinition))),
 			definition.NewAtomPattern(map[string]definition.TextTerminals{PegEndOfLine: nil}),
 		))),
+		definition.NewRule(PegImport, definition.NewJunction(
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegAnnotation: definition.NewTokenAttributeMatcher("@import")}),
+			definition.NewSymbol(PegImportPath),
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegToken: definition.NewTokenAttributeMatcher("as")}),
+			definition.NewSymbol(PegImportAlias),
+		)),
+		definition.NewRule(PegImportPath, definition.NewAtomPattern(map[string]definition.TextTerminals{PegString: nil})),
+		definition.NewRule(PegImportAlias, definition.NewAtomPattern(map[string]definition.TextTerminals{PegToken: nil})),
+		definition.NewRule(PegBase, definition.NewJunction(
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegAnnotation: definition.NewTokenAttributeMatcher("@extends")}),
+			definition.NewSymbol(PegImportPath),
+		)),
 		definition.NewRule(PegDefinition, definition.NewJunction(
+			definition.NewRepetition(definition.NewJunction(
+				definition.NewSymbol(PegAnnotation),
+				definition.NewOptional(definition.NewAtomPattern(map[string]definition.TextTerminals{PegEndOfLine: nil})),
+			)),
 			definition.NewSymbol(PegName),
+			definition.NewOptional(definition.NewSymbol(PegParameters)),
+			definition.NewOptional(definition.NewSymbol(PegExtension)),
 			definition.NewAtomPattern(map[string]definition.TextTerminals{PegControl: definition.NewTokenAttributeMatcher(":")}),
 			definition.NewSymbol(PegRule),
 		)),
+		definition.NewRule(PegExtension, definition.NewAtomPattern(map[string]definition.TextTerminals{PegControl: definition.NewTokenAttributeMatcher("/")})),
+		definition.NewRule(PegParameters, definition.NewJunction(
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegControl: definition.NewTokenAttributeMatcher("<")}),
+			definition.NewSymbol(PegParameter),
+			definition.NewRepetition(definition.NewJunction(
+				definition.NewAtomPattern(map[string]definition.TextTerminals{PegControl: definition.NewTokenAttributeMatcher(",")}),
+				definition.NewSymbol(PegParameter),
+			)),
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegControl: definition.NewTokenAttributeMatcher(">")}),
+		)),
+		definition.NewRule(PegParameter, definition.NewAtomPattern(map[string]definition.TextTerminals{PegToken: nil})),
+		definition.NewRule(PegAnnotation, definition.NewAtomPattern(map[string]definition.TextTerminals{PegAnnotation: nil})),
 		definition.NewRule(PegName, definition.NewAtomPattern(map[string]definition.TextTerminals{PegToken: nil})),
 		definition.NewRule(PegRule, definition.NewJunction(
 			definition.NewSymbol(PegChoice),
@@ -50,10 +89,13 @@ var (
 			definition.NewOptional(definition.NewSymbol(PegPrefix)),
 			definition.NewSymbol(PegExpression),
 			definition.NewOptional(definition.NewSymbol(PegSuffix)),
+			definition.NewOptional(definition.NewSymbol(PegRecovery)),
 		)),
-		definition.NewRule(PegPrefix, definition.NewAtomPattern(map[string]definition.TextTerminals{PegControl: definition.NewPatternAttributeMatcher("[!&]")})),
+		definition.NewRule(PegPrefix, definition.NewAtomPattern(map[string]definition.TextTerminals{PegControl: definition.NewCharClass("[!&]").(definition.TextTerminals)})),
 		definition.NewRule(PegExpression, definition.NewChoice(
 			definition.NewAtomPattern(map[string]definition.TextTerminals{PegString: nil}),
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegFoldedString: nil}),
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegCharClass: nil}),
 			definition.NewAtomPattern(map[string]definition.TextTerminals{PegRegex: nil}),
 			definition.NewSymbol(PegSymbol),
 			definition.NewAtomPattern(map[string]definition.TextTerminals{PegDot: nil}),
@@ -65,15 +107,32 @@ var (
 				definition.NewAtomPattern(map[string]definition.TextTerminals{PegClose: nil}),
 			),
 		)),
-		definition.NewRule(PegSuffix, definition.NewAtomPattern(map[string]definition.TextTerminals{PegControl: definition.NewPatternAttributeMatcher("[+*?]")})),
+		definition.NewRule(PegSuffix, definition.NewChoice(
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegControl: definition.NewCharClass("[+*?]").(definition.TextTerminals)}),
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegQuantifier: nil}),
+		)),
+		definition.NewRule(PegRecovery, definition.NewJunction(
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegControl: definition.NewTokenAttributeMatcher("~")}),
+			definition.NewSymbol(PegExpression),
+		)),
 		definition.NewRule(PegSymbol, definition.NewJunction(
 			definition.NewOptional(definition.NewJunction(
 				definition.NewSymbol(PegMap),
 				definition.NewAtomPattern(map[string]definition.TextTerminals{PegControl: definition.NewTokenAttributeMatcher(":")}),
 			)),
 			definition.NewSymbol(PegSymbolToken),
+			definition.NewOptional(definition.NewSymbol(PegArguments)),
 		)),
 		definition.NewRule(PegSymbolToken, definition.NewAtomPattern(map[string]definition.TextTerminals{PegToken: nil})),
+		definition.NewRule(PegArguments, definition.NewJunction(
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegControl: definition.NewTokenAttributeMatcher("<")}),
+			definition.NewSymbol(PegRule),
+			definition.NewRepetition(definition.NewJunction(
+				definition.NewAtomPattern(map[string]definition.TextTerminals{PegControl: definition.NewTokenAttributeMatcher(",")}),
+				definition.NewSymbol(PegRule),
+			)),
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegControl: definition.NewTokenAttributeMatcher(">")}),
+		)),
 		definition.NewRule(PegMap, definition.NewJunction(
 			definition.NewAtomPattern(map[string]definition.TextTerminals{PegControl: definition.NewTokenAttributeMatcher("{")}),
 			definition.NewSymbol(PegMapKeyValue),
@@ -91,6 +150,11 @@ var (
 			)),
 		)),
 		definition.NewRule(PegMapKey, definition.NewChoice(definition.NewAtomPattern(map[string]definition.TextTerminals{PegString: nil}), definition.NewAtomPattern(map[string]definition.TextTerminals{PegToken: nil}))),
-		definition.NewRule(PegMapValue, definition.NewChoice(definition.NewAtomPattern(map[string]definition.TextTerminals{PegString: nil}), definition.NewAtomPattern(map[string]definition.TextTerminals{PegRegex: nil}))),
+		definition.NewRule(PegMapValue, definition.NewChoice(
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegString: nil}),
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegFoldedString: nil}),
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegCharClass: nil}),
+			definition.NewAtomPattern(map[string]definition.TextTerminals{PegRegex: nil}),
+		)),
 	}
 )
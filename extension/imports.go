@@ -0,0 +1,181 @@
+package extension
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/parser"
+)
+
+// importer loads imported and base grammars; stack holds paths of the grammars which are currently loaded in order to detect import cycles
+// and report receives the overrides of the top-level grammar (depth is zero only while it is loaded).
+// Every imported grammar is loaded once: its rules are collected in imported under the prefix of its first import (the chain of aliases from the top-level grammar)
+type importer struct {
+	fsys     fs.FS
+	stack    []string
+	depth    int
+	report   *definition.OverrideReport
+	prefix   string
+	modules  map[string]*importedGrammar
+	prefixes map[string]string
+	imported definition.Rules
+}
+
+// importedGrammar maps names of the rules defined by the imported grammar to their qualified names
+type importedGrammar struct {
+	names     map[string]string
+	templates map[string]struct{}
+}
+
+// module loads the grammar imported by @import "path" as alias directive: its rules are renamed to alias.Rule (or #alias.Rule for hidden rules)
+// and become available for references in the importing grammar
+func (i *importer) module(importing string, node *parser.ParsingNode, s *scope) error {
+	alias := node.MustSelectBySymbol(PegImportAlias).Atom.SelectString()
+	if strings.HasPrefix(alias, "#") || strings.Contains(alias, ".") {
+		return fmt.Errorf("invalid import alias: %v", alias)
+	}
+	if _, ok := s.imports[alias]; ok {
+		return fmt.Errorf("import alias %v is used more than once", alias)
+	}
+	target, err := i.path(importing, node)
+	if err != nil {
+		return fmt.Errorf("unable to import %v: %w", target, err)
+	}
+	if imported, ok := i.modules[target]; ok {
+		s.imports[alias] = imported
+		return nil
+	}
+	prefix := qualify(i.prefix, alias)
+	if other, ok := i.prefixes[prefix]; ok {
+		return fmt.Errorf("unable to import %v: prefix %v is already used by %v", target, prefix, other)
+	}
+	parent, mark := i.prefix, len(i.imported)
+	i.prefix = prefix
+	rules, nested, err := i.nested(target)
+	i.prefix = parent
+	if err != nil {
+		return fmt.Errorf("unable to import %v: %w", target, err)
+	}
+	imported := &importedGrammar{names: make(map[string]string, len(rules)), templates: make(map[string]struct{})}
+	for _, rule := range rules {
+		imported.names[rule.Name] = qualify(prefix, rule.Name)
+	}
+	for name := range nested.templates {
+		imported.templates[name] = struct{}{}
+	}
+	qualified := make(definition.Rules, 0, len(rules))
+	for _, rule := range rules {
+		qualified = append(qualified, definition.NewAnnotatedRule(imported.names[rule.Name], qualifyExpr(imported.names, rule.Expr), rule.Annotations...))
+	}
+	// rules of the grammar precede rules of its imports which were collected while it was loaded
+	i.imported = slices.Insert(i.imported, mark, qualified...)
+	if i.modules == nil {
+		i.modules, i.prefixes = make(map[string]*importedGrammar), make(map[string]string)
+	}
+	i.modules[target], i.prefixes[prefix] = imported, target
+	s.imports[alias] = imported
+	return nil
+}
+
+// path resolves the path of the directive relative to the referencing grammar
+func (i *importer) path(referencing string, node *parser.ParsingNode) (string, error) {
+	target, err := strconv.Unquote(node.MustSelectBySymbol(PegImportPath).Atom.SelectString())
+	if err != nil {
+		return "", fmt.Errorf("unable to unescape path: %w", err)
+	}
+	return path.Join(path.Dir(referencing), target), nil
+}
+
+// nested loads the grammar referenced by the directive; it returns only the rules defined by the grammar itself as its imports are collected by the importer
+func (i *importer) nested(target string) (definition.Rules, *scope, error) {
+	if i.fsys == nil {
+		return nil, nil, fmt.Errorf("file system is not set")
+	}
+	if slices.Contains(i.stack, target) {
+		return nil, nil, fmt.Errorf("import cycle: %v", strings.Join(append(slices.Clone(i.stack), target), " -> "))
+	}
+	text, err := fs.ReadFile(i.fsys, target)
+	if err != nil {
+		return nil, nil, err
+	}
+	i.stack = append(i.stack, target)
+	i.depth++
+	rules, s, err := i.load(target, string(text))
+	i.depth--
+	i.stack = i.stack[:len(i.stack)-1]
+	return rules, s, err
+}
+
+// resolve finds the imported rule referenced as alias.Rule: the reference can omit # prefix of the hidden rule
+func (s *scope) resolve(reference string) (string, error) {
+	alias, name, _ := strings.Cut(reference, ".")
+	imported, ok := s.imports[alias]
+	if !ok {
+		return "", fmt.Errorf("unknown import %v in reference %v", alias, reference)
+	}
+	if _, ok := imported.templates[name]; ok {
+		return "", fmt.Errorf("parameterized rule %v of import %v can't be used outside of its grammar", name, alias)
+	}
+	if qualified, ok := imported.names[name]; ok {
+		return qualified, nil
+	}
+	if qualified, ok := imported.names["#"+name]; ok {
+		return qualified, nil
+	}
+	return "", fmt.Errorf("rule %v is not defined in import %v", name, alias)
+}
+
+// qualify prepends the prefix (the chain of aliases) to the name and keeps # prefix of the hidden rule at the start
+func qualify(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	if strings.HasPrefix(name, "#") {
+		return "#" + prefix + "." + name[1:]
+	}
+	return prefix + "." + name
+}
+
+func qualifyExprs(local map[string]string, exprs []definition.Expr) []definition.Expr {
+	qualified := make([]definition.Expr, 0, len(exprs))
+	for _, expr := range exprs {
+		qualified = append(qualified, qualifyExpr(local, expr))
+	}
+	return qualified
+}
+
+// qualifyExpr renames references to the rules of the imported grammar; references to its imports are already qualified by resolve
+func qualifyExpr(local map[string]string, expr definition.Expr) definition.Expr {
+	switch peg := expr.(type) {
+	case definition.Symbol:
+		if name, ok := local[peg.Name]; ok {
+			return definition.Symbol{Name: name, Attributes: peg.Attributes}
+		}
+		return peg
+	case definition.Choice:
+		return definition.Choice{Exprs: qualifyExprs(local, peg.Exprs)}
+	case definition.Junction:
+		return definition.Junction{Exprs: qualifyExprs(local, peg.Exprs)}
+	case definition.Negation:
+		return definition.Negation{Expr: qualifyExpr(local, peg.Expr)}
+	case definition.Ensure:
+		return definition.Ensure{Expr: qualifyExpr(local, peg.Expr)}
+	case definition.Optional:
+		return definition.Optional{Expr: qualifyExpr(local, peg.Expr)}
+	case definition.Kleene:
+		return definition.Kleene{Expr: qualifyExpr(local, peg.Expr)}
+	case definition.Repetition:
+		return definition.Repetition{Expr: qualifyExpr(local, peg.Expr), Min: peg.Min, Max: peg.Max}
+	case definition.Recovery:
+		return definition.Recovery{Expr: qualifyExpr(local, peg.Expr), Sync: qualifyExpr(local, peg.Sync)}
+	case definition.Terminals:
+		return peg
+	default:
+		panic(fmt.Errorf("unexpected peg expression type: %#v", expr))
+	}
+}
@@ -0,0 +1,90 @@
+package extension
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gopeg/parser"
+)
+
+func TestLoadImports(t *testing.T) {
+	fsys := fstest.MapFS{
+		"common.peg":       {Data: []byte("#Digit: [0-9]\nNumber: #Digit+ (\".\" #Digit+)?\nIdentifier: [a-z] ([a-z] / #Digit)*")},
+		"lang/values.peg":  {Data: []byte("@import \"../common.peg\" as c\nValue: c.Number / c.Identifier")},
+		"lang/grammar.peg": {Data: []byte("@import \"values.peg\" as v\n@import \"../common.peg\" as c\nAssignment: c.Identifier \"=\" v.Value")},
+	}
+	rules, err := LoadFS(fsys, "lang/grammar.peg")
+	require.Nil(t, err)
+	names := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+	// common.peg is imported twice but its rules are emitted once under the prefix of the first import
+	require.Equal(t, []string{"Assignment", "v.Value", "#v.c.Digit", "v.c.Number", "v.c.Identifier"}, names)
+	require.Equal(t, `v.Value: v.c.Number / v.c.Identifier`, rules[1].String())
+
+	node, err := parser.ParseText(rules, "Assignment", []byte("x1=4.2"))
+	require.Nil(t, err)
+	require.Equal(t, "v.c.Identifier", node.Children[0].Atom.Symbol)
+	require.Equal(t, "v.Value", node.Children[1].Atom.Symbol)
+	require.Equal(t, "v.c.Number", node.Children[1].Children[0].Atom.Symbol)
+
+	rules, err = Load("@import \"common.peg\" as c\nDigits: c.Digit+", WithFS(fsys))
+	require.Nil(t, err)
+	require.Equal(t, `Digits: #c.Digit+`, rules[0].String())
+}
+
+func TestLoadImportsDiamond(t *testing.T) {
+	fsys := fstest.MapFS{
+		"common.peg": {Data: []byte("#Digit: [0-9]\nNumber: #Digit+")},
+		"left.peg":   {Data: []byte("@import \"common.peg\" as c\nLeft: \"<\" c.Number \",\"")},
+		"right.peg":  {Data: []byte("@import \"common.peg\" as common\nRight: common.Number \">\"")},
+	}
+	rules, err := Load("@import \"left.peg\" as l\n@import \"right.peg\" as r\nPair: l.Left r.Right", WithFS(fsys))
+	require.Nil(t, err)
+	names := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+	require.Equal(t, []string{"Pair", "l.Left", "#l.c.Digit", "l.c.Number", "r.Right"}, names)
+	require.Equal(t, `r.Right: l.c.Number ">"`, rules[4].String())
+
+	node, err := parser.ParseText(rules, "Pair", []byte("<1,2>"), parser.WithMatchMode(parser.FullMatch))
+	require.Nil(t, err)
+	require.Equal(t, "l.c.Number", node.Children[1].Children[0].Atom.Symbol)
+}
+
+func TestLoadImportsErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"a.peg":         {Data: []byte("@import \"b.peg\" as b\nA: b.B")},
+		"b.peg":         {Data: []byte("@import \"a.peg\" as a\nB: a.A")},
+		"self.peg":      {Data: []byte("@import \"self.peg\" as s\nA: \"a\"")},
+		"common.peg":    {Data: []byte("Number: [0-9]+")},
+		"base.peg":      {Data: []byte("@import \"common.peg\" as c\nB: c.Number")},
+		"templates.peg": {Data: []byte("List<Item>: Item (\",\" Item)*\nNumbers: List<[0-9]>")},
+	}
+	for _, c := range []struct{ name, text, err string }{
+		{name: "cycle", text: "@import \"a.peg\" as a\nR: a.A", err: "import cycle: a.peg -> b.peg -> a.peg"},
+		{name: "self import", text: "@import \"self.peg\" as s\nR: s.A", err: "import cycle: self.peg -> self.peg"},
+		{name: "missing file", text: "@import \"missing.peg\" as m\nR: m.A", err: "unable to import missing.peg"},
+		{name: "alias collision", text: "@import \"common.peg\" as c\n@import \"a.peg\" as c\nR: c.Number", err: "import alias c is used more than once"},
+		{name: "rule collision", text: "R: \"a\"\nR: \"b\"", err: "rule R is defined more than once"},
+		{name: "qualified definition", text: "@import \"common.peg\" as c\nc.Number: \"1\"", err: "rule name can't be qualified: c.Number"},
+		{name: "unknown import", text: "R: x.Number", err: "unknown import x in reference x.Number"},
+		{name: "unknown rule", text: "@import \"common.peg\" as c\nR: c.Letter", err: "rule Letter is not defined in import c"},
+		{name: "imported template", text: "@import \"templates.peg\" as t\nR: t.List<\"a\">", err: "parameterized rule List of import t can't be used outside of its grammar"},
+		{name: "prefix collision", text: "@import \"templates.peg\" as c\n@extends \"base.peg\"\nR: c.Numbers", err: "prefix c is already used by templates.peg"},
+		{name: "imported template without arguments", text: "@import \"templates.peg\" as t\nR: t.List", err: "parameterized rule List of import t can't be used outside of its grammar"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Load(c.text, WithFS(fsys))
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), c.err)
+		})
+	}
+	_, err := Load("@import \"common.peg\" as c\nR: c.Number")
+	require.NotNil(t, err)
+	require.Contains(t, err.Error(), "file system is not set")
+}
@@ -0,0 +1,60 @@
+package extension
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/parser"
+)
+
+func TestLoadExtends(t *testing.T) {
+	fsys := fstest.MapFS{
+		"base.peg":    {Data: []byte("Sequence: (#Keywords / Word) (\" \" Sequence)?\n#Keywords: \"if\" / \"else\"\nWord: [a-z]+")},
+		"derived.peg": {Data: []byte("@extends \"base.peg\"\n#Keywords: \"if\" / \"while\"\nSequence /: Number\nNumber: [0-9]+")},
+	}
+	var report definition.OverrideReport
+	rules, err := LoadFS(fsys, "derived.peg", WithOverrideReport(&report))
+	require.Nil(t, err)
+	require.Equal(t, `Sequence: (#Keywords / Word) (" " Sequence)? / Number
+#Keywords: "if" / "while"
+Word: [a-z]+
+Number: [0-9]+
+`, rules.String())
+	require.Equal(t, definition.OverrideReport{Replaced: []string{"#Keywords"}, Extended: []string{"Sequence"}, Added: []string{"Number"}}, report)
+
+	_, err = parser.ParseText(rules, "Sequence", []byte("while x"))
+	require.Nil(t, err)
+	_, err = parser.ParseText(rules, "Sequence", []byte("42"))
+	require.Nil(t, err)
+
+	rules, err = Load("@extends \"derived.peg\"\nWord /: \"_\"", WithFS(fsys), WithOverrideReport(&report))
+	require.Nil(t, err)
+	require.Equal(t, `Word: [a-z]+ / "_"`, rules[2].String())
+	require.Equal(t, definition.OverrideReport{Extended: []string{"Word"}}, report)
+}
+
+func TestLoadExtendsErrors(t *testing.T) {
+	fsys := fstest.MapFS{
+		"base.peg": {Data: []byte("Word: [a-z]+")},
+		"loop.peg": {Data: []byte("@extends \"loop.peg\"\nWord: \"a\"")},
+	}
+	for _, c := range []struct{ name, text, err string }{
+		{name: "two bases", text: "@extends \"base.peg\"\n@extends \"base.peg\"\nWord: \"a\"", err: "grammar can extend only one base grammar"},
+		{name: "missing base", text: "@extends \"missing.peg\"\nWord: \"a\"", err: "unable to extend missing.peg"},
+		{name: "cycle", text: "@extends \"loop.peg\"\nWord: \"a\"", err: "import cycle: loop.peg -> loop.peg"},
+		{name: "extension without base", text: "Word /: \"a\"", err: "rule Word can be extended only in the grammar with @extends directive"},
+		{name: "unknown extension", text: "@extends \"base.peg\"\nNumber /: [0-9]+", err: "extended rule Number is not defined"},
+		{name: "annotated extension", text: "@extends \"base.peg\"\n@token Word /: \"_\"", err: "extension of rule Word can't have annotations"},
+		{name: "parameterized extension", text: "@extends \"base.peg\"\nWord<X> /: X", err: "parameterized rule Word can't extend the base rule"},
+		{name: "redefinition", text: "@extends \"base.peg\"\nWord: \"a\"\nWord: \"b\"", err: "rule Word is defined more than once"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Load(c.text, WithFS(fsys))
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), c.err)
+		})
+	}
+}
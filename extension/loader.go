@@ -4,42 +4,177 @@ import (
 	"fmt"
 	"github.com/sivukhin/gopeg/definition"
 	"github.com/sivukhin/gopeg/parser"
+	"io/fs"
 	"strconv"
+	"strings"
 )
 
-func Load(text string) (definition.Rules, error) {
-	tokens, err := parser.ParseText(PegTokenizerRules, PegText, []byte(text))
+var (
+	pegTokenizerGrammar = mustCompile(PegTokenizerRules, PegText)
+	pegGrammar          = mustCompile(PegGrammarRules, PegDefinitions)
+)
+
+func mustCompile(rules definition.Rules, root string) *parser.Grammar {
+	grammar, err := parser.Compile(rules, parser.WithRoot(root), parser.WithMatchMode(parser.FullMatch))
+	if err != nil {
+		panic(fmt.Errorf("unable to compile %v rules: %w", root, err))
+	}
+	return grammar
+}
+
+type (
+	LoadOption  func(options *loadOptions)
+	loadOptions struct {
+		fsys   fs.FS
+		report *definition.OverrideReport
+	}
+)
+
+// WithFS sets the file system (for example, embed.FS) which resolves @import directives; paths are relative to the importing file
+func WithFS(fsys fs.FS) LoadOption {
+	return func(options *loadOptions) { options.fsys = fsys }
+}
+
+// WithOverrideReport sets the report which receives rules of the base grammar replaced or extended by the grammar with @extends directive
+func WithOverrideReport(report *definition.OverrideReport) LoadOption {
+	return func(options *loadOptions) { options.report = report }
+}
+
+func Load(text string, opts ...LoadOption) (definition.Rules, error) {
+	options := loadOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	rules, _, err := (&importer{fsys: options.fsys, report: options.report}).load("", text)
+	return rules, err
+}
+
+// LoadFS loads the grammar from the file and resolves its imports relative to it
+func LoadFS(fsys fs.FS, name string, opts ...LoadOption) (definition.Rules, error) {
+	text, err := fs.ReadFile(fsys, name)
 	if err != nil {
-		return nil, fmt.Errorf("unable to parse syntax structure: %w", err)
+		return nil, fmt.Errorf("unable to read grammar: %w", err)
 	}
-	if tokens.Segment.Length() != len(text) {
-		return nil, fmt.Errorf("unable to fully tokenize input (valid first %v bytes)", tokens.Segment.Length())
+	options := loadOptions{}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	rules, _, err := (&importer{fsys: fsys, stack: []string{name}, report: options.report}).load(name, string(text))
+	return rules, err
+}
+
+// load returns the rules of the grammar together with rules of all imports for the top-level grammar and only its own rules for the nested one
+func (i *importer) load(name, text string) (definition.Rules, *scope, error) {
+	tokens, err := pegTokenizerGrammar.ParseText([]byte(text))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to fully tokenize input: %w", err)
 	}
 	atoms := make([]definition.Atom, 0)
 	for _, atom := range tokens.Children {
 		atoms = append(atoms, atom.Atom)
 	}
-	peg, err := parser.ParseAtoms(PegGrammarRules, PegDefinitions, atoms)
+	peg, err := pegGrammar.ParseAtoms(atoms)
 	if err != nil {
-		return nil, fmt.Errorf("unable to parse lexical structure: %w", err)
+		return nil, nil, fmt.Errorf("unable to fully parse tokenized input: %w", err)
+	}
+	s := &scope{atoms: atoms, templates: make(map[string]template), instances: make(map[string]struct{}), imports: make(map[string]*importedGrammar)}
+	for _, node := range peg.FilterBySymbol(PegImport) {
+		if err := i.module(name, node, s); err != nil {
+			return nil, nil, err
+		}
+	}
+	bases := peg.FilterBySymbol(PegBase)
+	if len(bases) > 1 {
+		return nil, nil, fmt.Errorf("grammar can extend only one base grammar")
+	}
+	var base definition.Rules
+	for _, node := range bases {
+		target, err := i.path(name, node)
+		if err != nil {
+			return nil, nil, fmt.Errorf("unable to extend %v: %w", target, err)
+		}
+		if base, _, err = i.nested(target); err != nil {
+			return nil, nil, fmt.Errorf("unable to extend %v: %w", target, err)
+		}
+	}
+	definitions := peg.FilterBySymbol(PegDefinition)
+	for _, d := range definitions {
+		name := string(d.MustSelectBySymbol(PegName).Atom.SelectText())
+		if strings.Contains(name, ".") {
+			return nil, nil, fmt.Errorf("rule name can't be qualified: %v", name)
+		}
+		if parameters, ok := d.TrySelectBySymbol(PegParameters); ok {
+			if _, ok := d.TrySelectBySymbol(PegExtension); ok {
+				return nil, nil, fmt.Errorf("parameterized rule %v can't extend the base rule", name)
+			}
+			if err := s.define(name, parameters, d); err != nil {
+				return nil, nil, err
+			}
+		}
 	}
-	if peg.Segment.Length() != len(atoms) {
-		return nil, fmt.Errorf("unable to fully parse tokenized input (valid first %v tokens)", peg.Segment.Length())
+	if err := s.checkTermination(); err != nil {
+		return nil, nil, err
 	}
-	rules := make(definition.Rules, 0)
-	for _, d := range peg.EnsureOnlySymbol(PegDefinition) {
+	rules, extensions := make(definition.Rules, 0), make(definition.Rules, 0)
+	for _, d := range definitions {
+		if _, ok := d.TrySelectBySymbol(PegParameters); ok {
+			continue
+		}
 		name := string(d.MustSelectBySymbol(PegName).Atom.SelectText())
-		current, additional, err := rule(d.MustSelectBySymbol(PegRule), atoms)
+		current, additional, err := rule(d.MustSelectBySymbol(PegRule), s)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
+		}
+		annotations, err := definitionAnnotations(d)
+		if err != nil {
+			return nil, nil, err
 		}
 		rules = append(rules, additional...)
-		rules = append(rules, definition.NewRule(name, current))
+		if _, ok := d.TrySelectBySymbol(PegExtension); ok {
+			if len(bases) == 0 {
+				return nil, nil, fmt.Errorf("rule %v can be extended only in the grammar with @extends directive", name)
+			}
+			extensions = append(extensions, definition.NewAnnotatedRule(name, current, annotations...))
+			continue
+		}
+		rules = append(rules, definition.NewAnnotatedRule(name, current, annotations...))
+	}
+	defined := make(map[string]struct{})
+	for _, rule := range rules {
+		if _, ok := defined[rule.Name]; ok {
+			return nil, nil, fmt.Errorf("rule %v is defined more than once", rule.Name)
+		}
+		defined[rule.Name] = struct{}{}
+	}
+	if len(bases) > 0 {
+		derived, report, err := base.Derive(rules, extensions)
+		if err != nil {
+			return nil, nil, err
+		}
+		if i.depth == 0 && i.report != nil {
+			*i.report = report
+		}
+		rules = derived
+	}
+	if i.depth == 0 {
+		rules = append(rules, i.imported...)
+	}
+	return rules, s, nil
+}
+
+func definitionAnnotations(d *parser.ParsingNode) ([]definition.Annotation, error) {
+	annotations := make([]definition.Annotation, 0)
+	for _, a := range d.FilterBySymbol(PegAnnotation) {
+		annotation, err := definition.ParseAnnotation(a.Atom.SelectString())
+		if err != nil {
+			return nil, err
+		}
+		annotations = append(annotations, annotation)
 	}
-	return rules, nil
+	return annotations, nil
 }
 
-func rule(node *parser.ParsingNode, atoms []definition.Atom) (definition.Expr, []definition.Rule, error) {
+func rule(node *parser.ParsingNode, s *scope) (definition.Expr, []definition.Rule, error) {
 	if node.Atom.Symbol != PegRule {
 		panic(fmt.Errorf("unexpcted node type: %v", node.Atom.Symbol))
 	}
@@ -49,55 +184,11 @@ func rule(node *parser.ParsingNode, atoms []definition.Atom) (definition.Expr, [
 	for _, choice := range node.EnsureOnlySymbol(PegChoice) {
 		junctions := make([]definition.Expr, 0, len(choice.Children))
 		for _, junction := range choice.EnsureOnlySymbol(PegJunction) {
-			expr := junction.MustSelectBySymbol(PegExpression)
-			var current definition.Expr
-			var err error
-			if len(expr.Children) == 0 {
-				if expr.Segment.Length() != 1 {
-					panic(fmt.Errorf("unexpected expression: %#v", expr))
-				}
-				atom := atoms[expr.Segment.Start]
-				current, err = atom2expr(atom, true)
-				if err != nil {
-					return nil, nil, err
-				}
-			} else {
-				child := expr.EnsureOnlySingle()
-				switch child.Atom.Symbol {
-				case PegRule:
-					var addition []definition.Rule
-					current, addition, err = rule(child, atoms)
-					if err != nil {
-						return nil, nil, err
-					}
-					rules = append(rules, addition...)
-				case PegMap:
-					pegMap, err := createPegMap(child, atoms)
-					if err != nil {
-						return nil, nil, err
-					}
-					matcher := make(map[string]definition.TextTerminals)
-					for pegMapKey, pegMapValue := range pegMap {
-						if pegMapValue == nil {
-							matcher[pegMapKey] = nil
-							continue
-						}
-						atomExpr, err := atom2expr(*pegMapValue, true)
-						if err != nil {
-							return nil, nil, err
-						}
-						matcher[pegMapKey] = atomExpr.(definition.TextTerminals)
-					}
-					current = definition.NewAtomPattern(matcher)
-				case PegSymbol:
-					current, err = createPegSymbol(child, atoms)
-					if err != nil {
-						return nil, nil, err
-					}
-				default:
-					panic(fmt.Errorf("unexpected atom symbol: %v", child.Atom.Symbol))
-				}
+			current, addition, err := expression(junction.MustSelectBySymbol(PegExpression), s)
+			if err != nil {
+				return nil, nil, err
 			}
+			rules = append(rules, addition...)
 
 			if prefix, ok := junction.TrySelectBySymbol(PegPrefix); ok {
 				control := string(prefix.Atom.SelectText())
@@ -120,16 +211,38 @@ func rule(node *parser.ParsingNode, atoms []definition.Atom) (definition.Expr, [
 				case "+":
 					current = definition.NewRepetitionN(current, 1)
 				default:
-					return nil, nil, fmt.Errorf("unknown suffix: %v", control)
+					if !strings.HasPrefix(control, "{") {
+						return nil, nil, fmt.Errorf("unknown suffix: %v", control)
+					}
+					min, max, err := quantifier(control)
+					if err != nil {
+						return nil, nil, err
+					}
+					current = definition.NewRepetitionRange(current, min, max)
 				}
 			}
+			if recovery, ok := junction.TrySelectBySymbol(PegRecovery); ok {
+				sync, addition, err := expression(recovery.MustSelectBySymbol(PegExpression), s)
+				if err != nil {
+					return nil, nil, fmt.Errorf("unable to create recovery: %w", err)
+				}
+				rules = append(rules, addition...)
+				current = definition.NewRecovery(current, sync)
+			}
 			if alias, ok := junction.TrySelectBySymbol(PegSymbol); ok {
-				symbol, err := createPegSymbol(alias, atoms)
+				if _, ok := alias.TrySelectBySymbol(PegArguments); ok {
+					return nil, nil, fmt.Errorf("alias can't have arguments: %v", alias.Atom.SelectString())
+				}
+				symbol, err := createPegSymbol(alias, s.atoms)
 				if err != nil {
 					return nil, nil, fmt.Errorf("unable to create alias: %w", err)
 				}
 				inlineSymbol := symbol
 				inlineSymbol.Name = fmt.Sprintf("%v@%v", symbol.Name, alias.Segment.Start)
+				// aliases of the parameterized rule body are distinct for every instantiation
+				if s.instance != "" {
+					inlineSymbol.Name = fmt.Sprintf("%v@%v", inlineSymbol.Name, s.instance)
+				}
 				rules = append(rules, definition.NewRule(inlineSymbol.Name, current))
 				current = inlineSymbol
 			}
@@ -140,6 +253,90 @@ func rule(node *parser.ParsingNode, atoms []definition.Atom) (definition.Expr, [
 	return definition.NewChoice(choices...), rules, nil
 }
 
+func expression(expr *parser.ParsingNode, s *scope) (definition.Expr, []definition.Rule, error) {
+	if expr.Atom.Symbol != PegExpression {
+		panic(fmt.Errorf("unexpcted node type: %v", expr.Atom.Symbol))
+	}
+
+	rules := make([]definition.Rule, 0)
+	var current definition.Expr
+	var err error
+	if len(expr.Children) == 0 {
+		if expr.Segment.Length() != 1 {
+			panic(fmt.Errorf("unexpected expression: %#v", expr))
+		}
+		atom := s.atoms[expr.Segment.Start]
+		current, err = atom2expr(atom, true)
+		if err != nil {
+			return nil, nil, err
+		}
+	} else {
+		child := expr.EnsureOnlySingle()
+		switch child.Atom.Symbol {
+		case PegRule:
+			var addition []definition.Rule
+			current, addition, err = rule(child, s)
+			if err != nil {
+				return nil, nil, err
+			}
+			rules = append(rules, addition...)
+		case PegMap:
+			pegMap, err := createPegMap(child, s.atoms)
+			if err != nil {
+				return nil, nil, err
+			}
+			matcher := make(map[string]definition.TextTerminals)
+			for pegMapKey, pegMapValue := range pegMap {
+				if pegMapValue == nil {
+					matcher[pegMapKey] = nil
+					continue
+				}
+				atomExpr, err := atom2expr(*pegMapValue, true)
+				if err != nil {
+					return nil, nil, err
+				}
+				matcher[pegMapKey] = atomExpr.(definition.TextTerminals)
+			}
+			current = definition.NewAtomPattern(matcher)
+		case PegSymbol:
+			var addition []definition.Rule
+			current, addition, err = s.symbol(child)
+			if err != nil {
+				return nil, nil, err
+			}
+			rules = append(rules, addition...)
+		default:
+			panic(fmt.Errorf("unexpected atom symbol: %v", child.Atom.Symbol))
+		}
+	}
+	return current, rules, nil
+}
+
+// quantifier parses bounds of {n}, {m,} and {m,n} suffixes; zero upper bound means that repetition is unbounded
+func quantifier(control string) (uint, uint, error) {
+	lower, upper, ranged := strings.Cut(strings.TrimSuffix(strings.TrimPrefix(control, "{"), "}"), ",")
+	min, err := strconv.ParseUint(lower, 10, 32)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid quantifier %v: %w", control, err)
+	}
+	max := min
+	if ranged && upper == "" {
+		return uint(min), 0, nil
+	}
+	if ranged {
+		if max, err = strconv.ParseUint(upper, 10, 32); err != nil {
+			return 0, 0, fmt.Errorf("invalid quantifier %v: %w", control, err)
+		}
+	}
+	if max == 0 {
+		return 0, 0, fmt.Errorf("invalid quantifier %v: upper bound must be positive", control)
+	}
+	if max < min {
+		return 0, 0, fmt.Errorf("invalid quantifier %v: upper bound is less than lower bound", control)
+	}
+	return uint(min), uint(max), nil
+}
+
 func createPegSymbol(node *parser.ParsingNode, atoms []definition.Atom) (definition.Symbol, error) {
 	atom := atoms[node.MustSelectBySymbol(PegSymbolToken).Segment.Start]
 	if atom.Symbol != PegToken {
@@ -195,6 +392,19 @@ func atom2expr(atom definition.Atom, textMatcher bool) (definition.Expr, error)
 			return nil, fmt.Errorf("unable to unescape %v token '%v': %w", atom.Symbol, token, err)
 		}
 		return definition.NewTextToken(unescaped), nil
+	case PegFoldedString:
+		token := string(atom.SelectText())
+		unescaped, err := strconv.Unquote(strings.TrimSuffix(token, "i"))
+		if err != nil {
+			return nil, fmt.Errorf("unable to unescape %v token '%v': %w", atom.Symbol, token, err)
+		}
+		return definition.NewFoldedToken(unescaped), nil
+	case PegCharClass:
+		class, err := definition.ParseCharClass(string(atom.SelectText()))
+		if err != nil {
+			return nil, fmt.Errorf("unable to parse %v token: %w", atom.Symbol, err)
+		}
+		return class, nil
 	case PegRegex:
 		token := string(atom.SelectText())
 		unescaped, err := strconv.Unquote(token)
@@ -214,6 +424,8 @@ func atom2expr(atom definition.Atom, textMatcher bool) (definition.Expr, error)
 			return definition.StartOfFile{}, nil
 		case definition.EndOfFileBuiltinSymbol:
 			return definition.EndOfFile{}, nil
+		case definition.InvalidUTF8BuiltinSymbol:
+			return definition.InvalidUTF8{}, nil
 		default:
 			panic(fmt.Errorf("unexpected builtin symbol: %v", symbol))
 		}
@@ -1,6 +1,8 @@
 package extension
 
 import (
+	"errors"
+	"github.com/sivukhin/gopeg/definition"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/sivukhin/gopeg/parser"
@@ -66,3 +68,158 @@ func TestInlineRules(t *testing.T) {
 		require.Equal(t, 2, node.Segment.Length())
 	}
 }
+
+func TestLeftRecursiveRules(t *testing.T) {
+	rules, err := Load(`Sum: Sum "+" Product / Product
+Product: Product "*" Num / Num
+Num: =~"[0-9]+"`)
+	require.Nil(t, err)
+	node, err := parser.ParseText(rules, "Sum", []byte(`1+2*3+4`))
+	require.Nil(t, err)
+	require.Equal(t, 7, node.Segment.Length())
+	require.Equal(t, "1+2*3", node.MustSelectBySymbol("Sum").Atom.SelectString())
+	require.Equal(t, "4", node.MustSelectBySymbol("Product").Atom.SelectString())
+}
+
+func TestLoadError(t *testing.T) {
+	_, err := Load("A: \"x\"\nB: / \"y\"\n")
+	var parseErr *parser.ParseError
+	require.True(t, errors.As(err, &parseErr))
+	require.Equal(t, 2, parseErr.Line)
+	require.Equal(t, 4, parseErr.Column)
+	require.Equal(t, []string{PegRule}, parseErr.Expected)
+
+	_, err = Load("A: \"x\" %\n")
+	require.True(t, errors.As(err, &parseErr))
+	require.Equal(t, 7, parseErr.Offset)
+	require.Equal(t, `'%'`, parseErr.Found)
+}
+
+func TestLoadRecovery(t *testing.T) {
+	rules, err := Load(`Statements: (Statement ";")*
+Statement: (Name "=" Name)~";"
+Name: =~"[a-z]+"`)
+	require.Nil(t, err)
+	node, diagnostics, err := parser.ParseTextWithRecovery(rules, "Statements", []byte(`a=b;c=;d=e;`))
+	require.Nil(t, err)
+	require.Equal(t, 11, node.Segment.Length())
+	require.Len(t, node.Children, 3)
+	require.Equal(t, parser.ErrorNode, node.Children[1].EnsureOnlySingle().Kind)
+	require.Equal(t, "c=", node.Children[1].Atom.SelectString())
+	require.Len(t, diagnostics, 1)
+	require.Equal(t, definition.Segment{Start: 4, End: 6}, diagnostics[0].Segment)
+	require.Equal(t, []string{"Name"}, diagnostics[0].Error.Expected)
+}
+
+func TestLoadAnnotations(t *testing.T) {
+	rules, err := Load(`@flatten
+List: Item ("," List)?
+@token @rename(Num)
+Item: =~"[0-9]" =~"[0-9]"*
+@drop Spaces: " "*
+`)
+	require.Nil(t, err)
+	require.Equal(t, []definition.Annotation{{Name: definition.FlattenAnnotation}}, rules[len(rules)-3].Annotations)
+	require.Equal(t, []definition.Annotation{
+		{Name: definition.TokenAnnotation},
+		{Name: definition.RenameAnnotation, Argument: "Num"},
+	}, rules[len(rules)-2].Annotations)
+	require.Equal(t, "@drop Spaces: \" \"*", rules[len(rules)-1].String())
+
+	node, err := parser.ParseText(rules, "List", []byte("12,3,45"))
+	require.Nil(t, err)
+	require.Len(t, node.Children, 3)
+	for _, child := range node.Children {
+		require.Equal(t, "Num", child.Atom.Symbol)
+		require.Empty(t, child.Children)
+	}
+
+	unknown, err := Load(`@unknown A: "a"`)
+	require.Nil(t, err)
+	_, err = parser.Compile(unknown)
+	require.ErrorContains(t, err, "unknown annotation @unknown of rule 'A'")
+}
+
+func TestLoadInvalidUTF8(t *testing.T) {
+	rules, err := Load(`Text: (Character / Invalid)*
+Character: .
+Invalid: @invalid-utf8`)
+	require.Nil(t, err)
+	require.Equal(t, definition.InvalidUTF8{}, rules[2].Expr)
+	node, err := parser.ParseText(rules, "Text", []byte("я\xff"), parser.WithUTF8())
+	require.Nil(t, err)
+	require.Len(t, node.Children, 2)
+	require.Equal(t, "Character", node.Children[0].Atom.Symbol)
+	require.Equal(t, "Invalid", node.Children[1].Atom.Symbol)
+}
+
+func TestLoadCharClass(t *testing.T) {
+	rules, err := Load(`Identifier: [a-zA-Z_] [a-zA-Z_0-9]* [^\]\n]?
+Word: [а-яё]+`)
+	require.Nil(t, err)
+	require.Equal(t, `Identifier: [a-zA-Z_] [a-zA-Z_0-9]* [^\]\n]?`, rules[0].String())
+	node, err := parser.ParseText(rules, "Identifier", []byte("snake_case_42!"))
+	require.Nil(t, err)
+	require.Equal(t, 14, node.Segment.Length())
+	_, err = parser.ParseText(rules, "Identifier", []byte("42"))
+	require.NotNil(t, err)
+	node, err = parser.ParseText(rules, "Word", []byte("ёжик"))
+	require.Nil(t, err)
+	require.Equal(t, 8, node.Segment.Length())
+
+	_, err = Load(`A: [z-a]`)
+	require.NotNil(t, err)
+}
+
+func TestLoadQuantifier(t *testing.T) {
+	rules, err := Load(`Octet: [0-9]{1,3}
+Address: Octet ("." Octet){3}
+Escape: "\\x" [0-9a-f]{2}
+Digits: [0-9]{2,}`)
+	require.Nil(t, err)
+	require.Equal(t, `Octet: [0-9]{1,3}`, rules[0].String())
+	require.Equal(t, `Address: Octet ("." Octet){3}`, rules[1].String())
+	require.Equal(t, `Digits: [0-9]{2,}`, rules[3].String())
+	for _, c := range []struct {
+		root, text string
+		ok         bool
+	}{
+		{root: "Address", text: "192.168.0.1", ok: true},
+		{root: "Address", text: "192.168.0", ok: false},
+		{root: "Address", text: "1921.168.0.1", ok: false},
+		{root: "Escape", text: `\x7f`, ok: true},
+		{root: "Escape", text: `\x7`, ok: false},
+		{root: "Digits", text: "1", ok: false},
+		{root: "Digits", text: "12345", ok: true},
+	} {
+		_, err := parser.ParseText(rules, c.root, []byte(c.text))
+		require.Equal(t, c.ok, err == nil, "%v on %q", c.root, c.text)
+	}
+
+	for _, invalid := range []string{`A: "a"{0}`, `A: "a"{3,2}`, `A: "a"{99999999999}`} {
+		_, err = Load(invalid)
+		require.NotNil(t, err, invalid)
+	}
+}
+
+func TestLoadFoldedString(t *testing.T) {
+	rules, err := Load(`Query: "select"i " " Name
+Name: =~"[a-z]+"`)
+	require.Nil(t, err)
+	require.Equal(t, `Query: "select"i " " Name`, rules[0].String())
+	node, err := parser.ParseText(rules, "Query", []byte("SeLeCt name"))
+	require.Nil(t, err)
+	require.Equal(t, "name", string(node.Children[0].Atom.SelectText()))
+	_, err = parser.ParseText(rules, "Query", []byte("selec name"))
+	require.NotNil(t, err)
+
+	rules, err = Load(`Keyword: {Token:"select"i}`)
+	require.Nil(t, err)
+	keyword, err := parser.ParseAtoms(rules, "Keyword", []definition.Atom{{
+		Symbol:       "Token",
+		Text:         []byte("SELECT"),
+		TextSelector: definition.BuildSegments(definition.Segment{Start: 0, End: 6}),
+	}})
+	require.Nil(t, err)
+	require.Equal(t, 1, keyword.Segment.Length())
+}
@@ -0,0 +1,190 @@
+package extension
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/parser"
+)
+
+type (
+	// template is the parameterized rule which is expanded into ordinary rule for every distinct list of arguments
+	template struct {
+		parameters []string
+		definition *parser.ParsingNode
+	}
+	// scope holds the state of the loading of the single grammar; arguments and instance are set only for the body of the instantiated template
+	scope struct {
+		atoms     []definition.Atom
+		templates map[string]template
+		instances map[string]struct{}
+		imports   map[string]*importedGrammar
+		arguments map[string]definition.Expr
+		instance  string
+	}
+	templateParameter struct {
+		template string
+		index    int
+	}
+)
+
+func (s *scope) define(name string, parameters, d *parser.ParsingNode) error {
+	if _, ok := s.templates[name]; ok {
+		return fmt.Errorf("parameterized rule %v is defined twice", name)
+	}
+	names := make([]string, 0)
+	for _, parameter := range parameters.EnsureOnlySymbol(PegParameter) {
+		if slices.Contains(names, parameter.Atom.SelectString()) {
+			return fmt.Errorf("parameter %v of %v is declared twice", parameter.Atom.SelectString(), name)
+		}
+		names = append(names, parameter.Atom.SelectString())
+	}
+	s.templates[name] = template{parameters: names, definition: d}
+	return nil
+}
+
+// symbol substitutes references to the parameters with the arguments and references with arguments with the instantiated rules
+func (s *scope) symbol(node *parser.ParsingNode) (definition.Expr, []definition.Rule, error) {
+	symbol, err := createPegSymbol(node, s.atoms)
+	if err != nil {
+		return nil, nil, err
+	}
+	if strings.Contains(symbol.Name, ".") {
+		if symbol.Name, err = s.resolve(symbol.Name); err != nil {
+			return nil, nil, err
+		}
+	}
+	if arguments, ok := node.TrySelectBySymbol(PegArguments); ok {
+		return s.instantiate(symbol, arguments)
+	}
+	if argument, ok := s.arguments[symbol.Name]; ok {
+		if symbol.Attributes != nil {
+			return nil, nil, fmt.Errorf("attributes can't be set for parameter %v", symbol.Name)
+		}
+		return argument, nil, nil
+	}
+	if _, ok := s.templates[symbol.Name]; ok {
+		return nil, nil, fmt.Errorf("parameterized rule %v is used without arguments", symbol.Name)
+	}
+	return symbol, nil, nil
+}
+
+// instantiate expands the template once for every distinct list of arguments: instance is named after the template and its arguments
+// (for example, List<Item, ",">), so all usages with the same arguments refer to the same rule
+func (s *scope) instantiate(symbol definition.Symbol, node *parser.ParsingNode) (definition.Expr, []definition.Rule, error) {
+	t, ok := s.templates[symbol.Name]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown parameterized rule %v", symbol.Name)
+	}
+	given := node.EnsureOnlySymbol(PegRule)
+	if len(given) != len(t.parameters) {
+		return nil, nil, fmt.Errorf("parameterized rule %v expects %v arguments, given %v", symbol.Name, len(t.parameters), len(given))
+	}
+	rules := make([]definition.Rule, 0)
+	arguments := make(map[string]definition.Expr)
+	names := make([]string, 0, len(t.parameters))
+	for k, argument := range given {
+		expr, addition, err := rule(argument, s)
+		if err != nil {
+			return nil, nil, err
+		}
+		rules = append(rules, addition...)
+		arguments[t.parameters[k]] = expr
+		names = append(names, expr.String())
+	}
+	name := fmt.Sprintf("%v<%v>", symbol.Name, strings.Join(names, ", "))
+	if strings.Contains(name, "@") {
+		return nil, nil, fmt.Errorf("aliases can't be used in arguments of parameterized rule: %v", name)
+	}
+	instance := definition.NewSymbol(name, symbol.Attributes)
+	if _, ok := s.instances[name]; ok {
+		return instance, rules, nil
+	}
+	s.instances[name] = struct{}{}
+	body := &scope{atoms: s.atoms, templates: s.templates, instances: s.instances, imports: s.imports, arguments: arguments, instance: name}
+	expr, addition, err := rule(t.definition.MustSelectBySymbol(PegRule), body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to instantiate %v: %w", name, err)
+	}
+	annotations, err := definitionAnnotations(t.definition)
+	if err != nil {
+		return nil, nil, err
+	}
+	rules = append(rules, addition...)
+	rules = append(rules, definition.NewAnnotatedRule(name, expr, annotations...))
+	return instance, rules, nil
+}
+
+// checkTermination rejects templates which instantiate themselves with ever growing arguments.
+// Parameter can flow into the argument of another instantiation either as is or as a part of the bigger expression:
+// if the latter happens on the cycle of such flows then every recursive instantiation gets new arguments and expansion never ends
+func (s *scope) checkTermination() error {
+	names := make([]string, 0, len(s.templates))
+	for name := range s.templates {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	flows := make(map[templateParameter][]templateParameter)
+	growing := make([][2]templateParameter, 0)
+	for _, name := range names {
+		t := s.templates[name]
+		t.definition.MustSelectBySymbol(PegRule).Traverse(func(node *parser.ParsingNode, next func(nodes []*parser.ParsingNode)) {
+			next(node.Children)
+			arguments, ok := node.TrySelectBySymbol(PegArguments)
+			if node.Atom.Symbol != PegSymbol || !ok {
+				return
+			}
+			callee := node.MustSelectBySymbol(PegSymbolToken).Atom.SelectString()
+			for k, argument := range arguments.EnsureOnlySymbol(PegRule) {
+				for p, parameter := range t.parameters {
+					direct := argument.Segment.Length() == 1 && s.atoms[argument.Segment.Start].SelectString() == parameter
+					if !direct && !references(argument, parameter) {
+						continue
+					}
+					from, to := templateParameter{template: name, index: p}, templateParameter{template: callee, index: k}
+					flows[from] = append(flows[from], to)
+					if !direct {
+						growing = append(growing, [2]templateParameter{from, to})
+					}
+				}
+			}
+		})
+	}
+	for _, flow := range growing {
+		if reachable(flows, flow[1], flow[0]) {
+			from := flow[0]
+			return fmt.Errorf("recursive instantiation of %v never terminates: parameter %v grows on every step", from.template, s.templates[from.template].parameters[from.index])
+		}
+	}
+	return nil
+}
+
+func references(node *parser.ParsingNode, parameter string) bool {
+	found := false
+	node.Traverse(func(node *parser.ParsingNode, next func(nodes []*parser.ParsingNode)) {
+		if node.Atom.Symbol == PegSymbolToken && node.Atom.SelectString() == parameter {
+			found = true
+		}
+		next(node.Children)
+	})
+	return found
+}
+
+func reachable(flows map[templateParameter][]templateParameter, from, to templateParameter) bool {
+	visited := map[templateParameter]struct{}{from: {}}
+	queue := []templateParameter{from}
+	for i := 0; i < len(queue); i++ {
+		if queue[i] == to {
+			return true
+		}
+		for _, next := range flows[queue[i]] {
+			if _, ok := visited[next]; !ok {
+				visited[next] = struct{}{}
+				queue = append(queue, next)
+			}
+		}
+	}
+	return false
+}
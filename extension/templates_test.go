@@ -0,0 +1,84 @@
+package extension
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gopeg/parser"
+)
+
+func TestLoadParameterizedRules(t *testing.T) {
+	rules, err := Load(`Call: Name "(" List<Arg, ","> ")"
+List<Item, Sep>: Item (Sep Item)*
+Arg: Name / "[" List<Arg, ";"> "]" / "{" List<Name, ","> "}"
+Pairs: List<Name ":" Name, ",">
+Name: [a-z]+`)
+	require.Nil(t, err)
+	names := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		names = append(names, rule.String())
+	}
+	require.Equal(t, []string{
+		`List<Arg, ",">: Arg ("," Arg)*`,
+		`Call: Name "(" List<Arg, ","> ")"`,
+		`List<Arg, ";">: Arg (";" Arg)*`,
+		`List<Name, ",">: Name ("," Name)*`,
+		`Arg: Name / "[" List<Arg, ";"> "]" / "{" List<Name, ","> "}"`,
+		`List<Name ":" Name, ",">: Name ":" Name ("," Name ":" Name)*`,
+		`Pairs: List<Name ":" Name, ",">`,
+		`Name: [a-z]+`,
+	}, names)
+
+	node, err := parser.ParseText(rules, "Call", []byte("f(a,[b;c],{d,e})"))
+	require.Nil(t, err)
+	require.Equal(t, `List<Arg, ",">`, node.Children[1].Atom.Symbol)
+	require.Len(t, node.Children[1].Children, 3)
+	_, err = parser.ParseText(rules, "Pairs", []byte("a:b,c:d"))
+	require.Nil(t, err)
+}
+
+func TestLoadParameterizedRulesNesting(t *testing.T) {
+	rules, err := Load(`Root: Commas<[a-z]>
+Commas<X>: List<X, ",">
+List<Item, Sep>: Item (Sep List<Item, Sep>)?
+Assignments: Assignment<[a-z]> ";" Assignment<[0-9]>
+Assignment<X>: Key:X "=" X`)
+	require.Nil(t, err)
+	node, err := parser.ParseText(rules, "Root", []byte("a,b,c"))
+	require.Nil(t, err)
+	require.Equal(t, `Commas<[a-z]>`, node.Children[0].Atom.Symbol)
+	node, err = parser.ParseText(rules, "Assignments", []byte("a=b;1=2"))
+	require.Nil(t, err)
+	require.Equal(t, "Key", node.Children[0].Children[0].Atom.Symbol)
+	require.Equal(t, "1", node.Children[1].Children[0].Atom.SelectString())
+
+	rules, err = Load(`Root: Wrapped<"a", "b">
+@inline
+Wrapped<X, Y>: "(" Wrapped<Y, X> ")" / X`)
+	require.Nil(t, err)
+	require.Len(t, rules, 3)
+	_, err = parser.ParseText(rules, "Root", []byte("((a))"))
+	require.Nil(t, err)
+	_, err = parser.ParseText(rules, "Root", []byte("(a)"))
+	require.NotNil(t, err)
+}
+
+func TestLoadParameterizedRulesErrors(t *testing.T) {
+	for _, c := range []struct{ name, text, err string }{
+		{name: "growing", text: "A: T<\"x\">\nT<X>: \"a\" T<\"b\" X> / X", err: "recursive instantiation of T never terminates: parameter X grows on every step"},
+		{name: "mutually growing", text: "A: T<\"x\">\nT<X>: U<X> / X\nU<Y>: T<Y+>", err: "recursive instantiation of U never terminates: parameter Y grows on every step"},
+		{name: "arity", text: "A: T<\"x\">\nT<X, Y>: X Y", err: "parameterized rule T expects 2 arguments, given 1"},
+		{name: "unknown", text: "A: T<\"x\">", err: "unknown parameterized rule T"},
+		{name: "without arguments", text: "A: T\nT<X>: X", err: "parameterized rule T is used without arguments"},
+		{name: "duplicate parameter", text: "T<X, X>: X", err: "parameter X of T is declared twice"},
+		{name: "duplicate definition", text: "T<X>: X\nT<Y>: Y", err: "parameterized rule T is defined twice"},
+		{name: "alias in argument", text: "A: T<B:\"x\">\nT<X>: X", err: "aliases can't be used in arguments of parameterized rule"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			_, err := Load(c.text)
+			require.NotNil(t, err)
+			require.Contains(t, err.Error(), c.err)
+		})
+	}
+}
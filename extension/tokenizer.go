@@ -8,14 +8,18 @@ const (
 	PegText          = "Text"
 	PegSequence      = "#Sequence"
 	PegString        = "String"
+	PegFoldedString  = "FoldedString"
+	PegCharClass     = "CharClass"
 	PegRegex         = "Regex"
 	PegToken         = "Token"
 	PegControl       = "Control"
+	PegQuantifier    = "Quantifier"
 	PegDot           = "Dot"
 	PegEndOfLine     = "EndOfLine"
 	PegOpen          = "Open"
 	PegClose         = "Close"
 	PegBuiltinSymbol = "BuiltinSymbol"
+	PegAnnotation    = "Annotation"
 )
 
 var (
@@ -36,10 +40,14 @@ var (
 				definition.NewTextToken("*/"),
 			),
 			definition.NewJunction(definition.NewTextToken("=~"), definition.NewSymbol(PegRegex)),
+			definition.NewSymbol(PegFoldedString),
 			definition.NewSymbol(PegString),
+			definition.NewSymbol(PegCharClass),
 			definition.NewSymbol(PegToken),
+			definition.NewSymbol(PegQuantifier),
 			definition.NewSymbol(PegControl),
 			definition.NewSymbol(PegBuiltinSymbol),
+			definition.NewSymbol(PegAnnotation),
 			definition.NewSymbol(PegDot),
 			definition.NewJunction(
 				definition.NewSymbol(PegOpen),
@@ -51,16 +59,24 @@ var (
 			),
 		), 1)),
 		definition.NewRule(PegRegex, definition.NewSymbol(PegString)),
+		definition.NewRule(PegFoldedString, definition.NewChoice(
+			definition.NewTextPattern(`"(\\.|[^"\\])*"i\b`),
+			definition.NewTextPattern("`[^`]*`i\\b"),
+		)),
 		definition.NewRule(PegString, definition.NewChoice(
 			definition.NewTextPattern(`"(\\.|[^"\\])*"`),
 			definition.NewTextPattern("`[^`]*`"),
 		)),
-		definition.NewRule(PegToken, definition.NewTextPattern("[#a-zA-Z][0-9a-zA-Z_]*")),
-		definition.NewRule(PegControl, definition.NewTextPattern("[:/*+?{},!&]")),
+		definition.NewRule(PegCharClass, definition.NewTextPattern(`\[(\\.|[^\]\\\n])+\]`)),
+		definition.NewRule(PegToken, definition.NewTextPattern(`[#a-zA-Z][0-9a-zA-Z_]*(\.[a-zA-Z][0-9a-zA-Z_]*)?`)),
+		definition.NewRule(PegQuantifier, definition.NewTextPattern(`\{[0-9]+(,[0-9]*)?\}`)),
+		definition.NewRule(PegControl, definition.NewCharClass("[:/*+?{},!&~<>]")),
 		definition.NewRule(PegBuiltinSymbol, definition.NewChoice(
 			definition.NewTextToken("@sof"),
 			definition.NewTextToken("@eof"),
+			definition.NewTextToken("@invalid-utf8"),
 		)),
+		definition.NewRule(PegAnnotation, definition.NewTextPattern(`@[a-z][a-z-]*(\([^()\n]*\))?`)),
 		definition.NewRule(PegEndOfLine, definition.NewChoice(
 			definition.NewTextToken("\n"),
 			definition.NewNegation(definition.NewDot())),
@@ -0,0 +1,701 @@
+package generator
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/sivukhin/gopeg/analysis"
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/parser"
+)
+
+type (
+	Option          func(options *generateOptions)
+	generateOptions struct {
+		packageName string
+		root        string
+		utf8        bool
+	}
+)
+
+func WithPackage(packageName string) Option {
+	return func(options *generateOptions) { options.packageName = packageName }
+}
+
+// WithRoot selects the rule which will be matched by generated Parse functions; by default it is the first rule
+func WithRoot(root string) Option {
+	return func(options *generateOptions) { options.root = root }
+}
+
+// WithUTF8 makes Dot of the generated parser consume single UTF-8 encoded character as parser.WithUTF8 option does
+func WithUTF8() Option {
+	return func(options *generateOptions) { options.utf8 = true }
+}
+
+type (
+	generatedRule struct {
+		Index int
+		Name  string
+		Expr  string
+		Body  string
+	}
+	generatedTerminal struct {
+		Index int
+		Expr  string
+		Body  string
+	}
+	generatedFile struct {
+		Package    string
+		Elem       string
+		Imports    []string
+		Vars       []string
+		Rules      []generatedRule
+		Terminals  []generatedTerminal
+		Tables     string
+		Root       int
+		RootName   string
+		UsedBytes  bool
+		UsedRegexp bool
+	}
+	// generator keeps normalized grammar in the same order as parser evaluates it
+	generator struct {
+		elem       string
+		order      []string
+		position   map[string]int
+		exprs      []definition.Expr
+		terminals  map[string]int
+		file       generatedFile
+		utf8       bool
+		usedBytes  bool
+		usedRegexp bool
+		usedUTF8   bool
+	}
+)
+
+// Generate emits source of the standalone Go package which parses input with the rules without interpreting them at runtime:
+// every normalized rule is translated into the specialized function and results are returned as parser.ParsingNode trees
+// which are identical to the trees built by the parser package (recovery mode is not supported by generated code)
+func Generate(rules definition.Rules, opts ...Option) ([]byte, error) {
+	options := generateOptions{packageName: "parser"}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("grammar must have at least one rule")
+	}
+	if options.root == "" {
+		options.root = rules[0].Name
+	}
+	if _, err := parser.Compile(rules, parser.WithRoot(options.root)); err != nil {
+		return nil, fmt.Errorf("unable to compile rules: %w", err)
+	}
+	terminalType, err := analysis.CheckRulesConsistency(rules)
+	if err != nil {
+		return nil, err
+	}
+	normalized, transformation := analysis.NormalizeRules(analysis.DesugarRules(rules))
+	components, recursive, err := parser.OrderRuleComponents(normalized)
+	if err != nil {
+		return nil, fmt.Errorf("unable to topologically order rules: %w", err)
+	}
+	ruleMap := make(map[string]definition.Expr)
+	for _, rule := range normalized {
+		ruleMap[rule.Name] = rule.Expr
+	}
+	g := &generator{elem: "byte", utf8: options.utf8, position: make(map[string]int), terminals: make(map[string]int)}
+	if terminalType == analysis.AtomTerminalType {
+		g.elem = "definition.Atom"
+	}
+	componentOf := make([]int, 0, len(normalized))
+	componentIndices := make([][]int, 0, len(components))
+	recursiveRules := make([]bool, 0, len(normalized))
+	for c, component := range components {
+		indices := make([]int, 0, len(component))
+		for _, name := range component {
+			g.position[name] = len(g.order)
+			indices = append(indices, len(g.order))
+			g.order = append(g.order, name)
+			g.exprs = append(g.exprs, ruleMap[name])
+			componentOf = append(componentOf, c)
+			recursiveRules = append(recursiveRules, recursive[name])
+		}
+		componentIndices = append(componentIndices, indices)
+	}
+
+	shapes := make([]string, 0, len(g.exprs))
+	for s, expr := range g.exprs {
+		body, err := g.rule(expr)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate rule %v: %w", g.order[s], err)
+		}
+		g.file.Rules = append(g.file.Rules, generatedRule{Index: s, Name: g.order[s], Expr: expr.String(), Body: body})
+		shape, err := g.shape(expr)
+		if err != nil {
+			return nil, fmt.Errorf("unable to generate rule %v: %w", g.order[s], err)
+		}
+		shapes = append(shapes, shape)
+	}
+
+	var tables strings.Builder
+	fmt.Fprintf(&tables, "names = %#v\n", g.order)
+	fmt.Fprintf(&tables, "recursive = %#v\n", recursiveRules)
+	fmt.Fprintf(&tables, "component = %#v\n", componentOf)
+	tables.WriteString("components = [][]int{")
+	for _, indices := range componentIndices {
+		fmt.Fprintf(&tables, "{%v}, ", strings.Join(strings.Fields(strings.Trim(fmt.Sprint(indices), "[]")), ", "))
+	}
+	tables.WriteString("}\n")
+	fmt.Fprintf(&tables, "shapes = []shape{\n%v}\n", strings.Join(shapes, ""))
+	mappingKeys := make([]string, 0, len(transformation.Backward))
+	for key := range transformation.Backward {
+		mappingKeys = append(mappingKeys, key)
+	}
+	slices.Sort(mappingKeys)
+	tables.WriteString("mapping = map[string]string{\n")
+	for _, key := range mappingKeys {
+		fmt.Fprintf(&tables, "%q: %q,\n", key, transformation.Backward[key])
+	}
+	tables.WriteString("}\n")
+	tables.WriteString("nodeShapes = map[string]nodeShape{\n")
+	for _, rule := range rules {
+		if len(rule.Annotations) > 0 {
+			fmt.Fprintf(&tables, "%q: %v,\n", rule.Name, shapeLiteral(rule.Annotations))
+		}
+	}
+	tables.WriteString("}\n")
+
+	g.file.Package = options.packageName
+	g.file.Elem = g.elem
+	g.file.Tables = tables.String()
+	g.file.Root = g.position[transformation.Forward[options.root]]
+	g.file.RootName = options.root
+	g.file.Imports = []string{`"fmt"`, `"math"`}
+	if g.usedBytes {
+		g.file.Imports = append(g.file.Imports, `"bytes"`)
+	}
+	if g.usedRegexp {
+		g.file.Imports = append(g.file.Imports, `"regexp"`)
+	}
+	if g.usedUTF8 {
+		g.file.Imports = append(g.file.Imports, `"unicode/utf8"`)
+	}
+	slices.Sort(g.file.Imports)
+
+	var source bytes.Buffer
+	if err := fileTemplate.Execute(&source, g.file); err != nil {
+		return nil, fmt.Errorf("unable to execute template: %w", err)
+	}
+	formatted, err := format.Source(source.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("unable to format generated code: %w", err)
+	}
+	return formatted, nil
+}
+
+// leaf returns Go expression which evaluates normalized leaf at the given position
+func (g *generator) leaf(expr definition.Expr, position string) (string, error) {
+	if symbol, ok := expr.(definition.Symbol); ok {
+		return fmt.Sprintf("p.at(%v, %v)", position, g.position[symbol.Name]), nil
+	}
+	terminal, ok := expr.(definition.Terminals)
+	if !ok {
+		return "", fmt.Errorf("unexpected peg expression type in normalized rule: %#v", expr)
+	}
+	k, err := g.terminal(terminal)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("p.terminal%v(%v)", k, position), nil
+}
+
+func (g *generator) rule(expr definition.Expr) (string, error) {
+	var body strings.Builder
+	switch peg := expr.(type) {
+	case definition.Junction:
+		body.WriteString("current := i\n")
+		for _, leaf := range peg.Exprs {
+			code, err := g.leaf(leaf, "current")
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&body, "if next := %v; !next.ok {\nreturn step{}\n} else {\ncurrent += next.advance\n}\n", code)
+		}
+		body.WriteString("return step{ok: true, advance: current - i}\n")
+	case definition.Choice:
+		for _, leaf := range peg.Exprs {
+			code, err := g.leaf(leaf, "i")
+			if err != nil {
+				return "", err
+			}
+			fmt.Fprintf(&body, "if next := %v; next.ok {\nreturn next\n}\n", code)
+		}
+		body.WriteString("return step{}\n")
+	case definition.Kleene:
+		code, err := g.leaf(peg.Expr, "current")
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&body, "current := i\nfor {\nnext := %v\nif !next.ok || next.advance == 0 {\nbreak\n}\ncurrent += next.advance\n}\nreturn step{ok: true, advance: current - i}\n", code)
+	case definition.Negation:
+		code, err := g.leaf(peg.Expr, "i")
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&body, "if %v.ok {\nreturn step{}\n}\nreturn step{ok: true}\n", code)
+	case definition.Recovery:
+		code, err := g.leaf(peg.Expr, "i")
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&body, "return %v\n", code)
+	default:
+		code, err := g.leaf(peg, "i")
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(&body, "return %v\n", code)
+	}
+	return body.String(), nil
+}
+
+func (g *generator) shape(expr definition.Expr) (string, error) {
+	kind, leaves := "kindTerminal", []definition.Expr{expr}
+	switch peg := expr.(type) {
+	case definition.Symbol:
+		kind = "kindSymbol"
+	case definition.Recovery:
+		kind, leaves = "kindSymbol", []definition.Expr{peg.Expr}
+	case definition.Junction:
+		kind, leaves = "kindJunction", peg.Exprs
+	case definition.Choice:
+		kind, leaves = "kindChoice", peg.Exprs
+	case definition.Kleene:
+		kind, leaves = "kindKleene", []definition.Expr{peg.Expr}
+	case definition.Negation:
+		kind, leaves = "kindNegation", []definition.Expr{peg.Expr}
+	}
+	codes := make([]string, 0, len(leaves))
+	for _, leaf := range leaves {
+		if symbol, ok := leaf.(definition.Symbol); ok {
+			codes = append(codes, fmt.Sprintf("{rule: %v, name: %q, attributes: %v}", g.position[symbol.Name], symbol.Name, attributesLiteral(symbol.Attributes)))
+			continue
+		}
+		terminal, ok := leaf.(definition.Terminals)
+		if !ok {
+			return "", fmt.Errorf("unexpected peg expression type in normalized rule: %#v", leaf)
+		}
+		k, err := g.terminal(terminal)
+		if err != nil {
+			return "", err
+		}
+		codes = append(codes, fmt.Sprintf("{rule: -1, terminal: %v}", k))
+	}
+	return fmt.Sprintf("{kind: %v, leaves: []leaf{%v}},\n", kind, strings.Join(codes, ", ")), nil
+}
+
+// terminal registers specialized matching function for the terminal and returns its index
+func (g *generator) terminal(terminal definition.Terminals) (int, error) {
+	key := fmt.Sprintf("%T %v", terminal, terminal)
+	if k, ok := g.terminals[key]; ok {
+		return k, nil
+	}
+	k := len(g.file.Terminals)
+	var body string
+	switch peg := terminal.(type) {
+	case definition.Empty:
+		body = "return step{ok: true}\n"
+	case definition.Dot:
+		if g.utf8 && g.elem == "byte" {
+			g.usedUTF8 = true
+			body = "current, size := utf8.DecodeRune(p.data[i:])\nif size == 0 || (current == utf8.RuneError && size == 1) {\nreturn step{}\n}\nreturn step{ok: true, advance: size}\n"
+			break
+		}
+		body = "if i < len(p.data) {\nreturn step{ok: true, advance: 1}\n}\nreturn step{}\n"
+	case definition.InvalidUTF8:
+		g.usedUTF8 = true
+		body = "if current, size := utf8.DecodeRune(p.data[i:]); current == utf8.RuneError && size == 1 {\nreturn step{ok: true, advance: 1}\n}\nreturn step{}\n"
+	case definition.StartOfFile:
+		body = "return step{ok: i == 0}\n"
+	case definition.EndOfFile:
+		body = "return step{ok: i == len(p.data)}\n"
+	case definition.TextToken:
+		g.usedBytes = true
+		g.file.Vars = append(g.file.Vars, fmt.Sprintf("token%v = []byte(%q)", k, peg.Text))
+		body = fmt.Sprintf("if bytes.HasPrefix(p.data[i:], token%v) {\nreturn step{ok: true, advance: %v}\n}\nreturn step{}\n", k, len(peg.Text))
+	case definition.FoldedToken:
+		g.file.Vars = append(g.file.Vars, fmt.Sprintf("foldedToken%v = definition.FoldedToken{Text: []byte(%q)}", k, peg.Text))
+		body = fmt.Sprintf("advance, ok := foldedToken%v.Match(p.data[i:])\nreturn step{ok: ok, advance: advance}\n", k)
+	case definition.CharClass:
+		g.file.Vars = append(g.file.Vars, fmt.Sprintf("charClass%v = definition.NewCharClass(%q).(definition.CharClass)", k, peg.Expr))
+		body = fmt.Sprintf("advance, ok := charClass%v.Match(p.data[i:])\nreturn step{ok: ok, advance: advance}\n", k)
+	case definition.TextPattern:
+		g.usedRegexp = true
+		g.file.Vars = append(g.file.Vars, fmt.Sprintf("pattern%v = regexp.MustCompile(%q)", k, peg.Expr))
+		body = fmt.Sprintf("location := pattern%v.FindIndex(p.data[i:])\nif location == nil || location[0] != 0 {\nreturn step{}\n}\nreturn step{ok: true, advance: location[1]}\n", k)
+	case definition.AtomPattern:
+		literal, err := g.atomPatternLiteral(peg)
+		if err != nil {
+			return 0, err
+		}
+		g.file.Vars = append(g.file.Vars, fmt.Sprintf("atomPattern%v = %v", k, literal))
+		body = fmt.Sprintf("advance, ok := definition.Accept[definition.Atom](atomPattern%v, p.data, i)\nreturn step{ok: ok, advance: advance}\n", k)
+	default:
+		return 0, fmt.Errorf("unsupported terminal: %#v", terminal)
+	}
+	g.terminals[key] = k
+	g.file.Terminals = append(g.file.Terminals, generatedTerminal{Index: k, Expr: terminal.String(), Body: body})
+	return k, nil
+}
+
+func (g *generator) atomPatternLiteral(pattern definition.AtomPattern) (string, error) {
+	keys := make([]string, 0, len(pattern.Matcher))
+	for key := range pattern.Matcher {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	var literal strings.Builder
+	literal.WriteString("definition.AtomPattern{Matcher: map[string]definition.TextTerminals{")
+	for _, key := range keys {
+		switch matcher := pattern.Matcher[key].(type) {
+		case nil:
+			fmt.Fprintf(&literal, "%q: nil, ", key)
+		case definition.TextToken:
+			fmt.Fprintf(&literal, "%q: definition.TextToken{Text: []byte(%q)}, ", key, matcher.Text)
+		case definition.FoldedToken:
+			fmt.Fprintf(&literal, "%q: definition.FoldedToken{Text: []byte(%q)}, ", key, matcher.Text)
+		case definition.CharClass:
+			fmt.Fprintf(&literal, "%q: definition.NewCharClass(%q).(definition.CharClass), ", key, matcher.Expr)
+		case definition.TextPattern:
+			g.usedRegexp = true
+			fmt.Fprintf(&literal, "%q: definition.TextPattern{Expr: %q, Regex: regexp.MustCompile(%q)}, ", key, matcher.Expr, matcher.Expr)
+		default:
+			return "", fmt.Errorf("unsupported atom pattern matcher: %#v", matcher)
+		}
+	}
+	literal.WriteString("}}")
+	return literal.String(), nil
+}
+
+// shapeLiteral relies on the annotations being validated by parser.Compile
+func shapeLiteral(annotations []definition.Annotation) string {
+	fields := make([]string, 0, len(annotations))
+	for _, annotation := range annotations {
+		switch annotation.Name {
+		case definition.InlineAnnotation:
+			fields = append(fields, "inline: true")
+		case definition.FlattenAnnotation:
+			fields = append(fields, "flatten: true")
+		case definition.CollapseSingleChildAnnotation:
+			fields = append(fields, "collapse: true")
+		case definition.TokenAnnotation:
+			fields = append(fields, "token: true")
+		case definition.DropAnnotation:
+			fields = append(fields, "drop: true")
+		case definition.RenameAnnotation:
+			fields = append(fields, fmt.Sprintf("rename: %q", annotation.Argument))
+		}
+	}
+	return "{" + strings.Join(fields, ", ") + "}"
+}
+
+func attributesLiteral(attributes map[string][]byte) string {
+	if attributes == nil {
+		return "nil"
+	}
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	var literal strings.Builder
+	literal.WriteString("map[string][]byte{")
+	for _, key := range keys {
+		if attributes[key] == nil {
+			fmt.Fprintf(&literal, "%q: nil, ", key)
+		} else {
+			fmt.Fprintf(&literal, "%q: []byte(%q), ", key, attributes[key])
+		}
+	}
+	literal.WriteString("}")
+	return literal.String()
+}
+
+var fileTemplate = template.Must(template.New("file").Parse(`// Code generated by gopeg generate; DO NOT EDIT.
+
+package {{.Package}}
+
+import (
+{{- range .Imports}}
+	{{.}}
+{{- end}}
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/parser"
+)
+
+type (
+	cell struct{ position, rule int }
+	step struct {
+		ok      bool
+		advance int
+	}
+	version struct {
+		seq  int
+		step step
+	}
+	leaf struct {
+		rule       int
+		terminal   int
+		name       string
+		attributes map[string][]byte
+	}
+	shape struct {
+		kind   int
+		leaves []leaf
+	}
+	frame struct {
+		node *parser.ParsingNode
+		rule int
+		seq  int
+	}
+	nodeShape struct {
+		inline   bool
+		flatten  bool
+		collapse bool
+		token    bool
+		drop     bool
+		rename   string
+	}
+	state struct {
+		data     []{{.Elem}}
+		memo     map[cell]step
+		seq      int
+		versions map[cell][]version
+	}
+)
+
+const (
+	kindTerminal = iota
+	kindSymbol
+	kindJunction
+	kindChoice
+	kindKleene
+	kindNegation
+)
+
+const root = {{.Root}}
+
+var (
+{{- range .Vars}}
+	{{.}}
+{{- end}}
+)
+
+var (
+{{.Tables}}
+)
+
+// Parse matches the longest prefix of the data with {{.RootName}} rule
+func Parse(data []{{.Elem}}) (*parser.ParsingNode, error) { return parse(data, false) }
+
+// ParseFull matches the whole data with {{.RootName}} rule
+func ParseFull(data []{{.Elem}}) (*parser.ParsingNode, error) { return parse(data, true) }
+
+func parse(data []{{.Elem}}, full bool) (*parser.ParsingNode, error) {
+	p := &state{data: data, memo: make(map[cell]step), versions: make(map[cell][]version)}
+	rootStep := p.at(0, root)
+	if !rootStep.ok {
+		return nil, fmt.Errorf("%w: rule {{.RootName}} doesn't match", parser.TextNotMatchErr)
+	}
+	if full && rootStep.advance != len(data) {
+		return nil, fmt.Errorf("%w: rule {{.RootName}} matched only first %v elements out of %v", parser.TextNotMatchErr, rootStep.advance, len(data))
+	}
+	nodes := transform(p.build(rootStep))
+	if len(nodes) != 1 {
+		return nil, fmt.Errorf("tree with multiple root was formed")
+	}
+	return nodes[0], nil
+}
+
+func (p *state) at(i, s int) step {
+	key := cell{position: i, rule: s}
+	if result, ok := p.memo[key]; ok {
+		return result
+	}
+	if !recursive[s] {
+		result := p.evaluate(i, s)
+		p.memo[key] = result
+		return result
+	}
+	group := components[component[s]]
+	for _, r := range group {
+		p.memo[cell{position: i, rule: r}] = step{}
+		p.versions[cell{position: i, rule: r}] = []version{{"{{"}}seq: -1{{"}}"}}
+	}
+	for grown := true; grown; {
+		grown = false
+		for _, r := range group {
+			p.seq++
+			seq := p.seq
+			next := p.evaluate(i, r)
+			if current := p.memo[cell{position: i, rule: r}]; next.ok && (!current.ok || next.advance > current.advance) {
+				p.memo[cell{position: i, rule: r}] = next
+				p.versions[cell{position: i, rule: r}] = append(p.versions[cell{position: i, rule: r}], version{seq: seq, step: next})
+				grown = true
+			}
+		}
+	}
+	return p.memo[key]
+}
+
+func (p *state) versionBefore(i, s, seq int) (step, int) {
+	versions := p.versions[cell{position: i, rule: s}]
+	for k := len(versions) - 1; k >= 0; k-- {
+		if versions[k].seq < seq {
+			return versions[k].step, versions[k].seq
+		}
+	}
+	panic(fmt.Errorf("left-recursive cell %v has no versions before %v", cell{position: i, rule: s}, seq))
+}
+
+func (p *state) evaluate(i, s int) step {
+	switch s {
+{{- range .Rules}}
+	case {{.Index}}:
+		return p.rule{{.Index}}(i)
+{{- end}}
+	}
+	panic(fmt.Errorf("unexpected rule: %v", s))
+}
+
+func (p *state) terminal(i, k int) step {
+	switch k {
+{{- range .Terminals}}
+	case {{.Index}}:
+		return p.terminal{{.Index}}(i)
+{{- end}}
+	}
+	panic(fmt.Errorf("unexpected terminal: %v", k))
+}
+{{range .Rules}}
+// rule{{.Index}} evaluates {{.Name}}: {{.Expr}}
+func (p *state) rule{{.Index}}(i int) step {
+{{.Body -}}
+}
+{{end}}
+{{- range .Terminals}}
+// terminal{{.Index}} matches {{.Expr}}
+func (p *state) terminal{{.Index}}(i int) step {
+{{.Body -}}
+}
+{{end}}
+func (p *state) derive(parent frame, i int, l leaf) (step, int) {
+	if l.rule < 0 {
+		return p.terminal(i, l.terminal), 0
+	}
+	if !recursive[l.rule] {
+		return p.at(i, l.rule), 0
+	}
+	limit := math.MaxInt
+	if i == parent.node.Segment.Start && component[l.rule] == component[parent.rule] {
+		limit = parent.seq
+	}
+	p.at(i, l.rule)
+	return p.versionBefore(i, l.rule, limit)
+}
+
+func (p *state) build(rootStep step) *parser.ParsingNode {
+	rootNode := parser.NewParsingNode[{{.Elem}}](names[root], nil, p.data, definition.Segment{Start: 0, End: rootStep.advance})
+	rootFrame := frame{node: &rootNode, rule: root, seq: math.MaxInt}
+	if recursive[root] {
+		_, rootFrame.seq = p.versionBefore(0, root, math.MaxInt)
+	}
+	derivation := []frame{rootFrame}
+	for k := 0; k < len(derivation); k++ {
+		current := derivation[k]
+		addChild := func(l leaf, segment definition.Segment, seq int) {
+			if l.rule < 0 {
+				return
+			}
+			next := parser.NewParsingNode[{{.Elem}}](l.name, l.attributes, p.data, segment)
+			current.node.Children = append(current.node.Children, &next)
+			derivation = append(derivation, frame{node: &next, rule: l.rule, seq: seq})
+		}
+		start := current.node.Segment.Start
+		rule := shapes[current.rule]
+		switch rule.kind {
+		case kindSymbol:
+			next, seq := p.derive(current, start, rule.leaves[0])
+			if next.ok {
+				addChild(rule.leaves[0], current.node.Segment, seq)
+			}
+		case kindKleene:
+			for position := start; ; {
+				next, seq := p.derive(current, position, rule.leaves[0])
+				if !next.ok || next.advance == 0 {
+					break
+				}
+				addChild(rule.leaves[0], definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindJunction:
+			position := start
+			for _, l := range rule.leaves {
+				next, seq := p.derive(current, position, l)
+				addChild(l, definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindChoice:
+			for _, l := range rule.leaves {
+				if next, seq := p.derive(current, start, l); next.ok {
+					addChild(l, definition.Segment{Start: start, End: start + next.advance}, seq)
+					break
+				}
+			}
+		}
+	}
+	return &rootNode
+}
+
+func transform(node *parser.ParsingNode) []*parser.ParsingNode {
+	name, ok := mapping[node.Atom.Symbol]
+	symbol, hidden := definition.AnalyzeSymbolName(name)
+	shape := nodeShapes[name]
+	if !ok || hidden || shape.inline {
+		nodes := make([]*parser.ParsingNode, 0, len(node.Children))
+		for _, child := range node.Children {
+			nodes = append(nodes, transform(child)...)
+		}
+		return nodes
+	}
+	if shape.drop {
+		return nil
+	}
+	atom := node.Atom
+	atom.Symbol = symbol
+	if shape.rename != "" {
+		atom.Symbol = shape.rename
+	}
+	next := parser.ParsingNode{Atom: atom, Segment: node.Segment}
+	if shape.token {
+		return []*parser.ParsingNode{&next}
+	}
+	for _, child := range node.Children {
+		for _, transformed := range transform(child) {
+			if shape.flatten && transformed.Atom.Symbol == next.Atom.Symbol {
+				next.Children = append(next.Children, transformed.Children...)
+				continue
+			}
+			next.Children = append(next.Children, transformed)
+		}
+	}
+	if shape.collapse && len(next.Children) == 1 {
+		return next.Children
+	}
+	return []*parser.ParsingNode{&next}
+}
+`))
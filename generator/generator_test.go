@@ -0,0 +1,156 @@
+package generator
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/extension"
+	"github.com/sivukhin/gopeg/generator/internal/generated"
+	"github.com/sivukhin/gopeg/generator/internal/generated/annotated"
+	"github.com/sivukhin/gopeg/generator/internal/generated/arithmetic"
+	"github.com/sivukhin/gopeg/generator/internal/generated/asmtokenizer"
+	"github.com/sivukhin/gopeg/generator/internal/generated/ctokenizer"
+	"github.com/sivukhin/gopeg/generator/internal/generated/gotokenizer"
+	"github.com/sivukhin/gopeg/generator/internal/generated/peggrammar"
+	"github.com/sivukhin/gopeg/generator/internal/generated/pegtokenizer"
+	"github.com/sivukhin/gopeg/generator/internal/generated/pythontokenizer"
+	"github.com/sivukhin/gopeg/generator/internal/generated/rusttokenizer"
+	"github.com/sivukhin/gopeg/generator/internal/generated/shelltokenizer"
+	"github.com/sivukhin/gopeg/generator/internal/generated/zigtokenizer"
+	"github.com/sivukhin/gopeg/highlight"
+	"github.com/sivukhin/gopeg/parser"
+)
+
+func TestGeneratedUpToDate(t *testing.T) {
+	for _, grammar := range generated.Grammars() {
+		t.Run(grammar.Package, func(t *testing.T) {
+			opts := []Option{WithPackage(grammar.Package), WithRoot(grammar.Root)}
+			if grammar.UTF8 {
+				opts = append(opts, WithUTF8())
+			}
+			source, err := Generate(grammar.Rules, opts...)
+			require.Nil(t, err)
+			checkedIn, err := os.ReadFile(filepath.Join("internal", "generated", grammar.Package, "parser.go"))
+			require.Nil(t, err)
+			require.Equal(t, string(checkedIn), string(source), "run go generate ./generator/...")
+		})
+	}
+}
+
+func TestGenerateErrors(t *testing.T) {
+	_, err := Generate(nil)
+	require.NotNil(t, err)
+	_, err = Generate(generated.ArithmeticRules, WithRoot("Unknown"))
+	require.NotNil(t, err)
+	_, err = Generate(definition.Rules{definition.NewRule("A", definition.NewSymbol("B"))})
+	require.NotNil(t, err)
+}
+
+func TestGenerateUTF8(t *testing.T) {
+	rules := definition.Rules{definition.NewRule("Text", definition.NewRepetition(definition.NewDot()))}
+	source, err := Generate(rules)
+	require.Nil(t, err)
+	require.NotContains(t, string(source), `"unicode/utf8"`)
+	source, err = Generate(rules, WithUTF8())
+	require.Nil(t, err)
+	require.Contains(t, string(source), `"unicode/utf8"`)
+	require.Contains(t, string(source), "utf8.DecodeRune")
+}
+
+func requireSameTree(t *testing.T, expected, actual *parser.ParsingNode) {
+	require.Equal(t, expected.Atom.Symbol, actual.Atom.Symbol)
+	require.Equal(t, expected.Atom.Attributes, actual.Atom.Attributes)
+	require.Equal(t, expected.Segment, actual.Segment)
+	require.Equal(t, len(expected.Children), len(actual.Children), "children of %v at %v", expected.Atom.Symbol, expected.Segment)
+	for i := range expected.Children {
+		requireSameTree(t, expected.Children[i], actual.Children[i])
+	}
+}
+
+func TestArithmetic(t *testing.T) {
+	for _, text := range []string{"1", "1+2*3-4", "(1+2)*(3-4)/5", "1+(2*(3+4))*5-6", "1+"} {
+		t.Run(text, func(t *testing.T) {
+			expected, err := parser.ParseText(generated.ArithmeticRules, "Expr", []byte(text))
+			require.Nil(t, err)
+			actual, err := arithmetic.Parse([]byte(text))
+			require.Nil(t, err)
+			requireSameTree(t, expected, actual)
+		})
+	}
+	_, err := arithmetic.ParseFull([]byte("1+"))
+	require.ErrorIs(t, err, parser.TextNotMatchErr)
+	_, err = arithmetic.Parse([]byte("+"))
+	require.ErrorIs(t, err, parser.TextNotMatchErr)
+}
+
+func TestAnnotated(t *testing.T) {
+	text := []byte("1,23#x,(4,5#y)")
+	expected, err := parser.ParseText(generated.AnnotatedRules, "List", text, parser.WithMatchMode(parser.FullMatch))
+	require.Nil(t, err)
+	actual, err := annotated.ParseFull(text)
+	require.Nil(t, err)
+	requireSameTree(t, expected, actual)
+	require.Equal(t, "Num", actual.Children[1].Atom.Symbol)
+	require.Equal(t, "Tuple", actual.Children[2].Atom.Symbol)
+}
+
+func TestPegGrammar(t *testing.T) {
+	for _, name := range []string{"peg-grammar.peg", "peg-tokenizer.peg", "test.peg"} {
+		t.Run(name, func(t *testing.T) {
+			text, err := os.ReadFile(filepath.Join("..", "extension", name))
+			require.Nil(t, err)
+			expectedTokens, err := parser.ParseText(extension.PegTokenizerRules, extension.PegText, text, parser.WithMatchMode(parser.FullMatch))
+			require.Nil(t, err)
+			actualTokens, err := pegtokenizer.ParseFull(text)
+			require.Nil(t, err)
+			requireSameTree(t, expectedTokens, actualTokens)
+
+			atoms := make([]definition.Atom, 0, len(actualTokens.Children))
+			for _, child := range actualTokens.Children {
+				atoms = append(atoms, child.Atom)
+			}
+			expected, err := parser.ParseAtoms(extension.PegGrammarRules, extension.PegDefinitions, atoms, parser.WithMatchMode(parser.FullMatch))
+			require.Nil(t, err)
+			actual, err := peggrammar.ParseFull(atoms)
+			require.Nil(t, err)
+			requireSameTree(t, expected, actual)
+		})
+	}
+}
+
+func TestTokenizers(t *testing.T) {
+	goSource, err := os.ReadFile("generator.go")
+	require.Nil(t, err)
+	tokenizers := []struct {
+		name   string
+		rules  definition.Rules
+		parse  func(data []byte) (*parser.ParsingNode, error)
+		source string
+	}{
+		{name: "python", rules: highlight.PythonTokenizerRules, parse: pythontokenizer.ParseFull, source: "def square(value):\n    result = value**2 # dummy comment\n    return result"},
+		{name: "c", rules: highlight.CTokenizerRules, parse: ctokenizer.ParseFull, source: "typedef struct {\n    unsigned value;       /**comment */\n} parameters;"},
+		{name: "rust", rules: highlight.RustTokenizerRules, parse: rusttokenizer.ParseFull, source: `fn main() { println!("Hello, world!"); }`},
+		{name: "shell", rules: highlight.ShellTokenizerRules, parse: shelltokenizer.ParseFull, source: "$> echo hi\n# comment\n123"},
+		{name: "go", rules: highlight.GoTokenizerRules, parse: gotokenizer.ParseFull, source: string(goSource)},
+		{name: "zig", rules: highlight.ZigTokenizerRules, parse: zigtokenizer.ParseFull, source: "const std = @import(\"std\");\n\npub fn main() void {\n    // comment\n    std.debug.print(\"{}\\n\", .{42});\n}"},
+		{name: "asm", rules: highlight.AsmTokenizerRules, parse: asmtokenizer.ParseFull, source: "TEXT     main.Check(SB), NOSPLIT|NOFRAME|ABIInternal, $0-0\nFUNCDATA $0, gclocals·g2BeySu+wFnoycgXfElmcg==(SB)\nXORL     AX, AX # comment\nRET"},
+	}
+	for _, tokenizer := range tokenizers {
+		for _, text := range []string{tokenizer.source, "", "x \xff y\n# \xc3 // \xfe"} {
+			t.Run(tokenizer.name, func(t *testing.T) {
+				expected, expectedErr := parser.ParseText(tokenizer.rules, "Source", []byte(text), parser.WithMatchMode(parser.FullMatch), parser.WithUTF8())
+				actual, actualErr := tokenizer.parse([]byte(text))
+				require.Equal(t, errors.Is(expectedErr, parser.TextNotMatchErr), errors.Is(actualErr, parser.TextNotMatchErr), "errors: %v, %v", expectedErr, actualErr)
+				require.Equal(t, expectedErr == nil, actualErr == nil, "errors: %v, %v", expectedErr, actualErr)
+				if expectedErr == nil {
+					requireSameTree(t, expected, actual)
+				}
+			})
+		}
+	}
+}
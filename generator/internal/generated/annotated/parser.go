@@ -0,0 +1,485 @@
+// Code generated by gopeg generate; DO NOT EDIT.
+
+package annotated
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/parser"
+)
+
+type (
+	cell struct{ position, rule int }
+	step struct {
+		ok      bool
+		advance int
+	}
+	version struct {
+		seq  int
+		step step
+	}
+	leaf struct {
+		rule       int
+		terminal   int
+		name       string
+		attributes map[string][]byte
+	}
+	shape struct {
+		kind   int
+		leaves []leaf
+	}
+	frame struct {
+		node *parser.ParsingNode
+		rule int
+		seq  int
+	}
+	nodeShape struct {
+		inline   bool
+		flatten  bool
+		collapse bool
+		token    bool
+		drop     bool
+		rename   string
+	}
+	state struct {
+		data     []byte
+		memo     map[cell]step
+		seq      int
+		versions map[cell][]version
+	}
+)
+
+const (
+	kindTerminal = iota
+	kindSymbol
+	kindJunction
+	kindChoice
+	kindKleene
+	kindNegation
+)
+
+const root = 5
+
+var (
+	pattern1 = regexp.MustCompile("^#[a-z]*")
+	token2   = []byte(",")
+	token3   = []byte("(")
+	token4   = []byte(")")
+	pattern5 = regexp.MustCompile("^[0-9]+")
+)
+
+var (
+	names      = []string{"Value#1", "Comment#0", "Inner#0", "List#2", "List#1", "List#0", "Item#0", "Item#1", "Value#0", "Number#0"}
+	recursive  = []bool{false, false, false, false, false, false, false, false, false, false}
+	component  = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9}
+	components = [][]int{{0}, {1}, {2}, {3}, {4}, {5}, {6}, {7}, {8}, {9}}
+	shapes     = []shape{
+		{kind: kindChoice, leaves: []leaf{{rule: 1, name: "Comment#0", attributes: nil}, {rule: -1, terminal: 0}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 1}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 5, name: "List#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 4, name: "List#1", attributes: nil}, {rule: -1, terminal: 0}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 2}, {rule: 5, name: "List#0", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 6, name: "Item#0", attributes: nil}, {rule: 3, name: "List#2", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 8, name: "Value#0", attributes: nil}, {rule: 7, name: "Item#1", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 3}, {rule: 2, name: "Inner#0", attributes: nil}, {rule: -1, terminal: 4}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 9, name: "Number#0", attributes: nil}, {rule: 0, name: "Value#1", attributes: nil}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 5}}},
+	}
+	mapping = map[string]string{
+		"Comment#0": "Comment",
+		"Inner#0":   "Inner",
+		"Item#0":    "Item",
+		"List#0":    "List",
+		"Number#0":  "Number",
+		"Value#0":   "Value",
+	}
+	nodeShapes = map[string]nodeShape{
+		"List":    {flatten: true},
+		"Item":    {collapse: true},
+		"Inner":   {rename: "Tuple"},
+		"Value":   {inline: true},
+		"Number":  {token: true, rename: "Num"},
+		"Comment": {drop: true},
+	}
+)
+
+// Parse matches the longest prefix of the data with List rule
+func Parse(data []byte) (*parser.ParsingNode, error) { return parse(data, false) }
+
+// ParseFull matches the whole data with List rule
+func ParseFull(data []byte) (*parser.ParsingNode, error) { return parse(data, true) }
+
+func parse(data []byte, full bool) (*parser.ParsingNode, error) {
+	p := &state{data: data, memo: make(map[cell]step), versions: make(map[cell][]version)}
+	rootStep := p.at(0, root)
+	if !rootStep.ok {
+		return nil, fmt.Errorf("%w: rule List doesn't match", parser.TextNotMatchErr)
+	}
+	if full && rootStep.advance != len(data) {
+		return nil, fmt.Errorf("%w: rule List matched only first %v elements out of %v", parser.TextNotMatchErr, rootStep.advance, len(data))
+	}
+	nodes := transform(p.build(rootStep))
+	if len(nodes) != 1 {
+		return nil, fmt.Errorf("tree with multiple root was formed")
+	}
+	return nodes[0], nil
+}
+
+func (p *state) at(i, s int) step {
+	key := cell{position: i, rule: s}
+	if result, ok := p.memo[key]; ok {
+		return result
+	}
+	if !recursive[s] {
+		result := p.evaluate(i, s)
+		p.memo[key] = result
+		return result
+	}
+	group := components[component[s]]
+	for _, r := range group {
+		p.memo[cell{position: i, rule: r}] = step{}
+		p.versions[cell{position: i, rule: r}] = []version{{seq: -1}}
+	}
+	for grown := true; grown; {
+		grown = false
+		for _, r := range group {
+			p.seq++
+			seq := p.seq
+			next := p.evaluate(i, r)
+			if current := p.memo[cell{position: i, rule: r}]; next.ok && (!current.ok || next.advance > current.advance) {
+				p.memo[cell{position: i, rule: r}] = next
+				p.versions[cell{position: i, rule: r}] = append(p.versions[cell{position: i, rule: r}], version{seq: seq, step: next})
+				grown = true
+			}
+		}
+	}
+	return p.memo[key]
+}
+
+func (p *state) versionBefore(i, s, seq int) (step, int) {
+	versions := p.versions[cell{position: i, rule: s}]
+	for k := len(versions) - 1; k >= 0; k-- {
+		if versions[k].seq < seq {
+			return versions[k].step, versions[k].seq
+		}
+	}
+	panic(fmt.Errorf("left-recursive cell %v has no versions before %v", cell{position: i, rule: s}, seq))
+}
+
+func (p *state) evaluate(i, s int) step {
+	switch s {
+	case 0:
+		return p.rule0(i)
+	case 1:
+		return p.rule1(i)
+	case 2:
+		return p.rule2(i)
+	case 3:
+		return p.rule3(i)
+	case 4:
+		return p.rule4(i)
+	case 5:
+		return p.rule5(i)
+	case 6:
+		return p.rule6(i)
+	case 7:
+		return p.rule7(i)
+	case 8:
+		return p.rule8(i)
+	case 9:
+		return p.rule9(i)
+	}
+	panic(fmt.Errorf("unexpected rule: %v", s))
+}
+
+func (p *state) terminal(i, k int) step {
+	switch k {
+	case 0:
+		return p.terminal0(i)
+	case 1:
+		return p.terminal1(i)
+	case 2:
+		return p.terminal2(i)
+	case 3:
+		return p.terminal3(i)
+	case 4:
+		return p.terminal4(i)
+	case 5:
+		return p.terminal5(i)
+	}
+	panic(fmt.Errorf("unexpected terminal: %v", k))
+}
+
+// rule0 evaluates Value#1: Comment#0 / @empty
+func (p *state) rule0(i int) step {
+	if next := p.at(i, 1); next.ok {
+		return next
+	}
+	if next := p.terminal0(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule1 evaluates Comment#0: =~"^#[a-z]*"
+func (p *state) rule1(i int) step {
+	return p.terminal1(i)
+}
+
+// rule2 evaluates Inner#0: List#0
+func (p *state) rule2(i int) step {
+	return p.at(i, 5)
+}
+
+// rule3 evaluates List#2: List#1 / @empty
+func (p *state) rule3(i int) step {
+	if next := p.at(i, 4); next.ok {
+		return next
+	}
+	if next := p.terminal0(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule4 evaluates List#1: "," List#0
+func (p *state) rule4(i int) step {
+	current := i
+	if next := p.terminal2(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 5); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule5 evaluates List#0: Item#0 List#2
+func (p *state) rule5(i int) step {
+	current := i
+	if next := p.at(current, 6); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 3); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule6 evaluates Item#0: Value#0 / Item#1
+func (p *state) rule6(i int) step {
+	if next := p.at(i, 8); next.ok {
+		return next
+	}
+	if next := p.at(i, 7); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule7 evaluates Item#1: "(" Inner#0 ")"
+func (p *state) rule7(i int) step {
+	current := i
+	if next := p.terminal3(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 2); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal4(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule8 evaluates Value#0: Number#0 Value#1
+func (p *state) rule8(i int) step {
+	current := i
+	if next := p.at(current, 9); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 0); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule9 evaluates Number#0: =~"^[0-9]+"
+func (p *state) rule9(i int) step {
+	return p.terminal5(i)
+}
+
+// terminal0 matches @empty
+func (p *state) terminal0(i int) step {
+	return step{ok: true}
+}
+
+// terminal1 matches =~"^#[a-z]*"
+func (p *state) terminal1(i int) step {
+	location := pattern1.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal2 matches ","
+func (p *state) terminal2(i int) step {
+	if bytes.HasPrefix(p.data[i:], token2) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal3 matches "("
+func (p *state) terminal3(i int) step {
+	if bytes.HasPrefix(p.data[i:], token3) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal4 matches ")"
+func (p *state) terminal4(i int) step {
+	if bytes.HasPrefix(p.data[i:], token4) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal5 matches =~"^[0-9]+"
+func (p *state) terminal5(i int) step {
+	location := pattern5.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+func (p *state) derive(parent frame, i int, l leaf) (step, int) {
+	if l.rule < 0 {
+		return p.terminal(i, l.terminal), 0
+	}
+	if !recursive[l.rule] {
+		return p.at(i, l.rule), 0
+	}
+	limit := math.MaxInt
+	if i == parent.node.Segment.Start && component[l.rule] == component[parent.rule] {
+		limit = parent.seq
+	}
+	p.at(i, l.rule)
+	return p.versionBefore(i, l.rule, limit)
+}
+
+func (p *state) build(rootStep step) *parser.ParsingNode {
+	rootNode := parser.NewParsingNode[byte](names[root], nil, p.data, definition.Segment{Start: 0, End: rootStep.advance})
+	rootFrame := frame{node: &rootNode, rule: root, seq: math.MaxInt}
+	if recursive[root] {
+		_, rootFrame.seq = p.versionBefore(0, root, math.MaxInt)
+	}
+	derivation := []frame{rootFrame}
+	for k := 0; k < len(derivation); k++ {
+		current := derivation[k]
+		addChild := func(l leaf, segment definition.Segment, seq int) {
+			if l.rule < 0 {
+				return
+			}
+			next := parser.NewParsingNode[byte](l.name, l.attributes, p.data, segment)
+			current.node.Children = append(current.node.Children, &next)
+			derivation = append(derivation, frame{node: &next, rule: l.rule, seq: seq})
+		}
+		start := current.node.Segment.Start
+		rule := shapes[current.rule]
+		switch rule.kind {
+		case kindSymbol:
+			next, seq := p.derive(current, start, rule.leaves[0])
+			if next.ok {
+				addChild(rule.leaves[0], current.node.Segment, seq)
+			}
+		case kindKleene:
+			for position := start; ; {
+				next, seq := p.derive(current, position, rule.leaves[0])
+				if !next.ok || next.advance == 0 {
+					break
+				}
+				addChild(rule.leaves[0], definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindJunction:
+			position := start
+			for _, l := range rule.leaves {
+				next, seq := p.derive(current, position, l)
+				addChild(l, definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindChoice:
+			for _, l := range rule.leaves {
+				if next, seq := p.derive(current, start, l); next.ok {
+					addChild(l, definition.Segment{Start: start, End: start + next.advance}, seq)
+					break
+				}
+			}
+		}
+	}
+	return &rootNode
+}
+
+func transform(node *parser.ParsingNode) []*parser.ParsingNode {
+	name, ok := mapping[node.Atom.Symbol]
+	symbol, hidden := definition.AnalyzeSymbolName(name)
+	shape := nodeShapes[name]
+	if !ok || hidden || shape.inline {
+		nodes := make([]*parser.ParsingNode, 0, len(node.Children))
+		for _, child := range node.Children {
+			nodes = append(nodes, transform(child)...)
+		}
+		return nodes
+	}
+	if shape.drop {
+		return nil
+	}
+	atom := node.Atom
+	atom.Symbol = symbol
+	if shape.rename != "" {
+		atom.Symbol = shape.rename
+	}
+	next := parser.ParsingNode{Atom: atom, Segment: node.Segment}
+	if shape.token {
+		return []*parser.ParsingNode{&next}
+	}
+	for _, child := range node.Children {
+		for _, transformed := range transform(child) {
+			if shape.flatten && transformed.Atom.Symbol == next.Atom.Symbol {
+				next.Children = append(next.Children, transformed.Children...)
+				continue
+			}
+			next.Children = append(next.Children, transformed)
+		}
+	}
+	if shape.collapse && len(next.Children) == 1 {
+		return next.Children
+	}
+	return []*parser.ParsingNode{&next}
+}
@@ -0,0 +1,436 @@
+// Code generated by gopeg generate; DO NOT EDIT.
+
+package arithmetic
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/parser"
+)
+
+type (
+	cell struct{ position, rule int }
+	step struct {
+		ok      bool
+		advance int
+	}
+	version struct {
+		seq  int
+		step step
+	}
+	leaf struct {
+		rule       int
+		terminal   int
+		name       string
+		attributes map[string][]byte
+	}
+	shape struct {
+		kind   int
+		leaves []leaf
+	}
+	frame struct {
+		node *parser.ParsingNode
+		rule int
+		seq  int
+	}
+	nodeShape struct {
+		inline   bool
+		flatten  bool
+		collapse bool
+		token    bool
+		drop     bool
+		rename   string
+	}
+	state struct {
+		data     []byte
+		memo     map[cell]step
+		seq      int
+		versions map[cell][]version
+	}
+)
+
+const (
+	kindTerminal = iota
+	kindSymbol
+	kindJunction
+	kindChoice
+	kindKleene
+	kindNegation
+)
+
+const root = 0
+
+var (
+	pattern0 = regexp.MustCompile("^[+-]")
+	pattern1 = regexp.MustCompile("^[*/]")
+	pattern2 = regexp.MustCompile("^[0-9]+")
+	token3   = []byte("(")
+	token4   = []byte(")")
+)
+
+var (
+	names      = []string{"Expr#0", "Expr#1", "Term#0", "Term#1", "Atom#0", "Atom#1"}
+	recursive  = []bool{true, true, true, true, false, false}
+	component  = []int{0, 0, 1, 1, 2, 3}
+	components = [][]int{{0, 1}, {2, 3}, {4}, {5}}
+	shapes     = []shape{
+		{kind: kindChoice, leaves: []leaf{{rule: 1, name: "Expr#1", attributes: nil}, {rule: 2, name: "Term#0", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 0, name: "Expr#0", attributes: nil}, {rule: -1, terminal: 0}, {rule: 2, name: "Term#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 3, name: "Term#1", attributes: nil}, {rule: 4, name: "Atom#0", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 2, name: "Term#0", attributes: nil}, {rule: -1, terminal: 1}, {rule: 4, name: "Atom#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 2}, {rule: 5, name: "Atom#1", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 3}, {rule: 0, name: "Expr#0", attributes: nil}, {rule: -1, terminal: 4}}},
+	}
+	mapping = map[string]string{
+		"Atom#0": "Atom",
+		"Expr#0": "Expr",
+		"Term#0": "Term",
+	}
+	nodeShapes = map[string]nodeShape{}
+)
+
+// Parse matches the longest prefix of the data with Expr rule
+func Parse(data []byte) (*parser.ParsingNode, error) { return parse(data, false) }
+
+// ParseFull matches the whole data with Expr rule
+func ParseFull(data []byte) (*parser.ParsingNode, error) { return parse(data, true) }
+
+func parse(data []byte, full bool) (*parser.ParsingNode, error) {
+	p := &state{data: data, memo: make(map[cell]step), versions: make(map[cell][]version)}
+	rootStep := p.at(0, root)
+	if !rootStep.ok {
+		return nil, fmt.Errorf("%w: rule Expr doesn't match", parser.TextNotMatchErr)
+	}
+	if full && rootStep.advance != len(data) {
+		return nil, fmt.Errorf("%w: rule Expr matched only first %v elements out of %v", parser.TextNotMatchErr, rootStep.advance, len(data))
+	}
+	nodes := transform(p.build(rootStep))
+	if len(nodes) != 1 {
+		return nil, fmt.Errorf("tree with multiple root was formed")
+	}
+	return nodes[0], nil
+}
+
+func (p *state) at(i, s int) step {
+	key := cell{position: i, rule: s}
+	if result, ok := p.memo[key]; ok {
+		return result
+	}
+	if !recursive[s] {
+		result := p.evaluate(i, s)
+		p.memo[key] = result
+		return result
+	}
+	group := components[component[s]]
+	for _, r := range group {
+		p.memo[cell{position: i, rule: r}] = step{}
+		p.versions[cell{position: i, rule: r}] = []version{{seq: -1}}
+	}
+	for grown := true; grown; {
+		grown = false
+		for _, r := range group {
+			p.seq++
+			seq := p.seq
+			next := p.evaluate(i, r)
+			if current := p.memo[cell{position: i, rule: r}]; next.ok && (!current.ok || next.advance > current.advance) {
+				p.memo[cell{position: i, rule: r}] = next
+				p.versions[cell{position: i, rule: r}] = append(p.versions[cell{position: i, rule: r}], version{seq: seq, step: next})
+				grown = true
+			}
+		}
+	}
+	return p.memo[key]
+}
+
+func (p *state) versionBefore(i, s, seq int) (step, int) {
+	versions := p.versions[cell{position: i, rule: s}]
+	for k := len(versions) - 1; k >= 0; k-- {
+		if versions[k].seq < seq {
+			return versions[k].step, versions[k].seq
+		}
+	}
+	panic(fmt.Errorf("left-recursive cell %v has no versions before %v", cell{position: i, rule: s}, seq))
+}
+
+func (p *state) evaluate(i, s int) step {
+	switch s {
+	case 0:
+		return p.rule0(i)
+	case 1:
+		return p.rule1(i)
+	case 2:
+		return p.rule2(i)
+	case 3:
+		return p.rule3(i)
+	case 4:
+		return p.rule4(i)
+	case 5:
+		return p.rule5(i)
+	}
+	panic(fmt.Errorf("unexpected rule: %v", s))
+}
+
+func (p *state) terminal(i, k int) step {
+	switch k {
+	case 0:
+		return p.terminal0(i)
+	case 1:
+		return p.terminal1(i)
+	case 2:
+		return p.terminal2(i)
+	case 3:
+		return p.terminal3(i)
+	case 4:
+		return p.terminal4(i)
+	}
+	panic(fmt.Errorf("unexpected terminal: %v", k))
+}
+
+// rule0 evaluates Expr#0: Expr#1 / Term#0
+func (p *state) rule0(i int) step {
+	if next := p.at(i, 1); next.ok {
+		return next
+	}
+	if next := p.at(i, 2); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule1 evaluates Expr#1: Expr#0 =~"^[+-]" Term#0
+func (p *state) rule1(i int) step {
+	current := i
+	if next := p.at(current, 0); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal0(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 2); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule2 evaluates Term#0: Term#1 / Atom#0
+func (p *state) rule2(i int) step {
+	if next := p.at(i, 3); next.ok {
+		return next
+	}
+	if next := p.at(i, 4); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule3 evaluates Term#1: Term#0 =~"^[*/]" Atom#0
+func (p *state) rule3(i int) step {
+	current := i
+	if next := p.at(current, 2); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal1(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 4); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule4 evaluates Atom#0: =~"^[0-9]+" / Atom#1
+func (p *state) rule4(i int) step {
+	if next := p.terminal2(i); next.ok {
+		return next
+	}
+	if next := p.at(i, 5); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule5 evaluates Atom#1: "(" Expr#0 ")"
+func (p *state) rule5(i int) step {
+	current := i
+	if next := p.terminal3(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 0); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal4(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// terminal0 matches =~"^[+-]"
+func (p *state) terminal0(i int) step {
+	location := pattern0.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal1 matches =~"^[*/]"
+func (p *state) terminal1(i int) step {
+	location := pattern1.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal2 matches =~"^[0-9]+"
+func (p *state) terminal2(i int) step {
+	location := pattern2.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal3 matches "("
+func (p *state) terminal3(i int) step {
+	if bytes.HasPrefix(p.data[i:], token3) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal4 matches ")"
+func (p *state) terminal4(i int) step {
+	if bytes.HasPrefix(p.data[i:], token4) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+func (p *state) derive(parent frame, i int, l leaf) (step, int) {
+	if l.rule < 0 {
+		return p.terminal(i, l.terminal), 0
+	}
+	if !recursive[l.rule] {
+		return p.at(i, l.rule), 0
+	}
+	limit := math.MaxInt
+	if i == parent.node.Segment.Start && component[l.rule] == component[parent.rule] {
+		limit = parent.seq
+	}
+	p.at(i, l.rule)
+	return p.versionBefore(i, l.rule, limit)
+}
+
+func (p *state) build(rootStep step) *parser.ParsingNode {
+	rootNode := parser.NewParsingNode[byte](names[root], nil, p.data, definition.Segment{Start: 0, End: rootStep.advance})
+	rootFrame := frame{node: &rootNode, rule: root, seq: math.MaxInt}
+	if recursive[root] {
+		_, rootFrame.seq = p.versionBefore(0, root, math.MaxInt)
+	}
+	derivation := []frame{rootFrame}
+	for k := 0; k < len(derivation); k++ {
+		current := derivation[k]
+		addChild := func(l leaf, segment definition.Segment, seq int) {
+			if l.rule < 0 {
+				return
+			}
+			next := parser.NewParsingNode[byte](l.name, l.attributes, p.data, segment)
+			current.node.Children = append(current.node.Children, &next)
+			derivation = append(derivation, frame{node: &next, rule: l.rule, seq: seq})
+		}
+		start := current.node.Segment.Start
+		rule := shapes[current.rule]
+		switch rule.kind {
+		case kindSymbol:
+			next, seq := p.derive(current, start, rule.leaves[0])
+			if next.ok {
+				addChild(rule.leaves[0], current.node.Segment, seq)
+			}
+		case kindKleene:
+			for position := start; ; {
+				next, seq := p.derive(current, position, rule.leaves[0])
+				if !next.ok || next.advance == 0 {
+					break
+				}
+				addChild(rule.leaves[0], definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindJunction:
+			position := start
+			for _, l := range rule.leaves {
+				next, seq := p.derive(current, position, l)
+				addChild(l, definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindChoice:
+			for _, l := range rule.leaves {
+				if next, seq := p.derive(current, start, l); next.ok {
+					addChild(l, definition.Segment{Start: start, End: start + next.advance}, seq)
+					break
+				}
+			}
+		}
+	}
+	return &rootNode
+}
+
+func transform(node *parser.ParsingNode) []*parser.ParsingNode {
+	name, ok := mapping[node.Atom.Symbol]
+	symbol, hidden := definition.AnalyzeSymbolName(name)
+	shape := nodeShapes[name]
+	if !ok || hidden || shape.inline {
+		nodes := make([]*parser.ParsingNode, 0, len(node.Children))
+		for _, child := range node.Children {
+			nodes = append(nodes, transform(child)...)
+		}
+		return nodes
+	}
+	if shape.drop {
+		return nil
+	}
+	atom := node.Atom
+	atom.Symbol = symbol
+	if shape.rename != "" {
+		atom.Symbol = shape.rename
+	}
+	next := parser.ParsingNode{Atom: atom, Segment: node.Segment}
+	if shape.token {
+		return []*parser.ParsingNode{&next}
+	}
+	for _, child := range node.Children {
+		for _, transformed := range transform(child) {
+			if shape.flatten && transformed.Atom.Symbol == next.Atom.Symbol {
+				next.Children = append(next.Children, transformed.Children...)
+				continue
+			}
+			next.Children = append(next.Children, transformed)
+		}
+	}
+	if shape.collapse && len(next.Children) == 1 {
+		return next.Children
+	}
+	return []*parser.ParsingNode{&next}
+}
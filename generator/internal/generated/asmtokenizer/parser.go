@@ -0,0 +1,1245 @@
+// Code generated by gopeg generate; DO NOT EDIT.
+
+package asmtokenizer
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/parser"
+)
+
+type (
+	cell struct{ position, rule int }
+	step struct {
+		ok      bool
+		advance int
+	}
+	version struct {
+		seq  int
+		step step
+	}
+	leaf struct {
+		rule       int
+		terminal   int
+		name       string
+		attributes map[string][]byte
+	}
+	shape struct {
+		kind   int
+		leaves []leaf
+	}
+	frame struct {
+		node *parser.ParsingNode
+		rule int
+		seq  int
+	}
+	nodeShape struct {
+		inline   bool
+		flatten  bool
+		collapse bool
+		token    bool
+		drop     bool
+		rename   string
+	}
+	state struct {
+		data     []byte
+		memo     map[cell]step
+		seq      int
+		versions map[cell][]version
+	}
+)
+
+const (
+	kindTerminal = iota
+	kindSymbol
+	kindJunction
+	kindChoice
+	kindKleene
+	kindNegation
+)
+
+const root = 36
+
+var (
+	token0    = []byte("*/")
+	token1    = []byte("//")
+	token2    = []byte("/*")
+	pattern3  = regexp.MustCompile("^(\\+|-)?\\d+(.\\d*)?")
+	token4    = []byte("#")
+	pattern5  = regexp.MustCompile("^\\$?(\\+|-)?\\d+(\\.\\d*)?")
+	pattern6  = regexp.MustCompile("^[a-zA-Z][a-zA-Z0-9_]*")
+	token7    = []byte(" ")
+	token8    = []byte("\t")
+	token9    = []byte("\n")
+	pattern12 = regexp.MustCompile("^[A-Z][A-Z0-9]+")
+	pattern13 = regexp.MustCompile("^[a-z][a-z0-9]+")
+	token14   = []byte("# ")
+)
+
+var (
+	names      = []string{"#c.SlashComment#9", "#c.SlashComment#8", "#c.SlashComment#7", "#c.SlashComment#6", "#c.SlashComment#5", "#c.SlashComment#3", "#c.SlashComment#2", "#c.SlashComment#1", "#c.SlashComment#0", "#c.SlashComment#10", "#c.SlashComment#4", "#c.Number#0", "#Instruction#5", "#Instruction#4", "#Instruction#2", "#Instruction#1", "#Comment#3", "#Comment#2", "#Comment#1", "#Sequence#5", "#Sequence#4", "#Sequence#3", "#Sequence#2", "None@90#9", "None@90#8", "None@90#7", "None@90#6", "None@90#5", "None@85#0", "None@79#0", "Token@65#0", "Token@51#0", "#Number#0", "None@47#0", "#c.Identifier#0", "None@25#0", "Source#0", "#Sequence#0", "None@103#0", "None@90#0", "None@90#4", "None@90#3", "None@90#2", "None@90#1", "#Space#0", "#c.EndOfLine#0", "#c.EndOfLine#1", "#c.Any#0", "#Sequence#6", "Token@30#0", "#Instruction#0", "#Instruction#6", "#Instruction#3", "#Sequence#1", "Token@12#0", "#Comment#0"}
+	recursive  = []bool{false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false}
+	component  = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32, 33, 34, 35, 36, 37, 38, 39, 40, 41, 42, 43, 44, 45, 46, 47, 48, 49, 50, 51, 52, 53, 54, 55}
+	components = [][]int{{0}, {1}, {2}, {3}, {4}, {5}, {6}, {7}, {8}, {9}, {10}, {11}, {12}, {13}, {14}, {15}, {16}, {17}, {18}, {19}, {20}, {21}, {22}, {23}, {24}, {25}, {26}, {27}, {28}, {29}, {30}, {31}, {32}, {33}, {34}, {35}, {36}, {37}, {38}, {39}, {40}, {41}, {42}, {43}, {44}, {45}, {46}, {47}, {48}, {49}, {50}, {51}, {52}, {53}, {54}, {55}}
+	shapes     = []shape{
+		{kind: kindNegation, leaves: []leaf{{rule: 1, name: "#c.SlashComment#8", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 45, name: "#c.EndOfLine#0", attributes: nil}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 3, name: "#c.SlashComment#6", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 4, name: "#c.SlashComment#5", attributes: nil}, {rule: 47, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 45, name: "#c.EndOfLine#0", attributes: nil}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 6, name: "#c.SlashComment#2", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 7, name: "#c.SlashComment#1", attributes: nil}, {rule: 47, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: -1, terminal: 0}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 10, name: "#c.SlashComment#4", attributes: nil}, {rule: 9, name: "#c.SlashComment#10", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 1}, {rule: 2, name: "#c.SlashComment#7", attributes: nil}, {rule: 0, name: "#c.SlashComment#9", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 2}, {rule: 5, name: "#c.SlashComment#3", attributes: nil}, {rule: -1, terminal: 0}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 3}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 13, name: "#Instruction#4", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 44, name: "#Space#0", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 15, name: "#Instruction#1", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 44, name: "#Space#0", attributes: nil}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 17, name: "#Comment#2", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 18, name: "#Comment#1", attributes: nil}, {rule: 47, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 45, name: "#c.EndOfLine#0", attributes: nil}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 20, name: "#Sequence#4", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 22, name: "#Sequence#2", attributes: nil}, {rule: 21, name: "#Sequence#3", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 33, name: "None@47#0", attributes: nil}, {rule: 31, name: "Token@51#0", attributes: map[string][]byte{"class": []byte("number"), "tag": []byte("span")}}, {rule: 30, name: "Token@65#0", attributes: map[string][]byte{"class": []byte("comment"), "tag": []byte("span")}}, {rule: 29, name: "None@79#0", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 45, name: "#c.EndOfLine#0", attributes: nil}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 24, name: "None@90#8", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 27, name: "None@90#5", attributes: nil}, {rule: 26, name: "None@90#6", attributes: nil}, {rule: 25, name: "None@90#7", attributes: nil}, {rule: 47, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: -1, terminal: 4}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 45, name: "#c.EndOfLine#0", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 44, name: "#Space#0", attributes: nil}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 45, name: "#c.EndOfLine#0", attributes: nil}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 47, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 55, name: "#Comment#0", attributes: nil}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 32, name: "#Number#0", attributes: nil}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 5}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 34, name: "#c.Identifier#0", attributes: nil}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 6}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 45, name: "#c.EndOfLine#0", attributes: nil}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 37, name: "#Sequence#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 53, name: "#Sequence#1", attributes: nil}, {rule: 48, name: "#Sequence#6", attributes: nil}, {rule: 39, name: "None@90#0", attributes: nil}, {rule: 38, name: "None@103#0", attributes: nil}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 47, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 40, name: "None@90#4", attributes: nil}, {rule: 23, name: "None@90#9", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 43, name: "None@90#1", attributes: nil}, {rule: 42, name: "None@90#2", attributes: nil}, {rule: 41, name: "None@90#3", attributes: nil}, {rule: 47, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: -1, terminal: 4}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 45, name: "#c.EndOfLine#0", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 44, name: "#Space#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 7}, {rule: -1, terminal: 8}, {rule: 45, name: "#c.EndOfLine#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 9}, {rule: 46, name: "#c.EndOfLine#1", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 47, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 10}, {rule: -1, terminal: 11}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 49, name: "Token@30#0", attributes: map[string][]byte{"class": []byte("keyword"), "tag": []byte("span")}}, {rule: 19, name: "#Sequence#5", attributes: nil}, {rule: 28, name: "None@85#0", attributes: nil}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 50, name: "#Instruction#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 52, name: "#Instruction#3", attributes: nil}, {rule: 51, name: "#Instruction#6", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 12}, {rule: 12, name: "#Instruction#5", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 13}, {rule: 14, name: "#Instruction#2", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 54, name: "Token@12#0", attributes: map[string][]byte{"class": []byte("comment"), "tag": []byte("span")}}, {rule: 35, name: "None@25#0", attributes: nil}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 55, name: "#Comment#0", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 14}, {rule: 16, name: "#Comment#3", attributes: nil}}},
+	}
+	mapping = map[string]string{
+		"#Comment#0":        "#Comment",
+		"#Instruction#0":    "#Instruction",
+		"#Number#0":         "#Number",
+		"#Sequence#0":       "#Sequence",
+		"#Space#0":          "#Space",
+		"#c.Any#0":          "#c.Any",
+		"#c.EndOfLine#0":    "#c.EndOfLine",
+		"#c.Identifier#0":   "#c.Identifier",
+		"#c.Number#0":       "#c.Number",
+		"#c.SlashComment#0": "#c.SlashComment",
+		"None@103#0":        "None@103",
+		"None@25#0":         "None@25",
+		"None@47#0":         "None@47",
+		"None@79#0":         "None@79",
+		"None@85#0":         "None@85",
+		"None@90#0":         "None@90",
+		"Source#0":          "Source",
+		"Token@12#0":        "Token@12",
+		"Token@30#0":        "Token@30",
+		"Token@51#0":        "Token@51",
+		"Token@65#0":        "Token@65",
+	}
+	nodeShapes = map[string]nodeShape{}
+)
+
+// Parse matches the longest prefix of the data with Source rule
+func Parse(data []byte) (*parser.ParsingNode, error) { return parse(data, false) }
+
+// ParseFull matches the whole data with Source rule
+func ParseFull(data []byte) (*parser.ParsingNode, error) { return parse(data, true) }
+
+func parse(data []byte, full bool) (*parser.ParsingNode, error) {
+	p := &state{data: data, memo: make(map[cell]step), versions: make(map[cell][]version)}
+	rootStep := p.at(0, root)
+	if !rootStep.ok {
+		return nil, fmt.Errorf("%w: rule Source doesn't match", parser.TextNotMatchErr)
+	}
+	if full && rootStep.advance != len(data) {
+		return nil, fmt.Errorf("%w: rule Source matched only first %v elements out of %v", parser.TextNotMatchErr, rootStep.advance, len(data))
+	}
+	nodes := transform(p.build(rootStep))
+	if len(nodes) != 1 {
+		return nil, fmt.Errorf("tree with multiple root was formed")
+	}
+	return nodes[0], nil
+}
+
+func (p *state) at(i, s int) step {
+	key := cell{position: i, rule: s}
+	if result, ok := p.memo[key]; ok {
+		return result
+	}
+	if !recursive[s] {
+		result := p.evaluate(i, s)
+		p.memo[key] = result
+		return result
+	}
+	group := components[component[s]]
+	for _, r := range group {
+		p.memo[cell{position: i, rule: r}] = step{}
+		p.versions[cell{position: i, rule: r}] = []version{{seq: -1}}
+	}
+	for grown := true; grown; {
+		grown = false
+		for _, r := range group {
+			p.seq++
+			seq := p.seq
+			next := p.evaluate(i, r)
+			if current := p.memo[cell{position: i, rule: r}]; next.ok && (!current.ok || next.advance > current.advance) {
+				p.memo[cell{position: i, rule: r}] = next
+				p.versions[cell{position: i, rule: r}] = append(p.versions[cell{position: i, rule: r}], version{seq: seq, step: next})
+				grown = true
+			}
+		}
+	}
+	return p.memo[key]
+}
+
+func (p *state) versionBefore(i, s, seq int) (step, int) {
+	versions := p.versions[cell{position: i, rule: s}]
+	for k := len(versions) - 1; k >= 0; k-- {
+		if versions[k].seq < seq {
+			return versions[k].step, versions[k].seq
+		}
+	}
+	panic(fmt.Errorf("left-recursive cell %v has no versions before %v", cell{position: i, rule: s}, seq))
+}
+
+func (p *state) evaluate(i, s int) step {
+	switch s {
+	case 0:
+		return p.rule0(i)
+	case 1:
+		return p.rule1(i)
+	case 2:
+		return p.rule2(i)
+	case 3:
+		return p.rule3(i)
+	case 4:
+		return p.rule4(i)
+	case 5:
+		return p.rule5(i)
+	case 6:
+		return p.rule6(i)
+	case 7:
+		return p.rule7(i)
+	case 8:
+		return p.rule8(i)
+	case 9:
+		return p.rule9(i)
+	case 10:
+		return p.rule10(i)
+	case 11:
+		return p.rule11(i)
+	case 12:
+		return p.rule12(i)
+	case 13:
+		return p.rule13(i)
+	case 14:
+		return p.rule14(i)
+	case 15:
+		return p.rule15(i)
+	case 16:
+		return p.rule16(i)
+	case 17:
+		return p.rule17(i)
+	case 18:
+		return p.rule18(i)
+	case 19:
+		return p.rule19(i)
+	case 20:
+		return p.rule20(i)
+	case 21:
+		return p.rule21(i)
+	case 22:
+		return p.rule22(i)
+	case 23:
+		return p.rule23(i)
+	case 24:
+		return p.rule24(i)
+	case 25:
+		return p.rule25(i)
+	case 26:
+		return p.rule26(i)
+	case 27:
+		return p.rule27(i)
+	case 28:
+		return p.rule28(i)
+	case 29:
+		return p.rule29(i)
+	case 30:
+		return p.rule30(i)
+	case 31:
+		return p.rule31(i)
+	case 32:
+		return p.rule32(i)
+	case 33:
+		return p.rule33(i)
+	case 34:
+		return p.rule34(i)
+	case 35:
+		return p.rule35(i)
+	case 36:
+		return p.rule36(i)
+	case 37:
+		return p.rule37(i)
+	case 38:
+		return p.rule38(i)
+	case 39:
+		return p.rule39(i)
+	case 40:
+		return p.rule40(i)
+	case 41:
+		return p.rule41(i)
+	case 42:
+		return p.rule42(i)
+	case 43:
+		return p.rule43(i)
+	case 44:
+		return p.rule44(i)
+	case 45:
+		return p.rule45(i)
+	case 46:
+		return p.rule46(i)
+	case 47:
+		return p.rule47(i)
+	case 48:
+		return p.rule48(i)
+	case 49:
+		return p.rule49(i)
+	case 50:
+		return p.rule50(i)
+	case 51:
+		return p.rule51(i)
+	case 52:
+		return p.rule52(i)
+	case 53:
+		return p.rule53(i)
+	case 54:
+		return p.rule54(i)
+	case 55:
+		return p.rule55(i)
+	}
+	panic(fmt.Errorf("unexpected rule: %v", s))
+}
+
+func (p *state) terminal(i, k int) step {
+	switch k {
+	case 0:
+		return p.terminal0(i)
+	case 1:
+		return p.terminal1(i)
+	case 2:
+		return p.terminal2(i)
+	case 3:
+		return p.terminal3(i)
+	case 4:
+		return p.terminal4(i)
+	case 5:
+		return p.terminal5(i)
+	case 6:
+		return p.terminal6(i)
+	case 7:
+		return p.terminal7(i)
+	case 8:
+		return p.terminal8(i)
+	case 9:
+		return p.terminal9(i)
+	case 10:
+		return p.terminal10(i)
+	case 11:
+		return p.terminal11(i)
+	case 12:
+		return p.terminal12(i)
+	case 13:
+		return p.terminal13(i)
+	case 14:
+		return p.terminal14(i)
+	}
+	panic(fmt.Errorf("unexpected terminal: %v", k))
+}
+
+// rule0 evaluates #c.SlashComment#9: !#c.SlashComment#8
+func (p *state) rule0(i int) step {
+	if p.at(i, 1).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule1 evaluates #c.SlashComment#8: !#c.EndOfLine#0
+func (p *state) rule1(i int) step {
+	if p.at(i, 45).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule2 evaluates #c.SlashComment#7: #c.SlashComment#6*
+func (p *state) rule2(i int) step {
+	current := i
+	for {
+		next := p.at(current, 3)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule3 evaluates #c.SlashComment#6: #c.SlashComment#5 #c.Any#0
+func (p *state) rule3(i int) step {
+	current := i
+	if next := p.at(current, 4); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 47); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule4 evaluates #c.SlashComment#5: !#c.EndOfLine#0
+func (p *state) rule4(i int) step {
+	if p.at(i, 45).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule5 evaluates #c.SlashComment#3: #c.SlashComment#2*
+func (p *state) rule5(i int) step {
+	current := i
+	for {
+		next := p.at(current, 6)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule6 evaluates #c.SlashComment#2: #c.SlashComment#1 #c.Any#0
+func (p *state) rule6(i int) step {
+	current := i
+	if next := p.at(current, 7); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 47); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule7 evaluates #c.SlashComment#1: !"*/"
+func (p *state) rule7(i int) step {
+	if p.terminal0(i).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule8 evaluates #c.SlashComment#0: #c.SlashComment#4 / #c.SlashComment#10
+func (p *state) rule8(i int) step {
+	if next := p.at(i, 10); next.ok {
+		return next
+	}
+	if next := p.at(i, 9); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule9 evaluates #c.SlashComment#10: "//" #c.SlashComment#7 #c.SlashComment#9
+func (p *state) rule9(i int) step {
+	current := i
+	if next := p.terminal1(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 2); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 0); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule10 evaluates #c.SlashComment#4: "/*" #c.SlashComment#3 "*/"
+func (p *state) rule10(i int) step {
+	current := i
+	if next := p.terminal2(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 5); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal0(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule11 evaluates #c.Number#0: =~"^(\\+|-)?\\d+(.\\d*)?"
+func (p *state) rule11(i int) step {
+	return p.terminal3(i)
+}
+
+// rule12 evaluates #Instruction#5: !#Instruction#4
+func (p *state) rule12(i int) step {
+	if p.at(i, 13).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule13 evaluates #Instruction#4: !#Space#0
+func (p *state) rule13(i int) step {
+	if p.at(i, 44).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule14 evaluates #Instruction#2: !#Instruction#1
+func (p *state) rule14(i int) step {
+	if p.at(i, 15).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule15 evaluates #Instruction#1: !#Space#0
+func (p *state) rule15(i int) step {
+	if p.at(i, 44).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule16 evaluates #Comment#3: #Comment#2*
+func (p *state) rule16(i int) step {
+	current := i
+	for {
+		next := p.at(current, 17)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule17 evaluates #Comment#2: #Comment#1 #c.Any#0
+func (p *state) rule17(i int) step {
+	current := i
+	if next := p.at(current, 18); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 47); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule18 evaluates #Comment#1: !#c.EndOfLine#0
+func (p *state) rule18(i int) step {
+	if p.at(i, 45).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule19 evaluates #Sequence#5: #Sequence#4*
+func (p *state) rule19(i int) step {
+	current := i
+	for {
+		next := p.at(current, 20)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule20 evaluates #Sequence#4: #Sequence#2 #Sequence#3
+func (p *state) rule20(i int) step {
+	current := i
+	if next := p.at(current, 22); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 21); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule21 evaluates #Sequence#3: None@47#0 / {class:"number", tag:"span"}:Token@51#0 / {class:"comment", tag:"span"}:Token@65#0 / None@79#0
+func (p *state) rule21(i int) step {
+	if next := p.at(i, 33); next.ok {
+		return next
+	}
+	if next := p.at(i, 31); next.ok {
+		return next
+	}
+	if next := p.at(i, 30); next.ok {
+		return next
+	}
+	if next := p.at(i, 29); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule22 evaluates #Sequence#2: !#c.EndOfLine#0
+func (p *state) rule22(i int) step {
+	if p.at(i, 45).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule23 evaluates None@90#9: None@90#8*
+func (p *state) rule23(i int) step {
+	current := i
+	for {
+		next := p.at(current, 24)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule24 evaluates None@90#8: None@90#5 None@90#6 None@90#7 #c.Any#0
+func (p *state) rule24(i int) step {
+	current := i
+	if next := p.at(current, 27); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 26); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 25); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 47); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule25 evaluates None@90#7: !"#"
+func (p *state) rule25(i int) step {
+	if p.terminal4(i).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule26 evaluates None@90#6: !#c.EndOfLine#0
+func (p *state) rule26(i int) step {
+	if p.at(i, 45).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule27 evaluates None@90#5: !#Space#0
+func (p *state) rule27(i int) step {
+	if p.at(i, 44).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule28 evaluates None@85#0: #c.EndOfLine#0
+func (p *state) rule28(i int) step {
+	return p.at(i, 45)
+}
+
+// rule29 evaluates None@79#0: #c.Any#0
+func (p *state) rule29(i int) step {
+	return p.at(i, 47)
+}
+
+// rule30 evaluates Token@65#0: #Comment#0
+func (p *state) rule30(i int) step {
+	return p.at(i, 55)
+}
+
+// rule31 evaluates Token@51#0: #Number#0
+func (p *state) rule31(i int) step {
+	return p.at(i, 32)
+}
+
+// rule32 evaluates #Number#0: =~"^\\$?(\\+|-)?\\d+(\\.\\d*)?"
+func (p *state) rule32(i int) step {
+	return p.terminal5(i)
+}
+
+// rule33 evaluates None@47#0: #c.Identifier#0
+func (p *state) rule33(i int) step {
+	return p.at(i, 34)
+}
+
+// rule34 evaluates #c.Identifier#0: =~"^[a-zA-Z][a-zA-Z0-9_]*"
+func (p *state) rule34(i int) step {
+	return p.terminal6(i)
+}
+
+// rule35 evaluates None@25#0: #c.EndOfLine#0
+func (p *state) rule35(i int) step {
+	return p.at(i, 45)
+}
+
+// rule36 evaluates Source#0: #Sequence#0*
+func (p *state) rule36(i int) step {
+	current := i
+	for {
+		next := p.at(current, 37)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule37 evaluates #Sequence#0: #Sequence#1 / #Sequence#6 / None@90#0 / None@103#0
+func (p *state) rule37(i int) step {
+	if next := p.at(i, 53); next.ok {
+		return next
+	}
+	if next := p.at(i, 48); next.ok {
+		return next
+	}
+	if next := p.at(i, 39); next.ok {
+		return next
+	}
+	if next := p.at(i, 38); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule38 evaluates None@103#0: #c.Any#0
+func (p *state) rule38(i int) step {
+	return p.at(i, 47)
+}
+
+// rule39 evaluates None@90#0: None@90#4 None@90#9
+func (p *state) rule39(i int) step {
+	current := i
+	if next := p.at(current, 40); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 23); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule40 evaluates None@90#4: None@90#1 None@90#2 None@90#3 #c.Any#0
+func (p *state) rule40(i int) step {
+	current := i
+	if next := p.at(current, 43); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 42); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 41); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 47); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule41 evaluates None@90#3: !"#"
+func (p *state) rule41(i int) step {
+	if p.terminal4(i).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule42 evaluates None@90#2: !#c.EndOfLine#0
+func (p *state) rule42(i int) step {
+	if p.at(i, 45).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule43 evaluates None@90#1: !#Space#0
+func (p *state) rule43(i int) step {
+	if p.at(i, 44).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule44 evaluates #Space#0: " " / "\t" / #c.EndOfLine#0
+func (p *state) rule44(i int) step {
+	if next := p.terminal7(i); next.ok {
+		return next
+	}
+	if next := p.terminal8(i); next.ok {
+		return next
+	}
+	if next := p.at(i, 45); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule45 evaluates #c.EndOfLine#0: "\n" / #c.EndOfLine#1
+func (p *state) rule45(i int) step {
+	if next := p.terminal9(i); next.ok {
+		return next
+	}
+	if next := p.at(i, 46); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule46 evaluates #c.EndOfLine#1: !#c.Any#0
+func (p *state) rule46(i int) step {
+	if p.at(i, 47).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule47 evaluates #c.Any#0: . / @invalid-utf8
+func (p *state) rule47(i int) step {
+	if next := p.terminal10(i); next.ok {
+		return next
+	}
+	if next := p.terminal11(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule48 evaluates #Sequence#6: {class:"keyword", tag:"span"}:Token@30#0 #Sequence#5 None@85#0
+func (p *state) rule48(i int) step {
+	current := i
+	if next := p.at(current, 49); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 19); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 28); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule49 evaluates Token@30#0: #Instruction#0
+func (p *state) rule49(i int) step {
+	return p.at(i, 50)
+}
+
+// rule50 evaluates #Instruction#0: #Instruction#3 / #Instruction#6
+func (p *state) rule50(i int) step {
+	if next := p.at(i, 52); next.ok {
+		return next
+	}
+	if next := p.at(i, 51); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule51 evaluates #Instruction#6: =~"^[A-Z][A-Z0-9]+" #Instruction#5
+func (p *state) rule51(i int) step {
+	current := i
+	if next := p.terminal12(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 12); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule52 evaluates #Instruction#3: =~"^[a-z][a-z0-9]+" #Instruction#2
+func (p *state) rule52(i int) step {
+	current := i
+	if next := p.terminal13(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 14); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule53 evaluates #Sequence#1: {class:"comment", tag:"span"}:Token@12#0 None@25#0
+func (p *state) rule53(i int) step {
+	current := i
+	if next := p.at(current, 54); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 35); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule54 evaluates Token@12#0: #Comment#0
+func (p *state) rule54(i int) step {
+	return p.at(i, 55)
+}
+
+// rule55 evaluates #Comment#0: "# " #Comment#3
+func (p *state) rule55(i int) step {
+	current := i
+	if next := p.terminal14(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 16); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// terminal0 matches "*/"
+func (p *state) terminal0(i int) step {
+	if bytes.HasPrefix(p.data[i:], token0) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal1 matches "//"
+func (p *state) terminal1(i int) step {
+	if bytes.HasPrefix(p.data[i:], token1) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal2 matches "/*"
+func (p *state) terminal2(i int) step {
+	if bytes.HasPrefix(p.data[i:], token2) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal3 matches =~"^(\\+|-)?\\d+(.\\d*)?"
+func (p *state) terminal3(i int) step {
+	location := pattern3.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal4 matches "#"
+func (p *state) terminal4(i int) step {
+	if bytes.HasPrefix(p.data[i:], token4) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal5 matches =~"^\\$?(\\+|-)?\\d+(\\.\\d*)?"
+func (p *state) terminal5(i int) step {
+	location := pattern5.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal6 matches =~"^[a-zA-Z][a-zA-Z0-9_]*"
+func (p *state) terminal6(i int) step {
+	location := pattern6.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal7 matches " "
+func (p *state) terminal7(i int) step {
+	if bytes.HasPrefix(p.data[i:], token7) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal8 matches "\t"
+func (p *state) terminal8(i int) step {
+	if bytes.HasPrefix(p.data[i:], token8) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal9 matches "\n"
+func (p *state) terminal9(i int) step {
+	if bytes.HasPrefix(p.data[i:], token9) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal10 matches .
+func (p *state) terminal10(i int) step {
+	current, size := utf8.DecodeRune(p.data[i:])
+	if size == 0 || (current == utf8.RuneError && size == 1) {
+		return step{}
+	}
+	return step{ok: true, advance: size}
+}
+
+// terminal11 matches @invalid-utf8
+func (p *state) terminal11(i int) step {
+	if current, size := utf8.DecodeRune(p.data[i:]); current == utf8.RuneError && size == 1 {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal12 matches =~"^[A-Z][A-Z0-9]+"
+func (p *state) terminal12(i int) step {
+	location := pattern12.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal13 matches =~"^[a-z][a-z0-9]+"
+func (p *state) terminal13(i int) step {
+	location := pattern13.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal14 matches "# "
+func (p *state) terminal14(i int) step {
+	if bytes.HasPrefix(p.data[i:], token14) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+func (p *state) derive(parent frame, i int, l leaf) (step, int) {
+	if l.rule < 0 {
+		return p.terminal(i, l.terminal), 0
+	}
+	if !recursive[l.rule] {
+		return p.at(i, l.rule), 0
+	}
+	limit := math.MaxInt
+	if i == parent.node.Segment.Start && component[l.rule] == component[parent.rule] {
+		limit = parent.seq
+	}
+	p.at(i, l.rule)
+	return p.versionBefore(i, l.rule, limit)
+}
+
+func (p *state) build(rootStep step) *parser.ParsingNode {
+	rootNode := parser.NewParsingNode[byte](names[root], nil, p.data, definition.Segment{Start: 0, End: rootStep.advance})
+	rootFrame := frame{node: &rootNode, rule: root, seq: math.MaxInt}
+	if recursive[root] {
+		_, rootFrame.seq = p.versionBefore(0, root, math.MaxInt)
+	}
+	derivation := []frame{rootFrame}
+	for k := 0; k < len(derivation); k++ {
+		current := derivation[k]
+		addChild := func(l leaf, segment definition.Segment, seq int) {
+			if l.rule < 0 {
+				return
+			}
+			next := parser.NewParsingNode[byte](l.name, l.attributes, p.data, segment)
+			current.node.Children = append(current.node.Children, &next)
+			derivation = append(derivation, frame{node: &next, rule: l.rule, seq: seq})
+		}
+		start := current.node.Segment.Start
+		rule := shapes[current.rule]
+		switch rule.kind {
+		case kindSymbol:
+			next, seq := p.derive(current, start, rule.leaves[0])
+			if next.ok {
+				addChild(rule.leaves[0], current.node.Segment, seq)
+			}
+		case kindKleene:
+			for position := start; ; {
+				next, seq := p.derive(current, position, rule.leaves[0])
+				if !next.ok || next.advance == 0 {
+					break
+				}
+				addChild(rule.leaves[0], definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindJunction:
+			position := start
+			for _, l := range rule.leaves {
+				next, seq := p.derive(current, position, l)
+				addChild(l, definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindChoice:
+			for _, l := range rule.leaves {
+				if next, seq := p.derive(current, start, l); next.ok {
+					addChild(l, definition.Segment{Start: start, End: start + next.advance}, seq)
+					break
+				}
+			}
+		}
+	}
+	return &rootNode
+}
+
+func transform(node *parser.ParsingNode) []*parser.ParsingNode {
+	name, ok := mapping[node.Atom.Symbol]
+	symbol, hidden := definition.AnalyzeSymbolName(name)
+	shape := nodeShapes[name]
+	if !ok || hidden || shape.inline {
+		nodes := make([]*parser.ParsingNode, 0, len(node.Children))
+		for _, child := range node.Children {
+			nodes = append(nodes, transform(child)...)
+		}
+		return nodes
+	}
+	if shape.drop {
+		return nil
+	}
+	atom := node.Atom
+	atom.Symbol = symbol
+	if shape.rename != "" {
+		atom.Symbol = shape.rename
+	}
+	next := parser.ParsingNode{Atom: atom, Segment: node.Segment}
+	if shape.token {
+		return []*parser.ParsingNode{&next}
+	}
+	for _, child := range node.Children {
+		for _, transformed := range transform(child) {
+			if shape.flatten && transformed.Atom.Symbol == next.Atom.Symbol {
+				next.Children = append(next.Children, transformed.Children...)
+				continue
+			}
+			next.Children = append(next.Children, transformed)
+		}
+	}
+	if shape.collapse && len(next.Children) == 1 {
+		return next.Children
+	}
+	return []*parser.ParsingNode{&next}
+}
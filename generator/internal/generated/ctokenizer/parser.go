@@ -0,0 +1,2206 @@
+// Code generated by gopeg generate; DO NOT EDIT.
+
+package ctokenizer
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/parser"
+)
+
+type (
+	cell struct{ position, rule int }
+	step struct {
+		ok      bool
+		advance int
+	}
+	version struct {
+		seq  int
+		step step
+	}
+	leaf struct {
+		rule       int
+		terminal   int
+		name       string
+		attributes map[string][]byte
+	}
+	shape struct {
+		kind   int
+		leaves []leaf
+	}
+	frame struct {
+		node *parser.ParsingNode
+		rule int
+		seq  int
+	}
+	nodeShape struct {
+		inline   bool
+		flatten  bool
+		collapse bool
+		token    bool
+		drop     bool
+		rename   string
+	}
+	state struct {
+		data     []byte
+		memo     map[cell]step
+		seq      int
+		versions map[cell][]version
+	}
+)
+
+const (
+	kindTerminal = iota
+	kindSymbol
+	kindJunction
+	kindChoice
+	kindKleene
+	kindNegation
+)
+
+const root = 13
+
+var (
+	token0     = []byte("*/")
+	token1     = []byte("\n")
+	token2     = []byte("(")
+	pattern3   = regexp.MustCompile("^[a-zA-Z0-9_]+")
+	token6     = []byte("//")
+	token7     = []byte("/*")
+	pattern8   = regexp.MustCompile("^(\\+|-)?\\d+(.\\d*)?")
+	pattern9   = regexp.MustCompile("^[a-zA-Z][a-zA-Z0-9_]*")
+	token10    = []byte("alignas")
+	token11    = []byte("alignof")
+	token12    = []byte("and")
+	token13    = []byte("and_eq")
+	token14    = []byte("asm")
+	token15    = []byte("atomic_cancel")
+	token16    = []byte("atomic_commit")
+	token17    = []byte("atomic_noexcept")
+	token18    = []byte("auto")
+	token19    = []byte("bitand")
+	token20    = []byte("bitor")
+	token21    = []byte("bool")
+	token22    = []byte("break")
+	token23    = []byte("case")
+	token24    = []byte("catch")
+	token25    = []byte("char")
+	token26    = []byte("char8_t")
+	token27    = []byte("char16_t")
+	token28    = []byte("char32_t")
+	token29    = []byte("class")
+	token30    = []byte("compl")
+	token31    = []byte("concept")
+	token32    = []byte("const")
+	token33    = []byte("consteval")
+	token34    = []byte("constexpr")
+	token35    = []byte("constinit")
+	token36    = []byte("const_cast")
+	token37    = []byte("continue")
+	token38    = []byte("co_await")
+	token39    = []byte("co_return")
+	token40    = []byte("co_yield")
+	token41    = []byte("decltype")
+	token42    = []byte("default")
+	token43    = []byte("delete")
+	token44    = []byte("do")
+	token45    = []byte("double")
+	token46    = []byte("dynamic_cast")
+	token47    = []byte("else")
+	token48    = []byte("enum")
+	token49    = []byte("explicit")
+	token50    = []byte("export")
+	token51    = []byte("extern")
+	token52    = []byte("false")
+	token53    = []byte("float")
+	token54    = []byte("for")
+	token55    = []byte("friend")
+	token56    = []byte("goto")
+	token57    = []byte("if")
+	token58    = []byte("inline")
+	token59    = []byte("int")
+	token60    = []byte("long")
+	token61    = []byte("mutable")
+	token62    = []byte("namespace")
+	token63    = []byte("new")
+	token64    = []byte("noexcept")
+	token65    = []byte("not")
+	token66    = []byte("not_eq")
+	token67    = []byte("nullptr")
+	token68    = []byte("operator")
+	token69    = []byte("or")
+	token70    = []byte("or_eq")
+	token71    = []byte("private")
+	token72    = []byte("protected")
+	token73    = []byte("public")
+	token74    = []byte("reflexpr")
+	token75    = []byte("register")
+	token76    = []byte("reinterpret_cast")
+	token77    = []byte("requires")
+	token78    = []byte("return")
+	token79    = []byte("short")
+	token80    = []byte("signed")
+	token81    = []byte("sizeof")
+	token82    = []byte("static")
+	token83    = []byte("static_assert")
+	token84    = []byte("static_cast")
+	token85    = []byte("struct")
+	token86    = []byte("switch")
+	token87    = []byte("synchronized")
+	token88    = []byte("template")
+	token89    = []byte("this")
+	token90    = []byte("thread_local")
+	token91    = []byte("throw")
+	token92    = []byte("true")
+	token93    = []byte("try")
+	token94    = []byte("typedef")
+	token95    = []byte("typeid")
+	token96    = []byte("typename")
+	token97    = []byte("union")
+	token98    = []byte("unsigned")
+	token99    = []byte("using")
+	token100   = []byte("virtual")
+	token101   = []byte("void")
+	token102   = []byte("volatile")
+	token103   = []byte("wchar_t")
+	token104   = []byte("while")
+	token105   = []byte("xor")
+	token106   = []byte("xor_eq")
+	token107   = []byte("#")
+	pattern108 = regexp.MustCompile("^'(\\.|[^'\\\\])*'")
+	pattern109 = regexp.MustCompile("^\"(\\.|[^\\\"\\\\])*\"")
+)
+
+var (
+	names      = []string{"#c.SlashComment#9", "#c.SlashComment#8", "#c.SlashComment#7", "#c.SlashComment#6", "#c.SlashComment#5", "#c.SlashComment#3", "#c.SlashComment#2", "#c.SlashComment#1", "#c.EndOfLine#0", "#c.EndOfLine#1", "Token@66#2", "Token@66#1", "Token@48#1", "Source#0", "#Sequence#0", "None@126#0", "#c.Any#0", "Token@112#0", "#c.SlashComment#0", "#c.SlashComment#10", "#c.SlashComment#4", "Token@98#0", "#c.Number#0", "Token@84#0", "Token@66#0", "#c.Identifier#0", "Token@48#0", "#Keywords#0", "Token@31#0", "Token@13#0"}
+	recursive  = []bool{false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false}
+	component  = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29}
+	components = [][]int{{0}, {1}, {2}, {3}, {4}, {5}, {6}, {7}, {8}, {9}, {10}, {11}, {12}, {13}, {14}, {15}, {16}, {17}, {18}, {19}, {20}, {21}, {22}, {23}, {24}, {25}, {26}, {27}, {28}, {29}}
+	shapes     = []shape{
+		{kind: kindNegation, leaves: []leaf{{rule: 1, name: "#c.SlashComment#8", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 8, name: "#c.EndOfLine#0", attributes: nil}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 3, name: "#c.SlashComment#6", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 4, name: "#c.SlashComment#5", attributes: nil}, {rule: 16, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 8, name: "#c.EndOfLine#0", attributes: nil}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 6, name: "#c.SlashComment#2", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 7, name: "#c.SlashComment#1", attributes: nil}, {rule: 16, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: -1, terminal: 0}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 1}, {rule: 9, name: "#c.EndOfLine#1", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 16, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 11, name: "Token@66#1", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: -1, terminal: 2}}},
+		{kind: kindNegation, leaves: []leaf{{rule: -1, terminal: 3}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 14, name: "#Sequence#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 29, name: "Token@13#0", attributes: map[string][]byte{"class": []byte("string"), "tag": []byte("span")}}, {rule: 28, name: "Token@31#0", attributes: map[string][]byte{"class": []byte("macro"), "tag": []byte("span")}}, {rule: 26, name: "Token@48#0", attributes: map[string][]byte{"class": []byte("keyword"), "tag": []byte("span")}}, {rule: 24, name: "Token@66#0", attributes: map[string][]byte{"class": []byte("function"), "tag": []byte("span")}}, {rule: 23, name: "Token@84#0", attributes: map[string][]byte{"class": []byte("identifier"), "tag": []byte("span")}}, {rule: 21, name: "Token@98#0", attributes: map[string][]byte{"class": []byte("number"), "tag": []byte("span")}}, {rule: 17, name: "Token@112#0", attributes: map[string][]byte{"class": []byte("comment"), "tag": []byte("span")}}, {rule: 15, name: "None@126#0", attributes: nil}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 16, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 4}, {rule: -1, terminal: 5}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 18, name: "#c.SlashComment#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 20, name: "#c.SlashComment#4", attributes: nil}, {rule: 19, name: "#c.SlashComment#10", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 6}, {rule: 2, name: "#c.SlashComment#7", attributes: nil}, {rule: 0, name: "#c.SlashComment#9", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 7}, {rule: 5, name: "#c.SlashComment#3", attributes: nil}, {rule: -1, terminal: 0}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 22, name: "#c.Number#0", attributes: nil}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 8}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 25, name: "#c.Identifier#0", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 25, name: "#c.Identifier#0", attributes: nil}, {rule: 10, name: "Token@66#2", attributes: nil}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 9}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 27, name: "#Keywords#0", attributes: nil}, {rule: 12, name: "Token@48#1", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 10}, {rule: -1, terminal: 11}, {rule: -1, terminal: 12}, {rule: -1, terminal: 13}, {rule: -1, terminal: 14}, {rule: -1, terminal: 15}, {rule: -1, terminal: 16}, {rule: -1, terminal: 17}, {rule: -1, terminal: 18}, {rule: -1, terminal: 19}, {rule: -1, terminal: 20}, {rule: -1, terminal: 21}, {rule: -1, terminal: 22}, {rule: -1, terminal: 23}, {rule: -1, terminal: 24}, {rule: -1, terminal: 25}, {rule: -1, terminal: 26}, {rule: -1, terminal: 27}, {rule: -1, terminal: 28}, {rule: -1, terminal: 29}, {rule: -1, terminal: 30}, {rule: -1, terminal: 31}, {rule: -1, terminal: 32}, {rule: -1, terminal: 33}, {rule: -1, terminal: 34}, {rule: -1, terminal: 35}, {rule: -1, terminal: 36}, {rule: -1, terminal: 37}, {rule: -1, terminal: 38}, {rule: -1, terminal: 39}, {rule: -1, terminal: 40}, {rule: -1, terminal: 41}, {rule: -1, terminal: 42}, {rule: -1, terminal: 43}, {rule: -1, terminal: 44}, {rule: -1, terminal: 45}, {rule: -1, terminal: 46}, {rule: -1, terminal: 47}, {rule: -1, terminal: 48}, {rule: -1, terminal: 49}, {rule: -1, terminal: 50}, {rule: -1, terminal: 51}, {rule: -1, terminal: 52}, {rule: -1, terminal: 53}, {rule: -1, terminal: 54}, {rule: -1, terminal: 55}, {rule: -1, terminal: 56}, {rule: -1, terminal: 57}, {rule: -1, terminal: 58}, {rule: -1, terminal: 59}, {rule: -1, terminal: 60}, {rule: -1, terminal: 61}, {rule: -1, terminal: 62}, {rule: -1, terminal: 63}, {rule: -1, terminal: 64}, {rule: -1, terminal: 65}, {rule: -1, terminal: 66}, {rule: -1, terminal: 67}, {rule: -1, terminal: 68}, {rule: -1, terminal: 69}, {rule: -1, terminal: 70}, {rule: -1, terminal: 71}, {rule: -1, terminal: 72}, {rule: -1, terminal: 73}, {rule: -1, terminal: 74}, {rule: -1, terminal: 75}, {rule: -1, terminal: 76}, {rule: -1, terminal: 77}, {rule: -1, terminal: 78}, {rule: -1, terminal: 79}, {rule: -1, terminal: 80}, {rule: -1, terminal: 81}, {rule: -1, terminal: 82}, {rule: -1, terminal: 83}, {rule: -1, terminal: 84}, {rule: -1, terminal: 85}, {rule: -1, terminal: 86}, {rule: -1, terminal: 87}, {rule: -1, terminal: 88}, {rule: -1, terminal: 89}, {rule: -1, terminal: 90}, {rule: -1, terminal: 91}, {rule: -1, terminal: 92}, {rule: -1, terminal: 93}, {rule: -1, terminal: 94}, {rule: -1, terminal: 95}, {rule: -1, terminal: 96}, {rule: -1, terminal: 97}, {rule: -1, terminal: 98}, {rule: -1, terminal: 99}, {rule: -1, terminal: 100}, {rule: -1, terminal: 101}, {rule: -1, terminal: 102}, {rule: -1, terminal: 103}, {rule: -1, terminal: 104}, {rule: -1, terminal: 105}, {rule: -1, terminal: 106}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 107}, {rule: 25, name: "#c.Identifier#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 108}, {rule: -1, terminal: 109}}},
+	}
+	mapping = map[string]string{
+		"#Keywords#0":       "#Keywords",
+		"#Sequence#0":       "#Sequence",
+		"#c.Any#0":          "#c.Any",
+		"#c.EndOfLine#0":    "#c.EndOfLine",
+		"#c.Identifier#0":   "#c.Identifier",
+		"#c.Number#0":       "#c.Number",
+		"#c.SlashComment#0": "#c.SlashComment",
+		"None@126#0":        "None@126",
+		"Source#0":          "Source",
+		"Token@112#0":       "Token@112",
+		"Token@13#0":        "Token@13",
+		"Token@31#0":        "Token@31",
+		"Token@48#0":        "Token@48",
+		"Token@66#0":        "Token@66",
+		"Token@84#0":        "Token@84",
+		"Token@98#0":        "Token@98",
+	}
+	nodeShapes = map[string]nodeShape{}
+)
+
+// Parse matches the longest prefix of the data with Source rule
+func Parse(data []byte) (*parser.ParsingNode, error) { return parse(data, false) }
+
+// ParseFull matches the whole data with Source rule
+func ParseFull(data []byte) (*parser.ParsingNode, error) { return parse(data, true) }
+
+func parse(data []byte, full bool) (*parser.ParsingNode, error) {
+	p := &state{data: data, memo: make(map[cell]step), versions: make(map[cell][]version)}
+	rootStep := p.at(0, root)
+	if !rootStep.ok {
+		return nil, fmt.Errorf("%w: rule Source doesn't match", parser.TextNotMatchErr)
+	}
+	if full && rootStep.advance != len(data) {
+		return nil, fmt.Errorf("%w: rule Source matched only first %v elements out of %v", parser.TextNotMatchErr, rootStep.advance, len(data))
+	}
+	nodes := transform(p.build(rootStep))
+	if len(nodes) != 1 {
+		return nil, fmt.Errorf("tree with multiple root was formed")
+	}
+	return nodes[0], nil
+}
+
+func (p *state) at(i, s int) step {
+	key := cell{position: i, rule: s}
+	if result, ok := p.memo[key]; ok {
+		return result
+	}
+	if !recursive[s] {
+		result := p.evaluate(i, s)
+		p.memo[key] = result
+		return result
+	}
+	group := components[component[s]]
+	for _, r := range group {
+		p.memo[cell{position: i, rule: r}] = step{}
+		p.versions[cell{position: i, rule: r}] = []version{{seq: -1}}
+	}
+	for grown := true; grown; {
+		grown = false
+		for _, r := range group {
+			p.seq++
+			seq := p.seq
+			next := p.evaluate(i, r)
+			if current := p.memo[cell{position: i, rule: r}]; next.ok && (!current.ok || next.advance > current.advance) {
+				p.memo[cell{position: i, rule: r}] = next
+				p.versions[cell{position: i, rule: r}] = append(p.versions[cell{position: i, rule: r}], version{seq: seq, step: next})
+				grown = true
+			}
+		}
+	}
+	return p.memo[key]
+}
+
+func (p *state) versionBefore(i, s, seq int) (step, int) {
+	versions := p.versions[cell{position: i, rule: s}]
+	for k := len(versions) - 1; k >= 0; k-- {
+		if versions[k].seq < seq {
+			return versions[k].step, versions[k].seq
+		}
+	}
+	panic(fmt.Errorf("left-recursive cell %v has no versions before %v", cell{position: i, rule: s}, seq))
+}
+
+func (p *state) evaluate(i, s int) step {
+	switch s {
+	case 0:
+		return p.rule0(i)
+	case 1:
+		return p.rule1(i)
+	case 2:
+		return p.rule2(i)
+	case 3:
+		return p.rule3(i)
+	case 4:
+		return p.rule4(i)
+	case 5:
+		return p.rule5(i)
+	case 6:
+		return p.rule6(i)
+	case 7:
+		return p.rule7(i)
+	case 8:
+		return p.rule8(i)
+	case 9:
+		return p.rule9(i)
+	case 10:
+		return p.rule10(i)
+	case 11:
+		return p.rule11(i)
+	case 12:
+		return p.rule12(i)
+	case 13:
+		return p.rule13(i)
+	case 14:
+		return p.rule14(i)
+	case 15:
+		return p.rule15(i)
+	case 16:
+		return p.rule16(i)
+	case 17:
+		return p.rule17(i)
+	case 18:
+		return p.rule18(i)
+	case 19:
+		return p.rule19(i)
+	case 20:
+		return p.rule20(i)
+	case 21:
+		return p.rule21(i)
+	case 22:
+		return p.rule22(i)
+	case 23:
+		return p.rule23(i)
+	case 24:
+		return p.rule24(i)
+	case 25:
+		return p.rule25(i)
+	case 26:
+		return p.rule26(i)
+	case 27:
+		return p.rule27(i)
+	case 28:
+		return p.rule28(i)
+	case 29:
+		return p.rule29(i)
+	}
+	panic(fmt.Errorf("unexpected rule: %v", s))
+}
+
+func (p *state) terminal(i, k int) step {
+	switch k {
+	case 0:
+		return p.terminal0(i)
+	case 1:
+		return p.terminal1(i)
+	case 2:
+		return p.terminal2(i)
+	case 3:
+		return p.terminal3(i)
+	case 4:
+		return p.terminal4(i)
+	case 5:
+		return p.terminal5(i)
+	case 6:
+		return p.terminal6(i)
+	case 7:
+		return p.terminal7(i)
+	case 8:
+		return p.terminal8(i)
+	case 9:
+		return p.terminal9(i)
+	case 10:
+		return p.terminal10(i)
+	case 11:
+		return p.terminal11(i)
+	case 12:
+		return p.terminal12(i)
+	case 13:
+		return p.terminal13(i)
+	case 14:
+		return p.terminal14(i)
+	case 15:
+		return p.terminal15(i)
+	case 16:
+		return p.terminal16(i)
+	case 17:
+		return p.terminal17(i)
+	case 18:
+		return p.terminal18(i)
+	case 19:
+		return p.terminal19(i)
+	case 20:
+		return p.terminal20(i)
+	case 21:
+		return p.terminal21(i)
+	case 22:
+		return p.terminal22(i)
+	case 23:
+		return p.terminal23(i)
+	case 24:
+		return p.terminal24(i)
+	case 25:
+		return p.terminal25(i)
+	case 26:
+		return p.terminal26(i)
+	case 27:
+		return p.terminal27(i)
+	case 28:
+		return p.terminal28(i)
+	case 29:
+		return p.terminal29(i)
+	case 30:
+		return p.terminal30(i)
+	case 31:
+		return p.terminal31(i)
+	case 32:
+		return p.terminal32(i)
+	case 33:
+		return p.terminal33(i)
+	case 34:
+		return p.terminal34(i)
+	case 35:
+		return p.terminal35(i)
+	case 36:
+		return p.terminal36(i)
+	case 37:
+		return p.terminal37(i)
+	case 38:
+		return p.terminal38(i)
+	case 39:
+		return p.terminal39(i)
+	case 40:
+		return p.terminal40(i)
+	case 41:
+		return p.terminal41(i)
+	case 42:
+		return p.terminal42(i)
+	case 43:
+		return p.terminal43(i)
+	case 44:
+		return p.terminal44(i)
+	case 45:
+		return p.terminal45(i)
+	case 46:
+		return p.terminal46(i)
+	case 47:
+		return p.terminal47(i)
+	case 48:
+		return p.terminal48(i)
+	case 49:
+		return p.terminal49(i)
+	case 50:
+		return p.terminal50(i)
+	case 51:
+		return p.terminal51(i)
+	case 52:
+		return p.terminal52(i)
+	case 53:
+		return p.terminal53(i)
+	case 54:
+		return p.terminal54(i)
+	case 55:
+		return p.terminal55(i)
+	case 56:
+		return p.terminal56(i)
+	case 57:
+		return p.terminal57(i)
+	case 58:
+		return p.terminal58(i)
+	case 59:
+		return p.terminal59(i)
+	case 60:
+		return p.terminal60(i)
+	case 61:
+		return p.terminal61(i)
+	case 62:
+		return p.terminal62(i)
+	case 63:
+		return p.terminal63(i)
+	case 64:
+		return p.terminal64(i)
+	case 65:
+		return p.terminal65(i)
+	case 66:
+		return p.terminal66(i)
+	case 67:
+		return p.terminal67(i)
+	case 68:
+		return p.terminal68(i)
+	case 69:
+		return p.terminal69(i)
+	case 70:
+		return p.terminal70(i)
+	case 71:
+		return p.terminal71(i)
+	case 72:
+		return p.terminal72(i)
+	case 73:
+		return p.terminal73(i)
+	case 74:
+		return p.terminal74(i)
+	case 75:
+		return p.terminal75(i)
+	case 76:
+		return p.terminal76(i)
+	case 77:
+		return p.terminal77(i)
+	case 78:
+		return p.terminal78(i)
+	case 79:
+		return p.terminal79(i)
+	case 80:
+		return p.terminal80(i)
+	case 81:
+		return p.terminal81(i)
+	case 82:
+		return p.terminal82(i)
+	case 83:
+		return p.terminal83(i)
+	case 84:
+		return p.terminal84(i)
+	case 85:
+		return p.terminal85(i)
+	case 86:
+		return p.terminal86(i)
+	case 87:
+		return p.terminal87(i)
+	case 88:
+		return p.terminal88(i)
+	case 89:
+		return p.terminal89(i)
+	case 90:
+		return p.terminal90(i)
+	case 91:
+		return p.terminal91(i)
+	case 92:
+		return p.terminal92(i)
+	case 93:
+		return p.terminal93(i)
+	case 94:
+		return p.terminal94(i)
+	case 95:
+		return p.terminal95(i)
+	case 96:
+		return p.terminal96(i)
+	case 97:
+		return p.terminal97(i)
+	case 98:
+		return p.terminal98(i)
+	case 99:
+		return p.terminal99(i)
+	case 100:
+		return p.terminal100(i)
+	case 101:
+		return p.terminal101(i)
+	case 102:
+		return p.terminal102(i)
+	case 103:
+		return p.terminal103(i)
+	case 104:
+		return p.terminal104(i)
+	case 105:
+		return p.terminal105(i)
+	case 106:
+		return p.terminal106(i)
+	case 107:
+		return p.terminal107(i)
+	case 108:
+		return p.terminal108(i)
+	case 109:
+		return p.terminal109(i)
+	}
+	panic(fmt.Errorf("unexpected terminal: %v", k))
+}
+
+// rule0 evaluates #c.SlashComment#9: !#c.SlashComment#8
+func (p *state) rule0(i int) step {
+	if p.at(i, 1).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule1 evaluates #c.SlashComment#8: !#c.EndOfLine#0
+func (p *state) rule1(i int) step {
+	if p.at(i, 8).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule2 evaluates #c.SlashComment#7: #c.SlashComment#6*
+func (p *state) rule2(i int) step {
+	current := i
+	for {
+		next := p.at(current, 3)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule3 evaluates #c.SlashComment#6: #c.SlashComment#5 #c.Any#0
+func (p *state) rule3(i int) step {
+	current := i
+	if next := p.at(current, 4); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 16); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule4 evaluates #c.SlashComment#5: !#c.EndOfLine#0
+func (p *state) rule4(i int) step {
+	if p.at(i, 8).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule5 evaluates #c.SlashComment#3: #c.SlashComment#2*
+func (p *state) rule5(i int) step {
+	current := i
+	for {
+		next := p.at(current, 6)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule6 evaluates #c.SlashComment#2: #c.SlashComment#1 #c.Any#0
+func (p *state) rule6(i int) step {
+	current := i
+	if next := p.at(current, 7); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 16); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule7 evaluates #c.SlashComment#1: !"*/"
+func (p *state) rule7(i int) step {
+	if p.terminal0(i).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule8 evaluates #c.EndOfLine#0: "\n" / #c.EndOfLine#1
+func (p *state) rule8(i int) step {
+	if next := p.terminal1(i); next.ok {
+		return next
+	}
+	if next := p.at(i, 9); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule9 evaluates #c.EndOfLine#1: !#c.Any#0
+func (p *state) rule9(i int) step {
+	if p.at(i, 16).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule10 evaluates Token@66#2: !Token@66#1
+func (p *state) rule10(i int) step {
+	if p.at(i, 11).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule11 evaluates Token@66#1: !"("
+func (p *state) rule11(i int) step {
+	if p.terminal2(i).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule12 evaluates Token@48#1: !=~"^[a-zA-Z0-9_]+"
+func (p *state) rule12(i int) step {
+	if p.terminal3(i).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule13 evaluates Source#0: #Sequence#0*
+func (p *state) rule13(i int) step {
+	current := i
+	for {
+		next := p.at(current, 14)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule14 evaluates #Sequence#0: {class:"string", tag:"span"}:Token@13#0 / {class:"macro", tag:"span"}:Token@31#0 / {class:"keyword", tag:"span"}:Token@48#0 / {class:"function", tag:"span"}:Token@66#0 / {class:"identifier", tag:"span"}:Token@84#0 / {class:"number", tag:"span"}:Token@98#0 / {class:"comment", tag:"span"}:Token@112#0 / None@126#0
+func (p *state) rule14(i int) step {
+	if next := p.at(i, 29); next.ok {
+		return next
+	}
+	if next := p.at(i, 28); next.ok {
+		return next
+	}
+	if next := p.at(i, 26); next.ok {
+		return next
+	}
+	if next := p.at(i, 24); next.ok {
+		return next
+	}
+	if next := p.at(i, 23); next.ok {
+		return next
+	}
+	if next := p.at(i, 21); next.ok {
+		return next
+	}
+	if next := p.at(i, 17); next.ok {
+		return next
+	}
+	if next := p.at(i, 15); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule15 evaluates None@126#0: #c.Any#0
+func (p *state) rule15(i int) step {
+	return p.at(i, 16)
+}
+
+// rule16 evaluates #c.Any#0: . / @invalid-utf8
+func (p *state) rule16(i int) step {
+	if next := p.terminal4(i); next.ok {
+		return next
+	}
+	if next := p.terminal5(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule17 evaluates Token@112#0: #c.SlashComment#0
+func (p *state) rule17(i int) step {
+	return p.at(i, 18)
+}
+
+// rule18 evaluates #c.SlashComment#0: #c.SlashComment#4 / #c.SlashComment#10
+func (p *state) rule18(i int) step {
+	if next := p.at(i, 20); next.ok {
+		return next
+	}
+	if next := p.at(i, 19); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule19 evaluates #c.SlashComment#10: "//" #c.SlashComment#7 #c.SlashComment#9
+func (p *state) rule19(i int) step {
+	current := i
+	if next := p.terminal6(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 2); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 0); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule20 evaluates #c.SlashComment#4: "/*" #c.SlashComment#3 "*/"
+func (p *state) rule20(i int) step {
+	current := i
+	if next := p.terminal7(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 5); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal0(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule21 evaluates Token@98#0: #c.Number#0
+func (p *state) rule21(i int) step {
+	return p.at(i, 22)
+}
+
+// rule22 evaluates #c.Number#0: =~"^(\\+|-)?\\d+(.\\d*)?"
+func (p *state) rule22(i int) step {
+	return p.terminal8(i)
+}
+
+// rule23 evaluates Token@84#0: #c.Identifier#0
+func (p *state) rule23(i int) step {
+	return p.at(i, 25)
+}
+
+// rule24 evaluates Token@66#0: #c.Identifier#0 Token@66#2
+func (p *state) rule24(i int) step {
+	current := i
+	if next := p.at(current, 25); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 10); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule25 evaluates #c.Identifier#0: =~"^[a-zA-Z][a-zA-Z0-9_]*"
+func (p *state) rule25(i int) step {
+	return p.terminal9(i)
+}
+
+// rule26 evaluates Token@48#0: #Keywords#0 Token@48#1
+func (p *state) rule26(i int) step {
+	current := i
+	if next := p.at(current, 27); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 12); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule27 evaluates #Keywords#0: "alignas" / "alignof" / "and" / "and_eq" / "asm" / "atomic_cancel" / "atomic_commit" / "atomic_noexcept" / "auto" / "bitand" / "bitor" / "bool" / "break" / "case" / "catch" / "char" / "char8_t" / "char16_t" / "char32_t" / "class" / "compl" / "concept" / "const" / "consteval" / "constexpr" / "constinit" / "const_cast" / "continue" / "co_await" / "co_return" / "co_yield" / "decltype" / "default" / "delete" / "do" / "double" / "dynamic_cast" / "else" / "enum" / "explicit" / "export" / "extern" / "false" / "float" / "for" / "friend" / "goto" / "if" / "inline" / "int" / "long" / "mutable" / "namespace" / "new" / "noexcept" / "not" / "not_eq" / "nullptr" / "operator" / "or" / "or_eq" / "private" / "protected" / "public" / "reflexpr" / "register" / "reinterpret_cast" / "requires" / "return" / "short" / "signed" / "sizeof" / "static" / "static_assert" / "static_cast" / "struct" / "switch" / "synchronized" / "template" / "this" / "thread_local" / "throw" / "true" / "try" / "typedef" / "typeid" / "typename" / "union" / "unsigned" / "using" / "virtual" / "void" / "volatile" / "wchar_t" / "while" / "xor" / "xor_eq"
+func (p *state) rule27(i int) step {
+	if next := p.terminal10(i); next.ok {
+		return next
+	}
+	if next := p.terminal11(i); next.ok {
+		return next
+	}
+	if next := p.terminal12(i); next.ok {
+		return next
+	}
+	if next := p.terminal13(i); next.ok {
+		return next
+	}
+	if next := p.terminal14(i); next.ok {
+		return next
+	}
+	if next := p.terminal15(i); next.ok {
+		return next
+	}
+	if next := p.terminal16(i); next.ok {
+		return next
+	}
+	if next := p.terminal17(i); next.ok {
+		return next
+	}
+	if next := p.terminal18(i); next.ok {
+		return next
+	}
+	if next := p.terminal19(i); next.ok {
+		return next
+	}
+	if next := p.terminal20(i); next.ok {
+		return next
+	}
+	if next := p.terminal21(i); next.ok {
+		return next
+	}
+	if next := p.terminal22(i); next.ok {
+		return next
+	}
+	if next := p.terminal23(i); next.ok {
+		return next
+	}
+	if next := p.terminal24(i); next.ok {
+		return next
+	}
+	if next := p.terminal25(i); next.ok {
+		return next
+	}
+	if next := p.terminal26(i); next.ok {
+		return next
+	}
+	if next := p.terminal27(i); next.ok {
+		return next
+	}
+	if next := p.terminal28(i); next.ok {
+		return next
+	}
+	if next := p.terminal29(i); next.ok {
+		return next
+	}
+	if next := p.terminal30(i); next.ok {
+		return next
+	}
+	if next := p.terminal31(i); next.ok {
+		return next
+	}
+	if next := p.terminal32(i); next.ok {
+		return next
+	}
+	if next := p.terminal33(i); next.ok {
+		return next
+	}
+	if next := p.terminal34(i); next.ok {
+		return next
+	}
+	if next := p.terminal35(i); next.ok {
+		return next
+	}
+	if next := p.terminal36(i); next.ok {
+		return next
+	}
+	if next := p.terminal37(i); next.ok {
+		return next
+	}
+	if next := p.terminal38(i); next.ok {
+		return next
+	}
+	if next := p.terminal39(i); next.ok {
+		return next
+	}
+	if next := p.terminal40(i); next.ok {
+		return next
+	}
+	if next := p.terminal41(i); next.ok {
+		return next
+	}
+	if next := p.terminal42(i); next.ok {
+		return next
+	}
+	if next := p.terminal43(i); next.ok {
+		return next
+	}
+	if next := p.terminal44(i); next.ok {
+		return next
+	}
+	if next := p.terminal45(i); next.ok {
+		return next
+	}
+	if next := p.terminal46(i); next.ok {
+		return next
+	}
+	if next := p.terminal47(i); next.ok {
+		return next
+	}
+	if next := p.terminal48(i); next.ok {
+		return next
+	}
+	if next := p.terminal49(i); next.ok {
+		return next
+	}
+	if next := p.terminal50(i); next.ok {
+		return next
+	}
+	if next := p.terminal51(i); next.ok {
+		return next
+	}
+	if next := p.terminal52(i); next.ok {
+		return next
+	}
+	if next := p.terminal53(i); next.ok {
+		return next
+	}
+	if next := p.terminal54(i); next.ok {
+		return next
+	}
+	if next := p.terminal55(i); next.ok {
+		return next
+	}
+	if next := p.terminal56(i); next.ok {
+		return next
+	}
+	if next := p.terminal57(i); next.ok {
+		return next
+	}
+	if next := p.terminal58(i); next.ok {
+		return next
+	}
+	if next := p.terminal59(i); next.ok {
+		return next
+	}
+	if next := p.terminal60(i); next.ok {
+		return next
+	}
+	if next := p.terminal61(i); next.ok {
+		return next
+	}
+	if next := p.terminal62(i); next.ok {
+		return next
+	}
+	if next := p.terminal63(i); next.ok {
+		return next
+	}
+	if next := p.terminal64(i); next.ok {
+		return next
+	}
+	if next := p.terminal65(i); next.ok {
+		return next
+	}
+	if next := p.terminal66(i); next.ok {
+		return next
+	}
+	if next := p.terminal67(i); next.ok {
+		return next
+	}
+	if next := p.terminal68(i); next.ok {
+		return next
+	}
+	if next := p.terminal69(i); next.ok {
+		return next
+	}
+	if next := p.terminal70(i); next.ok {
+		return next
+	}
+	if next := p.terminal71(i); next.ok {
+		return next
+	}
+	if next := p.terminal72(i); next.ok {
+		return next
+	}
+	if next := p.terminal73(i); next.ok {
+		return next
+	}
+	if next := p.terminal74(i); next.ok {
+		return next
+	}
+	if next := p.terminal75(i); next.ok {
+		return next
+	}
+	if next := p.terminal76(i); next.ok {
+		return next
+	}
+	if next := p.terminal77(i); next.ok {
+		return next
+	}
+	if next := p.terminal78(i); next.ok {
+		return next
+	}
+	if next := p.terminal79(i); next.ok {
+		return next
+	}
+	if next := p.terminal80(i); next.ok {
+		return next
+	}
+	if next := p.terminal81(i); next.ok {
+		return next
+	}
+	if next := p.terminal82(i); next.ok {
+		return next
+	}
+	if next := p.terminal83(i); next.ok {
+		return next
+	}
+	if next := p.terminal84(i); next.ok {
+		return next
+	}
+	if next := p.terminal85(i); next.ok {
+		return next
+	}
+	if next := p.terminal86(i); next.ok {
+		return next
+	}
+	if next := p.terminal87(i); next.ok {
+		return next
+	}
+	if next := p.terminal88(i); next.ok {
+		return next
+	}
+	if next := p.terminal89(i); next.ok {
+		return next
+	}
+	if next := p.terminal90(i); next.ok {
+		return next
+	}
+	if next := p.terminal91(i); next.ok {
+		return next
+	}
+	if next := p.terminal92(i); next.ok {
+		return next
+	}
+	if next := p.terminal93(i); next.ok {
+		return next
+	}
+	if next := p.terminal94(i); next.ok {
+		return next
+	}
+	if next := p.terminal95(i); next.ok {
+		return next
+	}
+	if next := p.terminal96(i); next.ok {
+		return next
+	}
+	if next := p.terminal97(i); next.ok {
+		return next
+	}
+	if next := p.terminal98(i); next.ok {
+		return next
+	}
+	if next := p.terminal99(i); next.ok {
+		return next
+	}
+	if next := p.terminal100(i); next.ok {
+		return next
+	}
+	if next := p.terminal101(i); next.ok {
+		return next
+	}
+	if next := p.terminal102(i); next.ok {
+		return next
+	}
+	if next := p.terminal103(i); next.ok {
+		return next
+	}
+	if next := p.terminal104(i); next.ok {
+		return next
+	}
+	if next := p.terminal105(i); next.ok {
+		return next
+	}
+	if next := p.terminal106(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule28 evaluates Token@31#0: "#" #c.Identifier#0
+func (p *state) rule28(i int) step {
+	current := i
+	if next := p.terminal107(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 25); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule29 evaluates Token@13#0: =~"^'(\\.|[^'\\\\])*'" / =~"^\"(\\.|[^\\\"\\\\])*\""
+func (p *state) rule29(i int) step {
+	if next := p.terminal108(i); next.ok {
+		return next
+	}
+	if next := p.terminal109(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// terminal0 matches "*/"
+func (p *state) terminal0(i int) step {
+	if bytes.HasPrefix(p.data[i:], token0) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal1 matches "\n"
+func (p *state) terminal1(i int) step {
+	if bytes.HasPrefix(p.data[i:], token1) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal2 matches "("
+func (p *state) terminal2(i int) step {
+	if bytes.HasPrefix(p.data[i:], token2) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal3 matches =~"^[a-zA-Z0-9_]+"
+func (p *state) terminal3(i int) step {
+	location := pattern3.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal4 matches .
+func (p *state) terminal4(i int) step {
+	current, size := utf8.DecodeRune(p.data[i:])
+	if size == 0 || (current == utf8.RuneError && size == 1) {
+		return step{}
+	}
+	return step{ok: true, advance: size}
+}
+
+// terminal5 matches @invalid-utf8
+func (p *state) terminal5(i int) step {
+	if current, size := utf8.DecodeRune(p.data[i:]); current == utf8.RuneError && size == 1 {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal6 matches "//"
+func (p *state) terminal6(i int) step {
+	if bytes.HasPrefix(p.data[i:], token6) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal7 matches "/*"
+func (p *state) terminal7(i int) step {
+	if bytes.HasPrefix(p.data[i:], token7) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal8 matches =~"^(\\+|-)?\\d+(.\\d*)?"
+func (p *state) terminal8(i int) step {
+	location := pattern8.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal9 matches =~"^[a-zA-Z][a-zA-Z0-9_]*"
+func (p *state) terminal9(i int) step {
+	location := pattern9.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal10 matches "alignas"
+func (p *state) terminal10(i int) step {
+	if bytes.HasPrefix(p.data[i:], token10) {
+		return step{ok: true, advance: 7}
+	}
+	return step{}
+}
+
+// terminal11 matches "alignof"
+func (p *state) terminal11(i int) step {
+	if bytes.HasPrefix(p.data[i:], token11) {
+		return step{ok: true, advance: 7}
+	}
+	return step{}
+}
+
+// terminal12 matches "and"
+func (p *state) terminal12(i int) step {
+	if bytes.HasPrefix(p.data[i:], token12) {
+		return step{ok: true, advance: 3}
+	}
+	return step{}
+}
+
+// terminal13 matches "and_eq"
+func (p *state) terminal13(i int) step {
+	if bytes.HasPrefix(p.data[i:], token13) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal14 matches "asm"
+func (p *state) terminal14(i int) step {
+	if bytes.HasPrefix(p.data[i:], token14) {
+		return step{ok: true, advance: 3}
+	}
+	return step{}
+}
+
+// terminal15 matches "atomic_cancel"
+func (p *state) terminal15(i int) step {
+	if bytes.HasPrefix(p.data[i:], token15) {
+		return step{ok: true, advance: 13}
+	}
+	return step{}
+}
+
+// terminal16 matches "atomic_commit"
+func (p *state) terminal16(i int) step {
+	if bytes.HasPrefix(p.data[i:], token16) {
+		return step{ok: true, advance: 13}
+	}
+	return step{}
+}
+
+// terminal17 matches "atomic_noexcept"
+func (p *state) terminal17(i int) step {
+	if bytes.HasPrefix(p.data[i:], token17) {
+		return step{ok: true, advance: 15}
+	}
+	return step{}
+}
+
+// terminal18 matches "auto"
+func (p *state) terminal18(i int) step {
+	if bytes.HasPrefix(p.data[i:], token18) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal19 matches "bitand"
+func (p *state) terminal19(i int) step {
+	if bytes.HasPrefix(p.data[i:], token19) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal20 matches "bitor"
+func (p *state) terminal20(i int) step {
+	if bytes.HasPrefix(p.data[i:], token20) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal21 matches "bool"
+func (p *state) terminal21(i int) step {
+	if bytes.HasPrefix(p.data[i:], token21) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal22 matches "break"
+func (p *state) terminal22(i int) step {
+	if bytes.HasPrefix(p.data[i:], token22) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal23 matches "case"
+func (p *state) terminal23(i int) step {
+	if bytes.HasPrefix(p.data[i:], token23) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal24 matches "catch"
+func (p *state) terminal24(i int) step {
+	if bytes.HasPrefix(p.data[i:], token24) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal25 matches "char"
+func (p *state) terminal25(i int) step {
+	if bytes.HasPrefix(p.data[i:], token25) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal26 matches "char8_t"
+func (p *state) terminal26(i int) step {
+	if bytes.HasPrefix(p.data[i:], token26) {
+		return step{ok: true, advance: 7}
+	}
+	return step{}
+}
+
+// terminal27 matches "char16_t"
+func (p *state) terminal27(i int) step {
+	if bytes.HasPrefix(p.data[i:], token27) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal28 matches "char32_t"
+func (p *state) terminal28(i int) step {
+	if bytes.HasPrefix(p.data[i:], token28) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal29 matches "class"
+func (p *state) terminal29(i int) step {
+	if bytes.HasPrefix(p.data[i:], token29) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal30 matches "compl"
+func (p *state) terminal30(i int) step {
+	if bytes.HasPrefix(p.data[i:], token30) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal31 matches "concept"
+func (p *state) terminal31(i int) step {
+	if bytes.HasPrefix(p.data[i:], token31) {
+		return step{ok: true, advance: 7}
+	}
+	return step{}
+}
+
+// terminal32 matches "const"
+func (p *state) terminal32(i int) step {
+	if bytes.HasPrefix(p.data[i:], token32) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal33 matches "consteval"
+func (p *state) terminal33(i int) step {
+	if bytes.HasPrefix(p.data[i:], token33) {
+		return step{ok: true, advance: 9}
+	}
+	return step{}
+}
+
+// terminal34 matches "constexpr"
+func (p *state) terminal34(i int) step {
+	if bytes.HasPrefix(p.data[i:], token34) {
+		return step{ok: true, advance: 9}
+	}
+	return step{}
+}
+
+// terminal35 matches "constinit"
+func (p *state) terminal35(i int) step {
+	if bytes.HasPrefix(p.data[i:], token35) {
+		return step{ok: true, advance: 9}
+	}
+	return step{}
+}
+
+// terminal36 matches "const_cast"
+func (p *state) terminal36(i int) step {
+	if bytes.HasPrefix(p.data[i:], token36) {
+		return step{ok: true, advance: 10}
+	}
+	return step{}
+}
+
+// terminal37 matches "continue"
+func (p *state) terminal37(i int) step {
+	if bytes.HasPrefix(p.data[i:], token37) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal38 matches "co_await"
+func (p *state) terminal38(i int) step {
+	if bytes.HasPrefix(p.data[i:], token38) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal39 matches "co_return"
+func (p *state) terminal39(i int) step {
+	if bytes.HasPrefix(p.data[i:], token39) {
+		return step{ok: true, advance: 9}
+	}
+	return step{}
+}
+
+// terminal40 matches "co_yield"
+func (p *state) terminal40(i int) step {
+	if bytes.HasPrefix(p.data[i:], token40) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal41 matches "decltype"
+func (p *state) terminal41(i int) step {
+	if bytes.HasPrefix(p.data[i:], token41) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal42 matches "default"
+func (p *state) terminal42(i int) step {
+	if bytes.HasPrefix(p.data[i:], token42) {
+		return step{ok: true, advance: 7}
+	}
+	return step{}
+}
+
+// terminal43 matches "delete"
+func (p *state) terminal43(i int) step {
+	if bytes.HasPrefix(p.data[i:], token43) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal44 matches "do"
+func (p *state) terminal44(i int) step {
+	if bytes.HasPrefix(p.data[i:], token44) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal45 matches "double"
+func (p *state) terminal45(i int) step {
+	if bytes.HasPrefix(p.data[i:], token45) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal46 matches "dynamic_cast"
+func (p *state) terminal46(i int) step {
+	if bytes.HasPrefix(p.data[i:], token46) {
+		return step{ok: true, advance: 12}
+	}
+	return step{}
+}
+
+// terminal47 matches "else"
+func (p *state) terminal47(i int) step {
+	if bytes.HasPrefix(p.data[i:], token47) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal48 matches "enum"
+func (p *state) terminal48(i int) step {
+	if bytes.HasPrefix(p.data[i:], token48) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal49 matches "explicit"
+func (p *state) terminal49(i int) step {
+	if bytes.HasPrefix(p.data[i:], token49) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal50 matches "export"
+func (p *state) terminal50(i int) step {
+	if bytes.HasPrefix(p.data[i:], token50) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal51 matches "extern"
+func (p *state) terminal51(i int) step {
+	if bytes.HasPrefix(p.data[i:], token51) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal52 matches "false"
+func (p *state) terminal52(i int) step {
+	if bytes.HasPrefix(p.data[i:], token52) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal53 matches "float"
+func (p *state) terminal53(i int) step {
+	if bytes.HasPrefix(p.data[i:], token53) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal54 matches "for"
+func (p *state) terminal54(i int) step {
+	if bytes.HasPrefix(p.data[i:], token54) {
+		return step{ok: true, advance: 3}
+	}
+	return step{}
+}
+
+// terminal55 matches "friend"
+func (p *state) terminal55(i int) step {
+	if bytes.HasPrefix(p.data[i:], token55) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal56 matches "goto"
+func (p *state) terminal56(i int) step {
+	if bytes.HasPrefix(p.data[i:], token56) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal57 matches "if"
+func (p *state) terminal57(i int) step {
+	if bytes.HasPrefix(p.data[i:], token57) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal58 matches "inline"
+func (p *state) terminal58(i int) step {
+	if bytes.HasPrefix(p.data[i:], token58) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal59 matches "int"
+func (p *state) terminal59(i int) step {
+	if bytes.HasPrefix(p.data[i:], token59) {
+		return step{ok: true, advance: 3}
+	}
+	return step{}
+}
+
+// terminal60 matches "long"
+func (p *state) terminal60(i int) step {
+	if bytes.HasPrefix(p.data[i:], token60) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal61 matches "mutable"
+func (p *state) terminal61(i int) step {
+	if bytes.HasPrefix(p.data[i:], token61) {
+		return step{ok: true, advance: 7}
+	}
+	return step{}
+}
+
+// terminal62 matches "namespace"
+func (p *state) terminal62(i int) step {
+	if bytes.HasPrefix(p.data[i:], token62) {
+		return step{ok: true, advance: 9}
+	}
+	return step{}
+}
+
+// terminal63 matches "new"
+func (p *state) terminal63(i int) step {
+	if bytes.HasPrefix(p.data[i:], token63) {
+		return step{ok: true, advance: 3}
+	}
+	return step{}
+}
+
+// terminal64 matches "noexcept"
+func (p *state) terminal64(i int) step {
+	if bytes.HasPrefix(p.data[i:], token64) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal65 matches "not"
+func (p *state) terminal65(i int) step {
+	if bytes.HasPrefix(p.data[i:], token65) {
+		return step{ok: true, advance: 3}
+	}
+	return step{}
+}
+
+// terminal66 matches "not_eq"
+func (p *state) terminal66(i int) step {
+	if bytes.HasPrefix(p.data[i:], token66) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal67 matches "nullptr"
+func (p *state) terminal67(i int) step {
+	if bytes.HasPrefix(p.data[i:], token67) {
+		return step{ok: true, advance: 7}
+	}
+	return step{}
+}
+
+// terminal68 matches "operator"
+func (p *state) terminal68(i int) step {
+	if bytes.HasPrefix(p.data[i:], token68) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal69 matches "or"
+func (p *state) terminal69(i int) step {
+	if bytes.HasPrefix(p.data[i:], token69) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal70 matches "or_eq"
+func (p *state) terminal70(i int) step {
+	if bytes.HasPrefix(p.data[i:], token70) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal71 matches "private"
+func (p *state) terminal71(i int) step {
+	if bytes.HasPrefix(p.data[i:], token71) {
+		return step{ok: true, advance: 7}
+	}
+	return step{}
+}
+
+// terminal72 matches "protected"
+func (p *state) terminal72(i int) step {
+	if bytes.HasPrefix(p.data[i:], token72) {
+		return step{ok: true, advance: 9}
+	}
+	return step{}
+}
+
+// terminal73 matches "public"
+func (p *state) terminal73(i int) step {
+	if bytes.HasPrefix(p.data[i:], token73) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal74 matches "reflexpr"
+func (p *state) terminal74(i int) step {
+	if bytes.HasPrefix(p.data[i:], token74) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal75 matches "register"
+func (p *state) terminal75(i int) step {
+	if bytes.HasPrefix(p.data[i:], token75) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal76 matches "reinterpret_cast"
+func (p *state) terminal76(i int) step {
+	if bytes.HasPrefix(p.data[i:], token76) {
+		return step{ok: true, advance: 16}
+	}
+	return step{}
+}
+
+// terminal77 matches "requires"
+func (p *state) terminal77(i int) step {
+	if bytes.HasPrefix(p.data[i:], token77) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal78 matches "return"
+func (p *state) terminal78(i int) step {
+	if bytes.HasPrefix(p.data[i:], token78) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal79 matches "short"
+func (p *state) terminal79(i int) step {
+	if bytes.HasPrefix(p.data[i:], token79) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal80 matches "signed"
+func (p *state) terminal80(i int) step {
+	if bytes.HasPrefix(p.data[i:], token80) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal81 matches "sizeof"
+func (p *state) terminal81(i int) step {
+	if bytes.HasPrefix(p.data[i:], token81) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal82 matches "static"
+func (p *state) terminal82(i int) step {
+	if bytes.HasPrefix(p.data[i:], token82) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal83 matches "static_assert"
+func (p *state) terminal83(i int) step {
+	if bytes.HasPrefix(p.data[i:], token83) {
+		return step{ok: true, advance: 13}
+	}
+	return step{}
+}
+
+// terminal84 matches "static_cast"
+func (p *state) terminal84(i int) step {
+	if bytes.HasPrefix(p.data[i:], token84) {
+		return step{ok: true, advance: 11}
+	}
+	return step{}
+}
+
+// terminal85 matches "struct"
+func (p *state) terminal85(i int) step {
+	if bytes.HasPrefix(p.data[i:], token85) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal86 matches "switch"
+func (p *state) terminal86(i int) step {
+	if bytes.HasPrefix(p.data[i:], token86) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal87 matches "synchronized"
+func (p *state) terminal87(i int) step {
+	if bytes.HasPrefix(p.data[i:], token87) {
+		return step{ok: true, advance: 12}
+	}
+	return step{}
+}
+
+// terminal88 matches "template"
+func (p *state) terminal88(i int) step {
+	if bytes.HasPrefix(p.data[i:], token88) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal89 matches "this"
+func (p *state) terminal89(i int) step {
+	if bytes.HasPrefix(p.data[i:], token89) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal90 matches "thread_local"
+func (p *state) terminal90(i int) step {
+	if bytes.HasPrefix(p.data[i:], token90) {
+		return step{ok: true, advance: 12}
+	}
+	return step{}
+}
+
+// terminal91 matches "throw"
+func (p *state) terminal91(i int) step {
+	if bytes.HasPrefix(p.data[i:], token91) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal92 matches "true"
+func (p *state) terminal92(i int) step {
+	if bytes.HasPrefix(p.data[i:], token92) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal93 matches "try"
+func (p *state) terminal93(i int) step {
+	if bytes.HasPrefix(p.data[i:], token93) {
+		return step{ok: true, advance: 3}
+	}
+	return step{}
+}
+
+// terminal94 matches "typedef"
+func (p *state) terminal94(i int) step {
+	if bytes.HasPrefix(p.data[i:], token94) {
+		return step{ok: true, advance: 7}
+	}
+	return step{}
+}
+
+// terminal95 matches "typeid"
+func (p *state) terminal95(i int) step {
+	if bytes.HasPrefix(p.data[i:], token95) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal96 matches "typename"
+func (p *state) terminal96(i int) step {
+	if bytes.HasPrefix(p.data[i:], token96) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal97 matches "union"
+func (p *state) terminal97(i int) step {
+	if bytes.HasPrefix(p.data[i:], token97) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal98 matches "unsigned"
+func (p *state) terminal98(i int) step {
+	if bytes.HasPrefix(p.data[i:], token98) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal99 matches "using"
+func (p *state) terminal99(i int) step {
+	if bytes.HasPrefix(p.data[i:], token99) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal100 matches "virtual"
+func (p *state) terminal100(i int) step {
+	if bytes.HasPrefix(p.data[i:], token100) {
+		return step{ok: true, advance: 7}
+	}
+	return step{}
+}
+
+// terminal101 matches "void"
+func (p *state) terminal101(i int) step {
+	if bytes.HasPrefix(p.data[i:], token101) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal102 matches "volatile"
+func (p *state) terminal102(i int) step {
+	if bytes.HasPrefix(p.data[i:], token102) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal103 matches "wchar_t"
+func (p *state) terminal103(i int) step {
+	if bytes.HasPrefix(p.data[i:], token103) {
+		return step{ok: true, advance: 7}
+	}
+	return step{}
+}
+
+// terminal104 matches "while"
+func (p *state) terminal104(i int) step {
+	if bytes.HasPrefix(p.data[i:], token104) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal105 matches "xor"
+func (p *state) terminal105(i int) step {
+	if bytes.HasPrefix(p.data[i:], token105) {
+		return step{ok: true, advance: 3}
+	}
+	return step{}
+}
+
+// terminal106 matches "xor_eq"
+func (p *state) terminal106(i int) step {
+	if bytes.HasPrefix(p.data[i:], token106) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal107 matches "#"
+func (p *state) terminal107(i int) step {
+	if bytes.HasPrefix(p.data[i:], token107) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal108 matches =~"^'(\\.|[^'\\\\])*'"
+func (p *state) terminal108(i int) step {
+	location := pattern108.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal109 matches =~"^\"(\\.|[^\\\"\\\\])*\""
+func (p *state) terminal109(i int) step {
+	location := pattern109.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+func (p *state) derive(parent frame, i int, l leaf) (step, int) {
+	if l.rule < 0 {
+		return p.terminal(i, l.terminal), 0
+	}
+	if !recursive[l.rule] {
+		return p.at(i, l.rule), 0
+	}
+	limit := math.MaxInt
+	if i == parent.node.Segment.Start && component[l.rule] == component[parent.rule] {
+		limit = parent.seq
+	}
+	p.at(i, l.rule)
+	return p.versionBefore(i, l.rule, limit)
+}
+
+func (p *state) build(rootStep step) *parser.ParsingNode {
+	rootNode := parser.NewParsingNode[byte](names[root], nil, p.data, definition.Segment{Start: 0, End: rootStep.advance})
+	rootFrame := frame{node: &rootNode, rule: root, seq: math.MaxInt}
+	if recursive[root] {
+		_, rootFrame.seq = p.versionBefore(0, root, math.MaxInt)
+	}
+	derivation := []frame{rootFrame}
+	for k := 0; k < len(derivation); k++ {
+		current := derivation[k]
+		addChild := func(l leaf, segment definition.Segment, seq int) {
+			if l.rule < 0 {
+				return
+			}
+			next := parser.NewParsingNode[byte](l.name, l.attributes, p.data, segment)
+			current.node.Children = append(current.node.Children, &next)
+			derivation = append(derivation, frame{node: &next, rule: l.rule, seq: seq})
+		}
+		start := current.node.Segment.Start
+		rule := shapes[current.rule]
+		switch rule.kind {
+		case kindSymbol:
+			next, seq := p.derive(current, start, rule.leaves[0])
+			if next.ok {
+				addChild(rule.leaves[0], current.node.Segment, seq)
+			}
+		case kindKleene:
+			for position := start; ; {
+				next, seq := p.derive(current, position, rule.leaves[0])
+				if !next.ok || next.advance == 0 {
+					break
+				}
+				addChild(rule.leaves[0], definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindJunction:
+			position := start
+			for _, l := range rule.leaves {
+				next, seq := p.derive(current, position, l)
+				addChild(l, definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindChoice:
+			for _, l := range rule.leaves {
+				if next, seq := p.derive(current, start, l); next.ok {
+					addChild(l, definition.Segment{Start: start, End: start + next.advance}, seq)
+					break
+				}
+			}
+		}
+	}
+	return &rootNode
+}
+
+func transform(node *parser.ParsingNode) []*parser.ParsingNode {
+	name, ok := mapping[node.Atom.Symbol]
+	symbol, hidden := definition.AnalyzeSymbolName(name)
+	shape := nodeShapes[name]
+	if !ok || hidden || shape.inline {
+		nodes := make([]*parser.ParsingNode, 0, len(node.Children))
+		for _, child := range node.Children {
+			nodes = append(nodes, transform(child)...)
+		}
+		return nodes
+	}
+	if shape.drop {
+		return nil
+	}
+	atom := node.Atom
+	atom.Symbol = symbol
+	if shape.rename != "" {
+		atom.Symbol = shape.rename
+	}
+	next := parser.ParsingNode{Atom: atom, Segment: node.Segment}
+	if shape.token {
+		return []*parser.ParsingNode{&next}
+	}
+	for _, child := range node.Children {
+		for _, transformed := range transform(child) {
+			if shape.flatten && transformed.Atom.Symbol == next.Atom.Symbol {
+				next.Children = append(next.Children, transformed.Children...)
+				continue
+			}
+			next.Children = append(next.Children, transformed)
+		}
+	}
+	if shape.collapse && len(next.Children) == 1 {
+		return next.Children
+	}
+	return []*parser.ParsingNode{&next}
+}
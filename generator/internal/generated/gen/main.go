@@ -0,0 +1,29 @@
+package main
+
+import (
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/sivukhin/gopeg/generator"
+	"github.com/sivukhin/gopeg/generator/internal/generated"
+)
+
+func main() {
+	for _, grammar := range generated.Grammars() {
+		opts := []generator.Option{generator.WithPackage(grammar.Package), generator.WithRoot(grammar.Root)}
+		if grammar.UTF8 {
+			opts = append(opts, generator.WithUTF8())
+		}
+		source, err := generator.Generate(grammar.Rules, opts...)
+		if err != nil {
+			log.Fatalf("unable to generate %v: %v", grammar.Package, err)
+		}
+		if err := os.MkdirAll(grammar.Package, 0o755); err != nil {
+			log.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(grammar.Package, "parser.go"), source, 0o644); err != nil {
+			log.Fatal(err)
+		}
+	}
+}
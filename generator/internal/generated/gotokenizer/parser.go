@@ -0,0 +1,1164 @@
+// Code generated by gopeg generate; DO NOT EDIT.
+
+package gotokenizer
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+	"unicode/utf8"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/parser"
+)
+
+type (
+	cell struct{ position, rule int }
+	step struct {
+		ok      bool
+		advance int
+	}
+	version struct {
+		seq  int
+		step step
+	}
+	leaf struct {
+		rule       int
+		terminal   int
+		name       string
+		attributes map[string][]byte
+	}
+	shape struct {
+		kind   int
+		leaves []leaf
+	}
+	frame struct {
+		node *parser.ParsingNode
+		rule int
+		seq  int
+	}
+	nodeShape struct {
+		inline   bool
+		flatten  bool
+		collapse bool
+		token    bool
+		drop     bool
+		rename   string
+	}
+	state struct {
+		data     []byte
+		memo     map[cell]step
+		seq      int
+		versions map[cell][]version
+	}
+)
+
+const (
+	kindTerminal = iota
+	kindSymbol
+	kindJunction
+	kindChoice
+	kindKleene
+	kindNegation
+)
+
+const root = 13
+
+var (
+	token0    = []byte("*/")
+	token1    = []byte("\n")
+	token2    = []byte("(")
+	pattern3  = regexp.MustCompile("^[a-zA-Z0-9_]+")
+	token6    = []byte("//")
+	token7    = []byte("/*")
+	pattern8  = regexp.MustCompile("^(\\+|-)?\\d+(.\\d*)?")
+	pattern9  = regexp.MustCompile("^[a-zA-Z][a-zA-Z0-9_]*")
+	token10   = []byte("break")
+	token11   = []byte("default")
+	token12   = []byte("func")
+	token13   = []byte("interface")
+	token14   = []byte("select")
+	token15   = []byte("case")
+	token16   = []byte("defer")
+	token17   = []byte("go")
+	token18   = []byte("map")
+	token19   = []byte("struct")
+	token20   = []byte("chan")
+	token21   = []byte("else")
+	token22   = []byte("goto")
+	token23   = []byte("package")
+	token24   = []byte("switch")
+	token25   = []byte("const")
+	token26   = []byte("fallthrough")
+	token27   = []byte("if")
+	token28   = []byte("range")
+	token29   = []byte("type")
+	token30   = []byte("continue")
+	token31   = []byte("for")
+	token32   = []byte("import")
+	token33   = []byte("return")
+	token34   = []byte("var")
+	pattern35 = regexp.MustCompile("^'(\\.|[^'\\\\])*'")
+	pattern36 = regexp.MustCompile("^\"(\\.|[^\\\"\\\\])*\"")
+)
+
+var (
+	names      = []string{"#c.SlashComment#9", "#c.SlashComment#8", "#c.SlashComment#7", "#c.SlashComment#6", "#c.SlashComment#5", "#c.SlashComment#3", "#c.SlashComment#2", "#c.SlashComment#1", "#c.EndOfLine#0", "#c.EndOfLine#1", "Token@49#2", "Token@49#1", "Token@31#1", "Source#0", "#Sequence#0", "None@109#0", "#c.Any#0", "Token@95#0", "#c.SlashComment#0", "#c.SlashComment#10", "#c.SlashComment#4", "Token@81#0", "#c.Number#0", "Token@67#0", "Token@49#0", "#c.Identifier#0", "Token@31#0", "#Keywords#0", "Token@13#0"}
+	recursive  = []bool{false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false}
+	component  = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28}
+	components = [][]int{{0}, {1}, {2}, {3}, {4}, {5}, {6}, {7}, {8}, {9}, {10}, {11}, {12}, {13}, {14}, {15}, {16}, {17}, {18}, {19}, {20}, {21}, {22}, {23}, {24}, {25}, {26}, {27}, {28}}
+	shapes     = []shape{
+		{kind: kindNegation, leaves: []leaf{{rule: 1, name: "#c.SlashComment#8", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 8, name: "#c.EndOfLine#0", attributes: nil}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 3, name: "#c.SlashComment#6", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 4, name: "#c.SlashComment#5", attributes: nil}, {rule: 16, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 8, name: "#c.EndOfLine#0", attributes: nil}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 6, name: "#c.SlashComment#2", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 7, name: "#c.SlashComment#1", attributes: nil}, {rule: 16, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: -1, terminal: 0}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 1}, {rule: 9, name: "#c.EndOfLine#1", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 16, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: 11, name: "Token@49#1", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: -1, terminal: 2}}},
+		{kind: kindNegation, leaves: []leaf{{rule: -1, terminal: 3}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 14, name: "#Sequence#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 28, name: "Token@13#0", attributes: map[string][]byte{"class": []byte("string"), "tag": []byte("span")}}, {rule: 26, name: "Token@31#0", attributes: map[string][]byte{"class": []byte("keyword"), "tag": []byte("span")}}, {rule: 24, name: "Token@49#0", attributes: map[string][]byte{"class": []byte("function"), "tag": []byte("span")}}, {rule: 23, name: "Token@67#0", attributes: map[string][]byte{"class": []byte("identifier"), "tag": []byte("span")}}, {rule: 21, name: "Token@81#0", attributes: map[string][]byte{"class": []byte("number"), "tag": []byte("span")}}, {rule: 17, name: "Token@95#0", attributes: map[string][]byte{"class": []byte("comment"), "tag": []byte("span")}}, {rule: 15, name: "None@109#0", attributes: nil}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 16, name: "#c.Any#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 4}, {rule: -1, terminal: 5}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 18, name: "#c.SlashComment#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 20, name: "#c.SlashComment#4", attributes: nil}, {rule: 19, name: "#c.SlashComment#10", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 6}, {rule: 2, name: "#c.SlashComment#7", attributes: nil}, {rule: 0, name: "#c.SlashComment#9", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 7}, {rule: 5, name: "#c.SlashComment#3", attributes: nil}, {rule: -1, terminal: 0}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 22, name: "#c.Number#0", attributes: nil}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 8}}},
+		{kind: kindSymbol, leaves: []leaf{{rule: 25, name: "#c.Identifier#0", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 25, name: "#c.Identifier#0", attributes: nil}, {rule: 10, name: "Token@49#2", attributes: nil}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 9}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 27, name: "#Keywords#0", attributes: nil}, {rule: 12, name: "Token@31#1", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 10}, {rule: -1, terminal: 11}, {rule: -1, terminal: 12}, {rule: -1, terminal: 13}, {rule: -1, terminal: 14}, {rule: -1, terminal: 15}, {rule: -1, terminal: 16}, {rule: -1, terminal: 17}, {rule: -1, terminal: 18}, {rule: -1, terminal: 19}, {rule: -1, terminal: 20}, {rule: -1, terminal: 21}, {rule: -1, terminal: 22}, {rule: -1, terminal: 23}, {rule: -1, terminal: 24}, {rule: -1, terminal: 25}, {rule: -1, terminal: 26}, {rule: -1, terminal: 27}, {rule: -1, terminal: 28}, {rule: -1, terminal: 29}, {rule: -1, terminal: 30}, {rule: -1, terminal: 31}, {rule: -1, terminal: 32}, {rule: -1, terminal: 33}, {rule: -1, terminal: 34}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 35}, {rule: -1, terminal: 36}}},
+	}
+	mapping = map[string]string{
+		"#Keywords#0":       "#Keywords",
+		"#Sequence#0":       "#Sequence",
+		"#c.Any#0":          "#c.Any",
+		"#c.EndOfLine#0":    "#c.EndOfLine",
+		"#c.Identifier#0":   "#c.Identifier",
+		"#c.Number#0":       "#c.Number",
+		"#c.SlashComment#0": "#c.SlashComment",
+		"None@109#0":        "None@109",
+		"Source#0":          "Source",
+		"Token@13#0":        "Token@13",
+		"Token@31#0":        "Token@31",
+		"Token@49#0":        "Token@49",
+		"Token@67#0":        "Token@67",
+		"Token@81#0":        "Token@81",
+		"Token@95#0":        "Token@95",
+	}
+	nodeShapes = map[string]nodeShape{}
+)
+
+// Parse matches the longest prefix of the data with Source rule
+func Parse(data []byte) (*parser.ParsingNode, error) { return parse(data, false) }
+
+// ParseFull matches the whole data with Source rule
+func ParseFull(data []byte) (*parser.ParsingNode, error) { return parse(data, true) }
+
+func parse(data []byte, full bool) (*parser.ParsingNode, error) {
+	p := &state{data: data, memo: make(map[cell]step), versions: make(map[cell][]version)}
+	rootStep := p.at(0, root)
+	if !rootStep.ok {
+		return nil, fmt.Errorf("%w: rule Source doesn't match", parser.TextNotMatchErr)
+	}
+	if full && rootStep.advance != len(data) {
+		return nil, fmt.Errorf("%w: rule Source matched only first %v elements out of %v", parser.TextNotMatchErr, rootStep.advance, len(data))
+	}
+	nodes := transform(p.build(rootStep))
+	if len(nodes) != 1 {
+		return nil, fmt.Errorf("tree with multiple root was formed")
+	}
+	return nodes[0], nil
+}
+
+func (p *state) at(i, s int) step {
+	key := cell{position: i, rule: s}
+	if result, ok := p.memo[key]; ok {
+		return result
+	}
+	if !recursive[s] {
+		result := p.evaluate(i, s)
+		p.memo[key] = result
+		return result
+	}
+	group := components[component[s]]
+	for _, r := range group {
+		p.memo[cell{position: i, rule: r}] = step{}
+		p.versions[cell{position: i, rule: r}] = []version{{seq: -1}}
+	}
+	for grown := true; grown; {
+		grown = false
+		for _, r := range group {
+			p.seq++
+			seq := p.seq
+			next := p.evaluate(i, r)
+			if current := p.memo[cell{position: i, rule: r}]; next.ok && (!current.ok || next.advance > current.advance) {
+				p.memo[cell{position: i, rule: r}] = next
+				p.versions[cell{position: i, rule: r}] = append(p.versions[cell{position: i, rule: r}], version{seq: seq, step: next})
+				grown = true
+			}
+		}
+	}
+	return p.memo[key]
+}
+
+func (p *state) versionBefore(i, s, seq int) (step, int) {
+	versions := p.versions[cell{position: i, rule: s}]
+	for k := len(versions) - 1; k >= 0; k-- {
+		if versions[k].seq < seq {
+			return versions[k].step, versions[k].seq
+		}
+	}
+	panic(fmt.Errorf("left-recursive cell %v has no versions before %v", cell{position: i, rule: s}, seq))
+}
+
+func (p *state) evaluate(i, s int) step {
+	switch s {
+	case 0:
+		return p.rule0(i)
+	case 1:
+		return p.rule1(i)
+	case 2:
+		return p.rule2(i)
+	case 3:
+		return p.rule3(i)
+	case 4:
+		return p.rule4(i)
+	case 5:
+		return p.rule5(i)
+	case 6:
+		return p.rule6(i)
+	case 7:
+		return p.rule7(i)
+	case 8:
+		return p.rule8(i)
+	case 9:
+		return p.rule9(i)
+	case 10:
+		return p.rule10(i)
+	case 11:
+		return p.rule11(i)
+	case 12:
+		return p.rule12(i)
+	case 13:
+		return p.rule13(i)
+	case 14:
+		return p.rule14(i)
+	case 15:
+		return p.rule15(i)
+	case 16:
+		return p.rule16(i)
+	case 17:
+		return p.rule17(i)
+	case 18:
+		return p.rule18(i)
+	case 19:
+		return p.rule19(i)
+	case 20:
+		return p.rule20(i)
+	case 21:
+		return p.rule21(i)
+	case 22:
+		return p.rule22(i)
+	case 23:
+		return p.rule23(i)
+	case 24:
+		return p.rule24(i)
+	case 25:
+		return p.rule25(i)
+	case 26:
+		return p.rule26(i)
+	case 27:
+		return p.rule27(i)
+	case 28:
+		return p.rule28(i)
+	}
+	panic(fmt.Errorf("unexpected rule: %v", s))
+}
+
+func (p *state) terminal(i, k int) step {
+	switch k {
+	case 0:
+		return p.terminal0(i)
+	case 1:
+		return p.terminal1(i)
+	case 2:
+		return p.terminal2(i)
+	case 3:
+		return p.terminal3(i)
+	case 4:
+		return p.terminal4(i)
+	case 5:
+		return p.terminal5(i)
+	case 6:
+		return p.terminal6(i)
+	case 7:
+		return p.terminal7(i)
+	case 8:
+		return p.terminal8(i)
+	case 9:
+		return p.terminal9(i)
+	case 10:
+		return p.terminal10(i)
+	case 11:
+		return p.terminal11(i)
+	case 12:
+		return p.terminal12(i)
+	case 13:
+		return p.terminal13(i)
+	case 14:
+		return p.terminal14(i)
+	case 15:
+		return p.terminal15(i)
+	case 16:
+		return p.terminal16(i)
+	case 17:
+		return p.terminal17(i)
+	case 18:
+		return p.terminal18(i)
+	case 19:
+		return p.terminal19(i)
+	case 20:
+		return p.terminal20(i)
+	case 21:
+		return p.terminal21(i)
+	case 22:
+		return p.terminal22(i)
+	case 23:
+		return p.terminal23(i)
+	case 24:
+		return p.terminal24(i)
+	case 25:
+		return p.terminal25(i)
+	case 26:
+		return p.terminal26(i)
+	case 27:
+		return p.terminal27(i)
+	case 28:
+		return p.terminal28(i)
+	case 29:
+		return p.terminal29(i)
+	case 30:
+		return p.terminal30(i)
+	case 31:
+		return p.terminal31(i)
+	case 32:
+		return p.terminal32(i)
+	case 33:
+		return p.terminal33(i)
+	case 34:
+		return p.terminal34(i)
+	case 35:
+		return p.terminal35(i)
+	case 36:
+		return p.terminal36(i)
+	}
+	panic(fmt.Errorf("unexpected terminal: %v", k))
+}
+
+// rule0 evaluates #c.SlashComment#9: !#c.SlashComment#8
+func (p *state) rule0(i int) step {
+	if p.at(i, 1).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule1 evaluates #c.SlashComment#8: !#c.EndOfLine#0
+func (p *state) rule1(i int) step {
+	if p.at(i, 8).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule2 evaluates #c.SlashComment#7: #c.SlashComment#6*
+func (p *state) rule2(i int) step {
+	current := i
+	for {
+		next := p.at(current, 3)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule3 evaluates #c.SlashComment#6: #c.SlashComment#5 #c.Any#0
+func (p *state) rule3(i int) step {
+	current := i
+	if next := p.at(current, 4); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 16); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule4 evaluates #c.SlashComment#5: !#c.EndOfLine#0
+func (p *state) rule4(i int) step {
+	if p.at(i, 8).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule5 evaluates #c.SlashComment#3: #c.SlashComment#2*
+func (p *state) rule5(i int) step {
+	current := i
+	for {
+		next := p.at(current, 6)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule6 evaluates #c.SlashComment#2: #c.SlashComment#1 #c.Any#0
+func (p *state) rule6(i int) step {
+	current := i
+	if next := p.at(current, 7); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 16); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule7 evaluates #c.SlashComment#1: !"*/"
+func (p *state) rule7(i int) step {
+	if p.terminal0(i).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule8 evaluates #c.EndOfLine#0: "\n" / #c.EndOfLine#1
+func (p *state) rule8(i int) step {
+	if next := p.terminal1(i); next.ok {
+		return next
+	}
+	if next := p.at(i, 9); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule9 evaluates #c.EndOfLine#1: !#c.Any#0
+func (p *state) rule9(i int) step {
+	if p.at(i, 16).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule10 evaluates Token@49#2: !Token@49#1
+func (p *state) rule10(i int) step {
+	if p.at(i, 11).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule11 evaluates Token@49#1: !"("
+func (p *state) rule11(i int) step {
+	if p.terminal2(i).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule12 evaluates Token@31#1: !=~"^[a-zA-Z0-9_]+"
+func (p *state) rule12(i int) step {
+	if p.terminal3(i).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule13 evaluates Source#0: #Sequence#0*
+func (p *state) rule13(i int) step {
+	current := i
+	for {
+		next := p.at(current, 14)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule14 evaluates #Sequence#0: {class:"string", tag:"span"}:Token@13#0 / {class:"keyword", tag:"span"}:Token@31#0 / {class:"function", tag:"span"}:Token@49#0 / {class:"identifier", tag:"span"}:Token@67#0 / {class:"number", tag:"span"}:Token@81#0 / {class:"comment", tag:"span"}:Token@95#0 / None@109#0
+func (p *state) rule14(i int) step {
+	if next := p.at(i, 28); next.ok {
+		return next
+	}
+	if next := p.at(i, 26); next.ok {
+		return next
+	}
+	if next := p.at(i, 24); next.ok {
+		return next
+	}
+	if next := p.at(i, 23); next.ok {
+		return next
+	}
+	if next := p.at(i, 21); next.ok {
+		return next
+	}
+	if next := p.at(i, 17); next.ok {
+		return next
+	}
+	if next := p.at(i, 15); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule15 evaluates None@109#0: #c.Any#0
+func (p *state) rule15(i int) step {
+	return p.at(i, 16)
+}
+
+// rule16 evaluates #c.Any#0: . / @invalid-utf8
+func (p *state) rule16(i int) step {
+	if next := p.terminal4(i); next.ok {
+		return next
+	}
+	if next := p.terminal5(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule17 evaluates Token@95#0: #c.SlashComment#0
+func (p *state) rule17(i int) step {
+	return p.at(i, 18)
+}
+
+// rule18 evaluates #c.SlashComment#0: #c.SlashComment#4 / #c.SlashComment#10
+func (p *state) rule18(i int) step {
+	if next := p.at(i, 20); next.ok {
+		return next
+	}
+	if next := p.at(i, 19); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule19 evaluates #c.SlashComment#10: "//" #c.SlashComment#7 #c.SlashComment#9
+func (p *state) rule19(i int) step {
+	current := i
+	if next := p.terminal6(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 2); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 0); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule20 evaluates #c.SlashComment#4: "/*" #c.SlashComment#3 "*/"
+func (p *state) rule20(i int) step {
+	current := i
+	if next := p.terminal7(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 5); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal0(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule21 evaluates Token@81#0: #c.Number#0
+func (p *state) rule21(i int) step {
+	return p.at(i, 22)
+}
+
+// rule22 evaluates #c.Number#0: =~"^(\\+|-)?\\d+(.\\d*)?"
+func (p *state) rule22(i int) step {
+	return p.terminal8(i)
+}
+
+// rule23 evaluates Token@67#0: #c.Identifier#0
+func (p *state) rule23(i int) step {
+	return p.at(i, 25)
+}
+
+// rule24 evaluates Token@49#0: #c.Identifier#0 Token@49#2
+func (p *state) rule24(i int) step {
+	current := i
+	if next := p.at(current, 25); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 10); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule25 evaluates #c.Identifier#0: =~"^[a-zA-Z][a-zA-Z0-9_]*"
+func (p *state) rule25(i int) step {
+	return p.terminal9(i)
+}
+
+// rule26 evaluates Token@31#0: #Keywords#0 Token@31#1
+func (p *state) rule26(i int) step {
+	current := i
+	if next := p.at(current, 27); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 12); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule27 evaluates #Keywords#0: "break" / "default" / "func" / "interface" / "select" / "case" / "defer" / "go" / "map" / "struct" / "chan" / "else" / "goto" / "package" / "switch" / "const" / "fallthrough" / "if" / "range" / "type" / "continue" / "for" / "import" / "return" / "var"
+func (p *state) rule27(i int) step {
+	if next := p.terminal10(i); next.ok {
+		return next
+	}
+	if next := p.terminal11(i); next.ok {
+		return next
+	}
+	if next := p.terminal12(i); next.ok {
+		return next
+	}
+	if next := p.terminal13(i); next.ok {
+		return next
+	}
+	if next := p.terminal14(i); next.ok {
+		return next
+	}
+	if next := p.terminal15(i); next.ok {
+		return next
+	}
+	if next := p.terminal16(i); next.ok {
+		return next
+	}
+	if next := p.terminal17(i); next.ok {
+		return next
+	}
+	if next := p.terminal18(i); next.ok {
+		return next
+	}
+	if next := p.terminal19(i); next.ok {
+		return next
+	}
+	if next := p.terminal20(i); next.ok {
+		return next
+	}
+	if next := p.terminal21(i); next.ok {
+		return next
+	}
+	if next := p.terminal22(i); next.ok {
+		return next
+	}
+	if next := p.terminal23(i); next.ok {
+		return next
+	}
+	if next := p.terminal24(i); next.ok {
+		return next
+	}
+	if next := p.terminal25(i); next.ok {
+		return next
+	}
+	if next := p.terminal26(i); next.ok {
+		return next
+	}
+	if next := p.terminal27(i); next.ok {
+		return next
+	}
+	if next := p.terminal28(i); next.ok {
+		return next
+	}
+	if next := p.terminal29(i); next.ok {
+		return next
+	}
+	if next := p.terminal30(i); next.ok {
+		return next
+	}
+	if next := p.terminal31(i); next.ok {
+		return next
+	}
+	if next := p.terminal32(i); next.ok {
+		return next
+	}
+	if next := p.terminal33(i); next.ok {
+		return next
+	}
+	if next := p.terminal34(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule28 evaluates Token@13#0: =~"^'(\\.|[^'\\\\])*'" / =~"^\"(\\.|[^\\\"\\\\])*\""
+func (p *state) rule28(i int) step {
+	if next := p.terminal35(i); next.ok {
+		return next
+	}
+	if next := p.terminal36(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// terminal0 matches "*/"
+func (p *state) terminal0(i int) step {
+	if bytes.HasPrefix(p.data[i:], token0) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal1 matches "\n"
+func (p *state) terminal1(i int) step {
+	if bytes.HasPrefix(p.data[i:], token1) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal2 matches "("
+func (p *state) terminal2(i int) step {
+	if bytes.HasPrefix(p.data[i:], token2) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal3 matches =~"^[a-zA-Z0-9_]+"
+func (p *state) terminal3(i int) step {
+	location := pattern3.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal4 matches .
+func (p *state) terminal4(i int) step {
+	current, size := utf8.DecodeRune(p.data[i:])
+	if size == 0 || (current == utf8.RuneError && size == 1) {
+		return step{}
+	}
+	return step{ok: true, advance: size}
+}
+
+// terminal5 matches @invalid-utf8
+func (p *state) terminal5(i int) step {
+	if current, size := utf8.DecodeRune(p.data[i:]); current == utf8.RuneError && size == 1 {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal6 matches "//"
+func (p *state) terminal6(i int) step {
+	if bytes.HasPrefix(p.data[i:], token6) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal7 matches "/*"
+func (p *state) terminal7(i int) step {
+	if bytes.HasPrefix(p.data[i:], token7) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal8 matches =~"^(\\+|-)?\\d+(.\\d*)?"
+func (p *state) terminal8(i int) step {
+	location := pattern8.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal9 matches =~"^[a-zA-Z][a-zA-Z0-9_]*"
+func (p *state) terminal9(i int) step {
+	location := pattern9.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal10 matches "break"
+func (p *state) terminal10(i int) step {
+	if bytes.HasPrefix(p.data[i:], token10) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal11 matches "default"
+func (p *state) terminal11(i int) step {
+	if bytes.HasPrefix(p.data[i:], token11) {
+		return step{ok: true, advance: 7}
+	}
+	return step{}
+}
+
+// terminal12 matches "func"
+func (p *state) terminal12(i int) step {
+	if bytes.HasPrefix(p.data[i:], token12) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal13 matches "interface"
+func (p *state) terminal13(i int) step {
+	if bytes.HasPrefix(p.data[i:], token13) {
+		return step{ok: true, advance: 9}
+	}
+	return step{}
+}
+
+// terminal14 matches "select"
+func (p *state) terminal14(i int) step {
+	if bytes.HasPrefix(p.data[i:], token14) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal15 matches "case"
+func (p *state) terminal15(i int) step {
+	if bytes.HasPrefix(p.data[i:], token15) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal16 matches "defer"
+func (p *state) terminal16(i int) step {
+	if bytes.HasPrefix(p.data[i:], token16) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal17 matches "go"
+func (p *state) terminal17(i int) step {
+	if bytes.HasPrefix(p.data[i:], token17) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal18 matches "map"
+func (p *state) terminal18(i int) step {
+	if bytes.HasPrefix(p.data[i:], token18) {
+		return step{ok: true, advance: 3}
+	}
+	return step{}
+}
+
+// terminal19 matches "struct"
+func (p *state) terminal19(i int) step {
+	if bytes.HasPrefix(p.data[i:], token19) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal20 matches "chan"
+func (p *state) terminal20(i int) step {
+	if bytes.HasPrefix(p.data[i:], token20) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal21 matches "else"
+func (p *state) terminal21(i int) step {
+	if bytes.HasPrefix(p.data[i:], token21) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal22 matches "goto"
+func (p *state) terminal22(i int) step {
+	if bytes.HasPrefix(p.data[i:], token22) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal23 matches "package"
+func (p *state) terminal23(i int) step {
+	if bytes.HasPrefix(p.data[i:], token23) {
+		return step{ok: true, advance: 7}
+	}
+	return step{}
+}
+
+// terminal24 matches "switch"
+func (p *state) terminal24(i int) step {
+	if bytes.HasPrefix(p.data[i:], token24) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal25 matches "const"
+func (p *state) terminal25(i int) step {
+	if bytes.HasPrefix(p.data[i:], token25) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal26 matches "fallthrough"
+func (p *state) terminal26(i int) step {
+	if bytes.HasPrefix(p.data[i:], token26) {
+		return step{ok: true, advance: 11}
+	}
+	return step{}
+}
+
+// terminal27 matches "if"
+func (p *state) terminal27(i int) step {
+	if bytes.HasPrefix(p.data[i:], token27) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal28 matches "range"
+func (p *state) terminal28(i int) step {
+	if bytes.HasPrefix(p.data[i:], token28) {
+		return step{ok: true, advance: 5}
+	}
+	return step{}
+}
+
+// terminal29 matches "type"
+func (p *state) terminal29(i int) step {
+	if bytes.HasPrefix(p.data[i:], token29) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal30 matches "continue"
+func (p *state) terminal30(i int) step {
+	if bytes.HasPrefix(p.data[i:], token30) {
+		return step{ok: true, advance: 8}
+	}
+	return step{}
+}
+
+// terminal31 matches "for"
+func (p *state) terminal31(i int) step {
+	if bytes.HasPrefix(p.data[i:], token31) {
+		return step{ok: true, advance: 3}
+	}
+	return step{}
+}
+
+// terminal32 matches "import"
+func (p *state) terminal32(i int) step {
+	if bytes.HasPrefix(p.data[i:], token32) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal33 matches "return"
+func (p *state) terminal33(i int) step {
+	if bytes.HasPrefix(p.data[i:], token33) {
+		return step{ok: true, advance: 6}
+	}
+	return step{}
+}
+
+// terminal34 matches "var"
+func (p *state) terminal34(i int) step {
+	if bytes.HasPrefix(p.data[i:], token34) {
+		return step{ok: true, advance: 3}
+	}
+	return step{}
+}
+
+// terminal35 matches =~"^'(\\.|[^'\\\\])*'"
+func (p *state) terminal35(i int) step {
+	location := pattern35.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal36 matches =~"^\"(\\.|[^\\\"\\\\])*\""
+func (p *state) terminal36(i int) step {
+	location := pattern36.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+func (p *state) derive(parent frame, i int, l leaf) (step, int) {
+	if l.rule < 0 {
+		return p.terminal(i, l.terminal), 0
+	}
+	if !recursive[l.rule] {
+		return p.at(i, l.rule), 0
+	}
+	limit := math.MaxInt
+	if i == parent.node.Segment.Start && component[l.rule] == component[parent.rule] {
+		limit = parent.seq
+	}
+	p.at(i, l.rule)
+	return p.versionBefore(i, l.rule, limit)
+}
+
+func (p *state) build(rootStep step) *parser.ParsingNode {
+	rootNode := parser.NewParsingNode[byte](names[root], nil, p.data, definition.Segment{Start: 0, End: rootStep.advance})
+	rootFrame := frame{node: &rootNode, rule: root, seq: math.MaxInt}
+	if recursive[root] {
+		_, rootFrame.seq = p.versionBefore(0, root, math.MaxInt)
+	}
+	derivation := []frame{rootFrame}
+	for k := 0; k < len(derivation); k++ {
+		current := derivation[k]
+		addChild := func(l leaf, segment definition.Segment, seq int) {
+			if l.rule < 0 {
+				return
+			}
+			next := parser.NewParsingNode[byte](l.name, l.attributes, p.data, segment)
+			current.node.Children = append(current.node.Children, &next)
+			derivation = append(derivation, frame{node: &next, rule: l.rule, seq: seq})
+		}
+		start := current.node.Segment.Start
+		rule := shapes[current.rule]
+		switch rule.kind {
+		case kindSymbol:
+			next, seq := p.derive(current, start, rule.leaves[0])
+			if next.ok {
+				addChild(rule.leaves[0], current.node.Segment, seq)
+			}
+		case kindKleene:
+			for position := start; ; {
+				next, seq := p.derive(current, position, rule.leaves[0])
+				if !next.ok || next.advance == 0 {
+					break
+				}
+				addChild(rule.leaves[0], definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindJunction:
+			position := start
+			for _, l := range rule.leaves {
+				next, seq := p.derive(current, position, l)
+				addChild(l, definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindChoice:
+			for _, l := range rule.leaves {
+				if next, seq := p.derive(current, start, l); next.ok {
+					addChild(l, definition.Segment{Start: start, End: start + next.advance}, seq)
+					break
+				}
+			}
+		}
+	}
+	return &rootNode
+}
+
+func transform(node *parser.ParsingNode) []*parser.ParsingNode {
+	name, ok := mapping[node.Atom.Symbol]
+	symbol, hidden := definition.AnalyzeSymbolName(name)
+	shape := nodeShapes[name]
+	if !ok || hidden || shape.inline {
+		nodes := make([]*parser.ParsingNode, 0, len(node.Children))
+		for _, child := range node.Children {
+			nodes = append(nodes, transform(child)...)
+		}
+		return nodes
+	}
+	if shape.drop {
+		return nil
+	}
+	atom := node.Atom
+	atom.Symbol = symbol
+	if shape.rename != "" {
+		atom.Symbol = shape.rename
+	}
+	next := parser.ParsingNode{Atom: atom, Segment: node.Segment}
+	if shape.token {
+		return []*parser.ParsingNode{&next}
+	}
+	for _, child := range node.Children {
+		for _, transformed := range transform(child) {
+			if shape.flatten && transformed.Atom.Symbol == next.Atom.Symbol {
+				next.Children = append(next.Children, transformed.Children...)
+				continue
+			}
+			next.Children = append(next.Children, transformed)
+		}
+	}
+	if shape.collapse && len(next.Children) == 1 {
+		return next.Children
+	}
+	return []*parser.ParsingNode{&next}
+}
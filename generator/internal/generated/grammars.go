@@ -0,0 +1,72 @@
+// Package generated contains parsers emitted by the generator for the grammars which are used to test it
+package generated
+
+import (
+	"fmt"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/extension"
+	"github.com/sivukhin/gopeg/highlight"
+)
+
+//go:generate go run ./gen
+
+type Grammar struct {
+	Package string
+	Root    string
+	Rules   definition.Rules
+	// UTF8 generates the parser with generator.WithUTF8 option
+	UTF8 bool
+}
+
+var ArithmeticRules = definition.Rules{
+	definition.NewRule("Expr", definition.NewChoice(
+		definition.NewJunction(definition.NewSymbol("Expr"), definition.NewTextPattern("[+-]"), definition.NewSymbol("Term")),
+		definition.NewSymbol("Term"),
+	)),
+	definition.NewRule("Term", definition.NewChoice(
+		definition.NewJunction(definition.NewSymbol("Term"), definition.NewTextPattern("[*/]"), definition.NewSymbol("Atom")),
+		definition.NewSymbol("Atom"),
+	)),
+	definition.NewRule("Atom", definition.NewChoice(
+		definition.NewTextPattern("[0-9]+"),
+		definition.NewJunction(definition.NewTextToken("("), definition.NewSymbol("Expr"), definition.NewTextToken(")")),
+	)),
+}
+
+const annotatedGrammar = `@flatten
+List: Item ("," List)?
+@collapse-single-child
+Item: Value / "(" Inner ")"
+@rename(Tuple) Inner: List
+@inline Value: Number Comment?
+@token @rename(Num) Number: =~"[0-9]+"
+@drop Comment: =~"#[a-z]*"
+`
+
+var AnnotatedRules = mustLoad(annotatedGrammar)
+
+func mustLoad(text string) definition.Rules {
+	rules, err := extension.Load(text)
+	if err != nil {
+		panic(fmt.Errorf("unable to load grammar: %w", err))
+	}
+	return rules
+}
+
+// Grammars must be called after initialization because highlight rules are loaded in the init function
+func Grammars() []Grammar {
+	return []Grammar{
+		{Package: "arithmetic", Root: "Expr", Rules: ArithmeticRules},
+		{Package: "annotated", Root: "List", Rules: AnnotatedRules},
+		{Package: "pegtokenizer", Root: extension.PegText, Rules: extension.PegTokenizerRules},
+		{Package: "peggrammar", Root: extension.PegDefinitions, Rules: extension.PegGrammarRules},
+		{Package: "pythontokenizer", Root: "Source", Rules: highlight.PythonTokenizerRules, UTF8: true},
+		{Package: "ctokenizer", Root: "Source", Rules: highlight.CTokenizerRules, UTF8: true},
+		{Package: "rusttokenizer", Root: "Source", Rules: highlight.RustTokenizerRules, UTF8: true},
+		{Package: "shelltokenizer", Root: "Source", Rules: highlight.ShellTokenizerRules, UTF8: true},
+		{Package: "gotokenizer", Root: "Source", Rules: highlight.GoTokenizerRules, UTF8: true},
+		{Package: "zigtokenizer", Root: "Source", Rules: highlight.ZigTokenizerRules, UTF8: true},
+		{Package: "asmtokenizer", Root: "Source", Rules: highlight.AsmTokenizerRules, UTF8: true},
+	}
+}
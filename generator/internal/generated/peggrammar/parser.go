@@ -0,0 +1,1449 @@
+// Code generated by gopeg generate; DO NOT EDIT.
+
+package peggrammar
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/parser"
+)
+
+type (
+	cell struct{ position, rule int }
+	step struct {
+		ok      bool
+		advance int
+	}
+	version struct {
+		seq  int
+		step step
+	}
+	leaf struct {
+		rule       int
+		terminal   int
+		name       string
+		attributes map[string][]byte
+	}
+	shape struct {
+		kind   int
+		leaves []leaf
+	}
+	frame struct {
+		node *parser.ParsingNode
+		rule int
+		seq  int
+	}
+	nodeShape struct {
+		inline   bool
+		flatten  bool
+		collapse bool
+		token    bool
+		drop     bool
+		rename   string
+	}
+	state struct {
+		data     []definition.Atom
+		memo     map[cell]step
+		seq      int
+		versions map[cell][]version
+	}
+)
+
+const (
+	kindTerminal = iota
+	kindSymbol
+	kindJunction
+	kindChoice
+	kindKleene
+	kindNegation
+)
+
+const root = 42
+
+var (
+	atomPattern0  = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"String": nil}}
+	atomPattern1  = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"FoldedString": nil}}
+	atomPattern2  = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"CharClass": nil}}
+	atomPattern3  = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Regex": nil}}
+	atomPattern5  = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Control": definition.TextToken{Text: []byte(":")}}}
+	atomPattern6  = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Token": nil}}
+	atomPattern7  = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Control": definition.TextToken{Text: []byte(",")}}}
+	atomPattern8  = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Control": definition.TextToken{Text: []byte("<")}}}
+	atomPattern9  = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Control": definition.TextToken{Text: []byte(">")}}}
+	atomPattern10 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Control": definition.TextToken{Text: []byte("~")}}}
+	atomPattern11 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Control": definition.NewCharClass("[+*?]").(definition.CharClass)}}
+	atomPattern12 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Quantifier": nil}}
+	atomPattern13 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Control": definition.TextToken{Text: []byte("/")}}}
+	atomPattern14 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Dot": nil}}
+	atomPattern15 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"BuiltinSymbol": nil}}
+	atomPattern16 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Open": nil}}
+	atomPattern17 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Close": nil}}
+	atomPattern18 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Control": definition.NewCharClass("[!&]").(definition.CharClass)}}
+	atomPattern19 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Control": definition.TextToken{Text: []byte("{")}}}
+	atomPattern20 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Control": definition.TextToken{Text: []byte("}")}}}
+	atomPattern21 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"EndOfLine": nil}}
+	atomPattern22 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Annotation": nil}}
+	atomPattern23 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Annotation": definition.TextToken{Text: []byte("@extends")}}}
+	atomPattern24 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Annotation": definition.TextToken{Text: []byte("@import")}}}
+	atomPattern25 = definition.AtomPattern{Matcher: map[string]definition.TextTerminals{"Token": definition.TextToken{Text: []byte("as")}}}
+)
+
+var (
+	names      = []string{"MapValue#0", "MapKeyValue#2", "MapKeyValue#1", "MapKeyValue#0", "MapKey#0", "Map#2", "Map#1", "Arguments#2", "Arguments#1", "Symbol#3", "Arguments#0", "Junction#5", "Recovery#0", "Junction#4", "Suffix#0", "Choice#1", "Rule#2", "Rule#1", "Rule#0", "Choice#0", "Junction#0", "Expression#0", "Expression#1", "Junction#3", "Prefix#0", "Junction#2", "Junction#1", "Symbol#0", "SymbolToken#0", "Symbol#2", "Symbol#1", "Map#0", "Parameter#0", "Parameters#2", "Parameters#1", "Definition#5", "Extension#0", "Definition#4", "Parameters#0", "Definition#1", "ImportAlias#0", "ImportPath#0", "Definitions#0", "Definitions#3", "Definitions#2", "Definitions#1", "Definition#0", "Name#0", "Definition#3", "Definition#2", "Annotation#0", "Base#0", "Import#0"}
+	recursive  = []bool{false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false}
+	component  = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32, 33, 34, 35, 36, 37, 38, 39, 40, 41, 42, 43, 44, 45, 46, 47, 48, 49, 50, 51, 52}
+	components = [][]int{{0}, {1}, {2}, {3}, {4}, {5}, {6}, {7}, {8}, {9}, {10}, {11}, {12}, {13}, {14}, {15}, {16}, {17}, {18}, {19}, {20}, {21}, {22}, {23}, {24}, {25}, {26}, {27}, {28}, {29}, {30}, {31}, {32}, {33}, {34}, {35}, {36}, {37}, {38}, {39}, {40}, {41}, {42}, {43}, {44}, {45}, {46}, {47}, {48}, {49}, {50}, {51}, {52}}
+	shapes     = []shape{
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 0}, {rule: -1, terminal: 1}, {rule: -1, terminal: 2}, {rule: -1, terminal: 3}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 2, name: "MapKeyValue#1", attributes: nil}, {rule: -1, terminal: 4}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 5}, {rule: 0, name: "MapValue#0", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 4, name: "MapKey#0", attributes: nil}, {rule: 1, name: "MapKeyValue#2", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 0}, {rule: -1, terminal: 6}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 6, name: "Map#1", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 7}, {rule: 3, name: "MapKeyValue#0", attributes: nil}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 8, name: "Arguments#1", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 7}, {rule: 18, name: "Rule#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 10, name: "Arguments#0", attributes: nil}, {rule: -1, terminal: 4}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 8}, {rule: 18, name: "Rule#0", attributes: nil}, {rule: 7, name: "Arguments#2", attributes: nil}, {rule: -1, terminal: 9}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 12, name: "Recovery#0", attributes: nil}, {rule: -1, terminal: 4}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 10}, {rule: 21, name: "Expression#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 14, name: "Suffix#0", attributes: nil}, {rule: -1, terminal: 4}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 11}, {rule: -1, terminal: 12}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 20, name: "Junction#0", attributes: nil}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 17, name: "Rule#1", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 13}, {rule: 19, name: "Choice#0", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 19, name: "Choice#0", attributes: nil}, {rule: 16, name: "Rule#2", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 20, name: "Junction#0", attributes: nil}, {rule: 15, name: "Choice#1", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 25, name: "Junction#2", attributes: nil}, {rule: 23, name: "Junction#3", attributes: nil}, {rule: 21, name: "Expression#0", attributes: nil}, {rule: 13, name: "Junction#4", attributes: nil}, {rule: 11, name: "Junction#5", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 0}, {rule: -1, terminal: 1}, {rule: -1, terminal: 2}, {rule: -1, terminal: 3}, {rule: 27, name: "Symbol#0", attributes: nil}, {rule: -1, terminal: 14}, {rule: -1, terminal: 15}, {rule: 31, name: "Map#0", attributes: nil}, {rule: 22, name: "Expression#1", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 16}, {rule: 18, name: "Rule#0", attributes: nil}, {rule: -1, terminal: 17}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 24, name: "Prefix#0", attributes: nil}, {rule: -1, terminal: 4}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 18}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 26, name: "Junction#1", attributes: nil}, {rule: -1, terminal: 4}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 27, name: "Symbol#0", attributes: nil}, {rule: -1, terminal: 5}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 29, name: "Symbol#2", attributes: nil}, {rule: 28, name: "SymbolToken#0", attributes: nil}, {rule: 9, name: "Symbol#3", attributes: nil}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 6}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 30, name: "Symbol#1", attributes: nil}, {rule: -1, terminal: 4}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 31, name: "Map#0", attributes: nil}, {rule: -1, terminal: 5}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 19}, {rule: 3, name: "MapKeyValue#0", attributes: nil}, {rule: 5, name: "Map#2", attributes: nil}, {rule: -1, terminal: 20}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 6}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 34, name: "Parameters#1", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 7}, {rule: 32, name: "Parameter#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 36, name: "Extension#0", attributes: nil}, {rule: -1, terminal: 4}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 13}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 38, name: "Parameters#0", attributes: nil}, {rule: -1, terminal: 4}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 8}, {rule: 32, name: "Parameter#0", attributes: nil}, {rule: 33, name: "Parameters#2", attributes: nil}, {rule: -1, terminal: 9}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 21}, {rule: -1, terminal: 4}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 6}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 0}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 43, name: "Definitions#3", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 44, name: "Definitions#2", attributes: nil}, {rule: -1, terminal: 21}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 45, name: "Definitions#1", attributes: nil}, {rule: -1, terminal: 4}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 52, name: "Import#0", attributes: nil}, {rule: 51, name: "Base#0", attributes: nil}, {rule: 46, name: "Definition#0", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 48, name: "Definition#3", attributes: nil}, {rule: 47, name: "Name#0", attributes: nil}, {rule: 37, name: "Definition#4", attributes: nil}, {rule: 35, name: "Definition#5", attributes: nil}, {rule: -1, terminal: 5}, {rule: 18, name: "Rule#0", attributes: nil}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 6}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 49, name: "Definition#2", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 50, name: "Annotation#0", attributes: nil}, {rule: 39, name: "Definition#1", attributes: nil}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 22}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 23}, {rule: 41, name: "ImportPath#0", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 24}, {rule: 41, name: "ImportPath#0", attributes: nil}, {rule: -1, terminal: 25}, {rule: 40, name: "ImportAlias#0", attributes: nil}}},
+	}
+	mapping = map[string]string{
+		"Annotation#0":  "Annotation",
+		"Arguments#0":   "Arguments",
+		"Base#0":        "Base",
+		"Choice#0":      "Choice",
+		"Definition#0":  "Definition",
+		"Definitions#0": "Definitions",
+		"Expression#0":  "Expression",
+		"Extension#0":   "Extension",
+		"Import#0":      "Import",
+		"ImportAlias#0": "ImportAlias",
+		"ImportPath#0":  "ImportPath",
+		"Junction#0":    "Junction",
+		"Map#0":         "Map",
+		"MapKey#0":      "MapKey",
+		"MapKeyValue#0": "MapKeyValue",
+		"MapValue#0":    "MapValue",
+		"Name#0":        "Name",
+		"Parameter#0":   "Parameter",
+		"Parameters#0":  "Parameters",
+		"Prefix#0":      "Prefix",
+		"Recovery#0":    "Recovery",
+		"Rule#0":        "Rule",
+		"Suffix#0":      "Suffix",
+		"Symbol#0":      "Symbol",
+		"SymbolToken#0": "SymbolToken",
+	}
+	nodeShapes = map[string]nodeShape{}
+)
+
+// Parse matches the longest prefix of the data with Definitions rule
+func Parse(data []definition.Atom) (*parser.ParsingNode, error) { return parse(data, false) }
+
+// ParseFull matches the whole data with Definitions rule
+func ParseFull(data []definition.Atom) (*parser.ParsingNode, error) { return parse(data, true) }
+
+func parse(data []definition.Atom, full bool) (*parser.ParsingNode, error) {
+	p := &state{data: data, memo: make(map[cell]step), versions: make(map[cell][]version)}
+	rootStep := p.at(0, root)
+	if !rootStep.ok {
+		return nil, fmt.Errorf("%w: rule Definitions doesn't match", parser.TextNotMatchErr)
+	}
+	if full && rootStep.advance != len(data) {
+		return nil, fmt.Errorf("%w: rule Definitions matched only first %v elements out of %v", parser.TextNotMatchErr, rootStep.advance, len(data))
+	}
+	nodes := transform(p.build(rootStep))
+	if len(nodes) != 1 {
+		return nil, fmt.Errorf("tree with multiple root was formed")
+	}
+	return nodes[0], nil
+}
+
+func (p *state) at(i, s int) step {
+	key := cell{position: i, rule: s}
+	if result, ok := p.memo[key]; ok {
+		return result
+	}
+	if !recursive[s] {
+		result := p.evaluate(i, s)
+		p.memo[key] = result
+		return result
+	}
+	group := components[component[s]]
+	for _, r := range group {
+		p.memo[cell{position: i, rule: r}] = step{}
+		p.versions[cell{position: i, rule: r}] = []version{{seq: -1}}
+	}
+	for grown := true; grown; {
+		grown = false
+		for _, r := range group {
+			p.seq++
+			seq := p.seq
+			next := p.evaluate(i, r)
+			if current := p.memo[cell{position: i, rule: r}]; next.ok && (!current.ok || next.advance > current.advance) {
+				p.memo[cell{position: i, rule: r}] = next
+				p.versions[cell{position: i, rule: r}] = append(p.versions[cell{position: i, rule: r}], version{seq: seq, step: next})
+				grown = true
+			}
+		}
+	}
+	return p.memo[key]
+}
+
+func (p *state) versionBefore(i, s, seq int) (step, int) {
+	versions := p.versions[cell{position: i, rule: s}]
+	for k := len(versions) - 1; k >= 0; k-- {
+		if versions[k].seq < seq {
+			return versions[k].step, versions[k].seq
+		}
+	}
+	panic(fmt.Errorf("left-recursive cell %v has no versions before %v", cell{position: i, rule: s}, seq))
+}
+
+func (p *state) evaluate(i, s int) step {
+	switch s {
+	case 0:
+		return p.rule0(i)
+	case 1:
+		return p.rule1(i)
+	case 2:
+		return p.rule2(i)
+	case 3:
+		return p.rule3(i)
+	case 4:
+		return p.rule4(i)
+	case 5:
+		return p.rule5(i)
+	case 6:
+		return p.rule6(i)
+	case 7:
+		return p.rule7(i)
+	case 8:
+		return p.rule8(i)
+	case 9:
+		return p.rule9(i)
+	case 10:
+		return p.rule10(i)
+	case 11:
+		return p.rule11(i)
+	case 12:
+		return p.rule12(i)
+	case 13:
+		return p.rule13(i)
+	case 14:
+		return p.rule14(i)
+	case 15:
+		return p.rule15(i)
+	case 16:
+		return p.rule16(i)
+	case 17:
+		return p.rule17(i)
+	case 18:
+		return p.rule18(i)
+	case 19:
+		return p.rule19(i)
+	case 20:
+		return p.rule20(i)
+	case 21:
+		return p.rule21(i)
+	case 22:
+		return p.rule22(i)
+	case 23:
+		return p.rule23(i)
+	case 24:
+		return p.rule24(i)
+	case 25:
+		return p.rule25(i)
+	case 26:
+		return p.rule26(i)
+	case 27:
+		return p.rule27(i)
+	case 28:
+		return p.rule28(i)
+	case 29:
+		return p.rule29(i)
+	case 30:
+		return p.rule30(i)
+	case 31:
+		return p.rule31(i)
+	case 32:
+		return p.rule32(i)
+	case 33:
+		return p.rule33(i)
+	case 34:
+		return p.rule34(i)
+	case 35:
+		return p.rule35(i)
+	case 36:
+		return p.rule36(i)
+	case 37:
+		return p.rule37(i)
+	case 38:
+		return p.rule38(i)
+	case 39:
+		return p.rule39(i)
+	case 40:
+		return p.rule40(i)
+	case 41:
+		return p.rule41(i)
+	case 42:
+		return p.rule42(i)
+	case 43:
+		return p.rule43(i)
+	case 44:
+		return p.rule44(i)
+	case 45:
+		return p.rule45(i)
+	case 46:
+		return p.rule46(i)
+	case 47:
+		return p.rule47(i)
+	case 48:
+		return p.rule48(i)
+	case 49:
+		return p.rule49(i)
+	case 50:
+		return p.rule50(i)
+	case 51:
+		return p.rule51(i)
+	case 52:
+		return p.rule52(i)
+	}
+	panic(fmt.Errorf("unexpected rule: %v", s))
+}
+
+func (p *state) terminal(i, k int) step {
+	switch k {
+	case 0:
+		return p.terminal0(i)
+	case 1:
+		return p.terminal1(i)
+	case 2:
+		return p.terminal2(i)
+	case 3:
+		return p.terminal3(i)
+	case 4:
+		return p.terminal4(i)
+	case 5:
+		return p.terminal5(i)
+	case 6:
+		return p.terminal6(i)
+	case 7:
+		return p.terminal7(i)
+	case 8:
+		return p.terminal8(i)
+	case 9:
+		return p.terminal9(i)
+	case 10:
+		return p.terminal10(i)
+	case 11:
+		return p.terminal11(i)
+	case 12:
+		return p.terminal12(i)
+	case 13:
+		return p.terminal13(i)
+	case 14:
+		return p.terminal14(i)
+	case 15:
+		return p.terminal15(i)
+	case 16:
+		return p.terminal16(i)
+	case 17:
+		return p.terminal17(i)
+	case 18:
+		return p.terminal18(i)
+	case 19:
+		return p.terminal19(i)
+	case 20:
+		return p.terminal20(i)
+	case 21:
+		return p.terminal21(i)
+	case 22:
+		return p.terminal22(i)
+	case 23:
+		return p.terminal23(i)
+	case 24:
+		return p.terminal24(i)
+	case 25:
+		return p.terminal25(i)
+	}
+	panic(fmt.Errorf("unexpected terminal: %v", k))
+}
+
+// rule0 evaluates MapValue#0: {String} / {FoldedString} / {CharClass} / {Regex}
+func (p *state) rule0(i int) step {
+	if next := p.terminal0(i); next.ok {
+		return next
+	}
+	if next := p.terminal1(i); next.ok {
+		return next
+	}
+	if next := p.terminal2(i); next.ok {
+		return next
+	}
+	if next := p.terminal3(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule1 evaluates MapKeyValue#2: MapKeyValue#1 / @empty
+func (p *state) rule1(i int) step {
+	if next := p.at(i, 2); next.ok {
+		return next
+	}
+	if next := p.terminal4(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule2 evaluates MapKeyValue#1: {Control:":"} MapValue#0
+func (p *state) rule2(i int) step {
+	current := i
+	if next := p.terminal5(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 0); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule3 evaluates MapKeyValue#0: MapKey#0 MapKeyValue#2
+func (p *state) rule3(i int) step {
+	current := i
+	if next := p.at(current, 4); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 1); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule4 evaluates MapKey#0: {String} / {Token}
+func (p *state) rule4(i int) step {
+	if next := p.terminal0(i); next.ok {
+		return next
+	}
+	if next := p.terminal6(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule5 evaluates Map#2: Map#1*
+func (p *state) rule5(i int) step {
+	current := i
+	for {
+		next := p.at(current, 6)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule6 evaluates Map#1: {Control:","} MapKeyValue#0
+func (p *state) rule6(i int) step {
+	current := i
+	if next := p.terminal7(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 3); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule7 evaluates Arguments#2: Arguments#1*
+func (p *state) rule7(i int) step {
+	current := i
+	for {
+		next := p.at(current, 8)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule8 evaluates Arguments#1: {Control:","} Rule#0
+func (p *state) rule8(i int) step {
+	current := i
+	if next := p.terminal7(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 18); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule9 evaluates Symbol#3: Arguments#0 / @empty
+func (p *state) rule9(i int) step {
+	if next := p.at(i, 10); next.ok {
+		return next
+	}
+	if next := p.terminal4(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule10 evaluates Arguments#0: {Control:"<"} Rule#0 Arguments#2 {Control:">"}
+func (p *state) rule10(i int) step {
+	current := i
+	if next := p.terminal8(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 18); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 7); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal9(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule11 evaluates Junction#5: Recovery#0 / @empty
+func (p *state) rule11(i int) step {
+	if next := p.at(i, 12); next.ok {
+		return next
+	}
+	if next := p.terminal4(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule12 evaluates Recovery#0: {Control:"~"} Expression#0
+func (p *state) rule12(i int) step {
+	current := i
+	if next := p.terminal10(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 21); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule13 evaluates Junction#4: Suffix#0 / @empty
+func (p *state) rule13(i int) step {
+	if next := p.at(i, 14); next.ok {
+		return next
+	}
+	if next := p.terminal4(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule14 evaluates Suffix#0: {Control:[+*?]} / {Quantifier}
+func (p *state) rule14(i int) step {
+	if next := p.terminal11(i); next.ok {
+		return next
+	}
+	if next := p.terminal12(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule15 evaluates Choice#1: Junction#0*
+func (p *state) rule15(i int) step {
+	current := i
+	for {
+		next := p.at(current, 20)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule16 evaluates Rule#2: Rule#1*
+func (p *state) rule16(i int) step {
+	current := i
+	for {
+		next := p.at(current, 17)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule17 evaluates Rule#1: {Control:"/"} Choice#0
+func (p *state) rule17(i int) step {
+	current := i
+	if next := p.terminal13(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 19); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule18 evaluates Rule#0: Choice#0 Rule#2
+func (p *state) rule18(i int) step {
+	current := i
+	if next := p.at(current, 19); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 16); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule19 evaluates Choice#0: Junction#0 Choice#1
+func (p *state) rule19(i int) step {
+	current := i
+	if next := p.at(current, 20); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 15); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule20 evaluates Junction#0: Junction#2 Junction#3 Expression#0 Junction#4 Junction#5
+func (p *state) rule20(i int) step {
+	current := i
+	if next := p.at(current, 25); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 23); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 21); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 13); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 11); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule21 evaluates Expression#0: {String} / {FoldedString} / {CharClass} / {Regex} / Symbol#0 / {Dot} / {BuiltinSymbol} / Map#0 / Expression#1
+func (p *state) rule21(i int) step {
+	if next := p.terminal0(i); next.ok {
+		return next
+	}
+	if next := p.terminal1(i); next.ok {
+		return next
+	}
+	if next := p.terminal2(i); next.ok {
+		return next
+	}
+	if next := p.terminal3(i); next.ok {
+		return next
+	}
+	if next := p.at(i, 27); next.ok {
+		return next
+	}
+	if next := p.terminal14(i); next.ok {
+		return next
+	}
+	if next := p.terminal15(i); next.ok {
+		return next
+	}
+	if next := p.at(i, 31); next.ok {
+		return next
+	}
+	if next := p.at(i, 22); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule22 evaluates Expression#1: {Open} Rule#0 {Close}
+func (p *state) rule22(i int) step {
+	current := i
+	if next := p.terminal16(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 18); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal17(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule23 evaluates Junction#3: Prefix#0 / @empty
+func (p *state) rule23(i int) step {
+	if next := p.at(i, 24); next.ok {
+		return next
+	}
+	if next := p.terminal4(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule24 evaluates Prefix#0: {Control:[!&]}
+func (p *state) rule24(i int) step {
+	return p.terminal18(i)
+}
+
+// rule25 evaluates Junction#2: Junction#1 / @empty
+func (p *state) rule25(i int) step {
+	if next := p.at(i, 26); next.ok {
+		return next
+	}
+	if next := p.terminal4(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule26 evaluates Junction#1: Symbol#0 {Control:":"}
+func (p *state) rule26(i int) step {
+	current := i
+	if next := p.at(current, 27); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal5(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule27 evaluates Symbol#0: Symbol#2 SymbolToken#0 Symbol#3
+func (p *state) rule27(i int) step {
+	current := i
+	if next := p.at(current, 29); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 28); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 9); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule28 evaluates SymbolToken#0: {Token}
+func (p *state) rule28(i int) step {
+	return p.terminal6(i)
+}
+
+// rule29 evaluates Symbol#2: Symbol#1 / @empty
+func (p *state) rule29(i int) step {
+	if next := p.at(i, 30); next.ok {
+		return next
+	}
+	if next := p.terminal4(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule30 evaluates Symbol#1: Map#0 {Control:":"}
+func (p *state) rule30(i int) step {
+	current := i
+	if next := p.at(current, 31); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal5(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule31 evaluates Map#0: {Control:"{"} MapKeyValue#0 Map#2 {Control:"}"}
+func (p *state) rule31(i int) step {
+	current := i
+	if next := p.terminal19(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 3); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 5); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal20(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule32 evaluates Parameter#0: {Token}
+func (p *state) rule32(i int) step {
+	return p.terminal6(i)
+}
+
+// rule33 evaluates Parameters#2: Parameters#1*
+func (p *state) rule33(i int) step {
+	current := i
+	for {
+		next := p.at(current, 34)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule34 evaluates Parameters#1: {Control:","} Parameter#0
+func (p *state) rule34(i int) step {
+	current := i
+	if next := p.terminal7(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 32); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule35 evaluates Definition#5: Extension#0 / @empty
+func (p *state) rule35(i int) step {
+	if next := p.at(i, 36); next.ok {
+		return next
+	}
+	if next := p.terminal4(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule36 evaluates Extension#0: {Control:"/"}
+func (p *state) rule36(i int) step {
+	return p.terminal13(i)
+}
+
+// rule37 evaluates Definition#4: Parameters#0 / @empty
+func (p *state) rule37(i int) step {
+	if next := p.at(i, 38); next.ok {
+		return next
+	}
+	if next := p.terminal4(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule38 evaluates Parameters#0: {Control:"<"} Parameter#0 Parameters#2 {Control:">"}
+func (p *state) rule38(i int) step {
+	current := i
+	if next := p.terminal8(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 32); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 33); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal9(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule39 evaluates Definition#1: {EndOfLine} / @empty
+func (p *state) rule39(i int) step {
+	if next := p.terminal21(i); next.ok {
+		return next
+	}
+	if next := p.terminal4(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule40 evaluates ImportAlias#0: {Token}
+func (p *state) rule40(i int) step {
+	return p.terminal6(i)
+}
+
+// rule41 evaluates ImportPath#0: {String}
+func (p *state) rule41(i int) step {
+	return p.terminal0(i)
+}
+
+// rule42 evaluates Definitions#0: Definitions#3*
+func (p *state) rule42(i int) step {
+	current := i
+	for {
+		next := p.at(current, 43)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule43 evaluates Definitions#3: Definitions#2 {EndOfLine}
+func (p *state) rule43(i int) step {
+	current := i
+	if next := p.at(current, 44); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal21(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule44 evaluates Definitions#2: Definitions#1 / @empty
+func (p *state) rule44(i int) step {
+	if next := p.at(i, 45); next.ok {
+		return next
+	}
+	if next := p.terminal4(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule45 evaluates Definitions#1: Import#0 / Base#0 / Definition#0
+func (p *state) rule45(i int) step {
+	if next := p.at(i, 52); next.ok {
+		return next
+	}
+	if next := p.at(i, 51); next.ok {
+		return next
+	}
+	if next := p.at(i, 46); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule46 evaluates Definition#0: Definition#3 Name#0 Definition#4 Definition#5 {Control:":"} Rule#0
+func (p *state) rule46(i int) step {
+	current := i
+	if next := p.at(current, 48); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 47); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 37); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 35); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal5(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 18); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule47 evaluates Name#0: {Token}
+func (p *state) rule47(i int) step {
+	return p.terminal6(i)
+}
+
+// rule48 evaluates Definition#3: Definition#2*
+func (p *state) rule48(i int) step {
+	current := i
+	for {
+		next := p.at(current, 49)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule49 evaluates Definition#2: Annotation#0 Definition#1
+func (p *state) rule49(i int) step {
+	current := i
+	if next := p.at(current, 50); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 39); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule50 evaluates Annotation#0: {Annotation}
+func (p *state) rule50(i int) step {
+	return p.terminal22(i)
+}
+
+// rule51 evaluates Base#0: {Annotation:"@extends"} ImportPath#0
+func (p *state) rule51(i int) step {
+	current := i
+	if next := p.terminal23(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 41); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule52 evaluates Import#0: {Annotation:"@import"} ImportPath#0 {Token:"as"} ImportAlias#0
+func (p *state) rule52(i int) step {
+	current := i
+	if next := p.terminal24(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 41); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal25(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 40); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// terminal0 matches {String}
+func (p *state) terminal0(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern0, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal1 matches {FoldedString}
+func (p *state) terminal1(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern1, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal2 matches {CharClass}
+func (p *state) terminal2(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern2, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal3 matches {Regex}
+func (p *state) terminal3(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern3, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal4 matches @empty
+func (p *state) terminal4(i int) step {
+	return step{ok: true}
+}
+
+// terminal5 matches {Control:":"}
+func (p *state) terminal5(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern5, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal6 matches {Token}
+func (p *state) terminal6(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern6, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal7 matches {Control:","}
+func (p *state) terminal7(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern7, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal8 matches {Control:"<"}
+func (p *state) terminal8(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern8, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal9 matches {Control:">"}
+func (p *state) terminal9(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern9, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal10 matches {Control:"~"}
+func (p *state) terminal10(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern10, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal11 matches {Control:[+*?]}
+func (p *state) terminal11(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern11, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal12 matches {Quantifier}
+func (p *state) terminal12(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern12, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal13 matches {Control:"/"}
+func (p *state) terminal13(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern13, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal14 matches {Dot}
+func (p *state) terminal14(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern14, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal15 matches {BuiltinSymbol}
+func (p *state) terminal15(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern15, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal16 matches {Open}
+func (p *state) terminal16(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern16, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal17 matches {Close}
+func (p *state) terminal17(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern17, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal18 matches {Control:[!&]}
+func (p *state) terminal18(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern18, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal19 matches {Control:"{"}
+func (p *state) terminal19(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern19, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal20 matches {Control:"}"}
+func (p *state) terminal20(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern20, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal21 matches {EndOfLine}
+func (p *state) terminal21(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern21, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal22 matches {Annotation}
+func (p *state) terminal22(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern22, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal23 matches {Annotation:"@extends"}
+func (p *state) terminal23(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern23, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal24 matches {Annotation:"@import"}
+func (p *state) terminal24(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern24, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+// terminal25 matches {Token:"as"}
+func (p *state) terminal25(i int) step {
+	advance, ok := definition.Accept[definition.Atom](atomPattern25, p.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+func (p *state) derive(parent frame, i int, l leaf) (step, int) {
+	if l.rule < 0 {
+		return p.terminal(i, l.terminal), 0
+	}
+	if !recursive[l.rule] {
+		return p.at(i, l.rule), 0
+	}
+	limit := math.MaxInt
+	if i == parent.node.Segment.Start && component[l.rule] == component[parent.rule] {
+		limit = parent.seq
+	}
+	p.at(i, l.rule)
+	return p.versionBefore(i, l.rule, limit)
+}
+
+func (p *state) build(rootStep step) *parser.ParsingNode {
+	rootNode := parser.NewParsingNode[definition.Atom](names[root], nil, p.data, definition.Segment{Start: 0, End: rootStep.advance})
+	rootFrame := frame{node: &rootNode, rule: root, seq: math.MaxInt}
+	if recursive[root] {
+		_, rootFrame.seq = p.versionBefore(0, root, math.MaxInt)
+	}
+	derivation := []frame{rootFrame}
+	for k := 0; k < len(derivation); k++ {
+		current := derivation[k]
+		addChild := func(l leaf, segment definition.Segment, seq int) {
+			if l.rule < 0 {
+				return
+			}
+			next := parser.NewParsingNode[definition.Atom](l.name, l.attributes, p.data, segment)
+			current.node.Children = append(current.node.Children, &next)
+			derivation = append(derivation, frame{node: &next, rule: l.rule, seq: seq})
+		}
+		start := current.node.Segment.Start
+		rule := shapes[current.rule]
+		switch rule.kind {
+		case kindSymbol:
+			next, seq := p.derive(current, start, rule.leaves[0])
+			if next.ok {
+				addChild(rule.leaves[0], current.node.Segment, seq)
+			}
+		case kindKleene:
+			for position := start; ; {
+				next, seq := p.derive(current, position, rule.leaves[0])
+				if !next.ok || next.advance == 0 {
+					break
+				}
+				addChild(rule.leaves[0], definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindJunction:
+			position := start
+			for _, l := range rule.leaves {
+				next, seq := p.derive(current, position, l)
+				addChild(l, definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindChoice:
+			for _, l := range rule.leaves {
+				if next, seq := p.derive(current, start, l); next.ok {
+					addChild(l, definition.Segment{Start: start, End: start + next.advance}, seq)
+					break
+				}
+			}
+		}
+	}
+	return &rootNode
+}
+
+func transform(node *parser.ParsingNode) []*parser.ParsingNode {
+	name, ok := mapping[node.Atom.Symbol]
+	symbol, hidden := definition.AnalyzeSymbolName(name)
+	shape := nodeShapes[name]
+	if !ok || hidden || shape.inline {
+		nodes := make([]*parser.ParsingNode, 0, len(node.Children))
+		for _, child := range node.Children {
+			nodes = append(nodes, transform(child)...)
+		}
+		return nodes
+	}
+	if shape.drop {
+		return nil
+	}
+	atom := node.Atom
+	atom.Symbol = symbol
+	if shape.rename != "" {
+		atom.Symbol = shape.rename
+	}
+	next := parser.ParsingNode{Atom: atom, Segment: node.Segment}
+	if shape.token {
+		return []*parser.ParsingNode{&next}
+	}
+	for _, child := range node.Children {
+		for _, transformed := range transform(child) {
+			if shape.flatten && transformed.Atom.Symbol == next.Atom.Symbol {
+				next.Children = append(next.Children, transformed.Children...)
+				continue
+			}
+			next.Children = append(next.Children, transformed)
+		}
+	}
+	if shape.collapse && len(next.Children) == 1 {
+		return next.Children
+	}
+	return []*parser.ParsingNode{&next}
+}
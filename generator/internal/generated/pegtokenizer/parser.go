@@ -0,0 +1,1139 @@
+// Code generated by gopeg generate; DO NOT EDIT.
+
+package pegtokenizer
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"regexp"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/parser"
+)
+
+type (
+	cell struct{ position, rule int }
+	step struct {
+		ok      bool
+		advance int
+	}
+	version struct {
+		seq  int
+		step step
+	}
+	leaf struct {
+		rule       int
+		terminal   int
+		name       string
+		attributes map[string][]byte
+	}
+	shape struct {
+		kind   int
+		leaves []leaf
+	}
+	frame struct {
+		node *parser.ParsingNode
+		rule int
+		seq  int
+	}
+	nodeShape struct {
+		inline   bool
+		flatten  bool
+		collapse bool
+		token    bool
+		drop     bool
+		rename   string
+	}
+	state struct {
+		data     []byte
+		memo     map[cell]step
+		seq      int
+		versions map[cell][]version
+	}
+)
+
+const (
+	kindTerminal = iota
+	kindSymbol
+	kindJunction
+	kindChoice
+	kindKleene
+	kindNegation
+)
+
+const root = 17
+
+var (
+	pattern0    = regexp.MustCompile("^[\t\r ]+")
+	pattern1    = regexp.MustCompile("^//[^\n]+")
+	token2      = []byte("\n")
+	token3      = []byte("=~")
+	token4      = []byte("/*")
+	token5      = []byte("*/")
+	token7      = []byte(")")
+	token8      = []byte("(")
+	token9      = []byte(".")
+	pattern10   = regexp.MustCompile("^@[a-z][a-z-]*(\\([^()\\n]*\\))?")
+	token11     = []byte("@sof")
+	token12     = []byte("@eof")
+	token13     = []byte("@invalid-utf8")
+	charClass14 = definition.NewCharClass("[:/*+?{},!&~<>]").(definition.CharClass)
+	pattern15   = regexp.MustCompile("^\\{[0-9]+(,[0-9]*)?\\}")
+	pattern16   = regexp.MustCompile("^[#a-zA-Z][0-9a-zA-Z_]*(\\.[a-zA-Z][0-9a-zA-Z_]*)?")
+	pattern17   = regexp.MustCompile("^\\[(\\\\.|[^\\]\\\\\\n])+\\]")
+	pattern18   = regexp.MustCompile("^\"(\\\\.|[^\"\\\\])*\"")
+	pattern19   = regexp.MustCompile("^`[^`]*`")
+	pattern20   = regexp.MustCompile("^\"(\\\\.|[^\"\\\\])*\"i\\b")
+	pattern21   = regexp.MustCompile("^`[^`]*`i\\b")
+)
+
+var (
+	names      = []string{"Regex#0", "#Sequence#19", "#Sequence#18", "#Sequence#17", "#Sequence#16", "#Sequence#15", "#Sequence#14", "#Sequence#13", "#Sequence#12", "#Sequence#11", "#Sequence#10", "#Sequence#7", "#Sequence#6", "#Sequence#3", "#Sequence#2", "#Sequence#1", "Close#0", "Text#0", "Text#2", "EndOfLine#0", "EndOfLine#1", "Text#1", "#Sequence#0", "#Sequence#9", "#Sequence#8", "Open#0", "Dot#0", "Annotation#0", "BuiltinSymbol#0", "Control#0", "Quantifier#0", "Token#0", "CharClass#0", "String#0", "FoldedString#0", "#Sequence#5", "#Sequence#4"}
+	recursive  = []bool{false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false, false}
+	component  = []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16, 17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 32, 33, 34, 35, 36}
+	components = [][]int{{0}, {1}, {2}, {3}, {4}, {5}, {6}, {7}, {8}, {9}, {10}, {11}, {12}, {13}, {14}, {15}, {16}, {17}, {18}, {19}, {20}, {21}, {22}, {23}, {24}, {25}, {26}, {27}, {28}, {29}, {30}, {31}, {32}, {33}, {34}, {35}, {36}}
+	shapes     = []shape{
+		{kind: kindSymbol, leaves: []leaf{{rule: 33, name: "String#0", attributes: nil}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 2, name: "#Sequence#18", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 0}, {rule: -1, terminal: 1}, {rule: 7, name: "#Sequence#13", attributes: nil}, {rule: 6, name: "#Sequence#14", attributes: nil}, {rule: 34, name: "FoldedString#0", attributes: nil}, {rule: 33, name: "String#0", attributes: nil}, {rule: 32, name: "CharClass#0", attributes: nil}, {rule: 31, name: "Token#0", attributes: nil}, {rule: 30, name: "Quantifier#0", attributes: nil}, {rule: 29, name: "Control#0", attributes: nil}, {rule: 28, name: "BuiltinSymbol#0", attributes: nil}, {rule: 27, name: "Annotation#0", attributes: nil}, {rule: 26, name: "Dot#0", attributes: nil}, {rule: 3, name: "#Sequence#17", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 25, name: "Open#0", attributes: nil}, {rule: 4, name: "#Sequence#16", attributes: nil}, {rule: 16, name: "Close#0", attributes: nil}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 5, name: "#Sequence#15", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 22, name: "#Sequence#0", attributes: nil}, {rule: -1, terminal: 2}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 3}, {rule: 0, name: "Regex#0", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 4}, {rule: 8, name: "#Sequence#12", attributes: nil}, {rule: -1, terminal: 5}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 9, name: "#Sequence#11", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 10, name: "#Sequence#10", attributes: nil}, {rule: -1, terminal: 6}}},
+		{kind: kindNegation, leaves: []leaf{{rule: -1, terminal: 5}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 12, name: "#Sequence#6", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: 22, name: "#Sequence#0", attributes: nil}, {rule: -1, terminal: 2}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 14, name: "#Sequence#2", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 15, name: "#Sequence#1", attributes: nil}, {rule: -1, terminal: 6}}},
+		{kind: kindNegation, leaves: []leaf{{rule: -1, terminal: 5}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 7}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 18, name: "Text#2", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 21, name: "Text#1", attributes: nil}, {rule: 19, name: "EndOfLine#0", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 2}, {rule: 20, name: "EndOfLine#1", attributes: nil}}},
+		{kind: kindNegation, leaves: []leaf{{rule: -1, terminal: 6}}},
+		{kind: kindKleene, leaves: []leaf{{rule: 22, name: "#Sequence#0", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 23, name: "#Sequence#9", attributes: nil}, {rule: 1, name: "#Sequence#19", attributes: nil}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 0}, {rule: -1, terminal: 1}, {rule: 36, name: "#Sequence#4", attributes: nil}, {rule: 35, name: "#Sequence#5", attributes: nil}, {rule: 34, name: "FoldedString#0", attributes: nil}, {rule: 33, name: "String#0", attributes: nil}, {rule: 32, name: "CharClass#0", attributes: nil}, {rule: 31, name: "Token#0", attributes: nil}, {rule: 30, name: "Quantifier#0", attributes: nil}, {rule: 29, name: "Control#0", attributes: nil}, {rule: 28, name: "BuiltinSymbol#0", attributes: nil}, {rule: 27, name: "Annotation#0", attributes: nil}, {rule: 26, name: "Dot#0", attributes: nil}, {rule: 24, name: "#Sequence#8", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: 25, name: "Open#0", attributes: nil}, {rule: 11, name: "#Sequence#7", attributes: nil}, {rule: 16, name: "Close#0", attributes: nil}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 8}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 9}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 10}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 11}, {rule: -1, terminal: 12}, {rule: -1, terminal: 13}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 14}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 15}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 16}}},
+		{kind: kindTerminal, leaves: []leaf{{rule: -1, terminal: 17}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 18}, {rule: -1, terminal: 19}}},
+		{kind: kindChoice, leaves: []leaf{{rule: -1, terminal: 20}, {rule: -1, terminal: 21}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 3}, {rule: 0, name: "Regex#0", attributes: nil}}},
+		{kind: kindJunction, leaves: []leaf{{rule: -1, terminal: 4}, {rule: 13, name: "#Sequence#3", attributes: nil}, {rule: -1, terminal: 5}}},
+	}
+	mapping = map[string]string{
+		"#Sequence#0":     "#Sequence",
+		"Annotation#0":    "Annotation",
+		"BuiltinSymbol#0": "BuiltinSymbol",
+		"CharClass#0":     "CharClass",
+		"Close#0":         "Close",
+		"Control#0":       "Control",
+		"Dot#0":           "Dot",
+		"EndOfLine#0":     "EndOfLine",
+		"FoldedString#0":  "FoldedString",
+		"Open#0":          "Open",
+		"Quantifier#0":    "Quantifier",
+		"Regex#0":         "Regex",
+		"String#0":        "String",
+		"Text#0":          "Text",
+		"Token#0":         "Token",
+	}
+	nodeShapes = map[string]nodeShape{}
+)
+
+// Parse matches the longest prefix of the data with Text rule
+func Parse(data []byte) (*parser.ParsingNode, error) { return parse(data, false) }
+
+// ParseFull matches the whole data with Text rule
+func ParseFull(data []byte) (*parser.ParsingNode, error) { return parse(data, true) }
+
+func parse(data []byte, full bool) (*parser.ParsingNode, error) {
+	p := &state{data: data, memo: make(map[cell]step), versions: make(map[cell][]version)}
+	rootStep := p.at(0, root)
+	if !rootStep.ok {
+		return nil, fmt.Errorf("%w: rule Text doesn't match", parser.TextNotMatchErr)
+	}
+	if full && rootStep.advance != len(data) {
+		return nil, fmt.Errorf("%w: rule Text matched only first %v elements out of %v", parser.TextNotMatchErr, rootStep.advance, len(data))
+	}
+	nodes := transform(p.build(rootStep))
+	if len(nodes) != 1 {
+		return nil, fmt.Errorf("tree with multiple root was formed")
+	}
+	return nodes[0], nil
+}
+
+func (p *state) at(i, s int) step {
+	key := cell{position: i, rule: s}
+	if result, ok := p.memo[key]; ok {
+		return result
+	}
+	if !recursive[s] {
+		result := p.evaluate(i, s)
+		p.memo[key] = result
+		return result
+	}
+	group := components[component[s]]
+	for _, r := range group {
+		p.memo[cell{position: i, rule: r}] = step{}
+		p.versions[cell{position: i, rule: r}] = []version{{seq: -1}}
+	}
+	for grown := true; grown; {
+		grown = false
+		for _, r := range group {
+			p.seq++
+			seq := p.seq
+			next := p.evaluate(i, r)
+			if current := p.memo[cell{position: i, rule: r}]; next.ok && (!current.ok || next.advance > current.advance) {
+				p.memo[cell{position: i, rule: r}] = next
+				p.versions[cell{position: i, rule: r}] = append(p.versions[cell{position: i, rule: r}], version{seq: seq, step: next})
+				grown = true
+			}
+		}
+	}
+	return p.memo[key]
+}
+
+func (p *state) versionBefore(i, s, seq int) (step, int) {
+	versions := p.versions[cell{position: i, rule: s}]
+	for k := len(versions) - 1; k >= 0; k-- {
+		if versions[k].seq < seq {
+			return versions[k].step, versions[k].seq
+		}
+	}
+	panic(fmt.Errorf("left-recursive cell %v has no versions before %v", cell{position: i, rule: s}, seq))
+}
+
+func (p *state) evaluate(i, s int) step {
+	switch s {
+	case 0:
+		return p.rule0(i)
+	case 1:
+		return p.rule1(i)
+	case 2:
+		return p.rule2(i)
+	case 3:
+		return p.rule3(i)
+	case 4:
+		return p.rule4(i)
+	case 5:
+		return p.rule5(i)
+	case 6:
+		return p.rule6(i)
+	case 7:
+		return p.rule7(i)
+	case 8:
+		return p.rule8(i)
+	case 9:
+		return p.rule9(i)
+	case 10:
+		return p.rule10(i)
+	case 11:
+		return p.rule11(i)
+	case 12:
+		return p.rule12(i)
+	case 13:
+		return p.rule13(i)
+	case 14:
+		return p.rule14(i)
+	case 15:
+		return p.rule15(i)
+	case 16:
+		return p.rule16(i)
+	case 17:
+		return p.rule17(i)
+	case 18:
+		return p.rule18(i)
+	case 19:
+		return p.rule19(i)
+	case 20:
+		return p.rule20(i)
+	case 21:
+		return p.rule21(i)
+	case 22:
+		return p.rule22(i)
+	case 23:
+		return p.rule23(i)
+	case 24:
+		return p.rule24(i)
+	case 25:
+		return p.rule25(i)
+	case 26:
+		return p.rule26(i)
+	case 27:
+		return p.rule27(i)
+	case 28:
+		return p.rule28(i)
+	case 29:
+		return p.rule29(i)
+	case 30:
+		return p.rule30(i)
+	case 31:
+		return p.rule31(i)
+	case 32:
+		return p.rule32(i)
+	case 33:
+		return p.rule33(i)
+	case 34:
+		return p.rule34(i)
+	case 35:
+		return p.rule35(i)
+	case 36:
+		return p.rule36(i)
+	}
+	panic(fmt.Errorf("unexpected rule: %v", s))
+}
+
+func (p *state) terminal(i, k int) step {
+	switch k {
+	case 0:
+		return p.terminal0(i)
+	case 1:
+		return p.terminal1(i)
+	case 2:
+		return p.terminal2(i)
+	case 3:
+		return p.terminal3(i)
+	case 4:
+		return p.terminal4(i)
+	case 5:
+		return p.terminal5(i)
+	case 6:
+		return p.terminal6(i)
+	case 7:
+		return p.terminal7(i)
+	case 8:
+		return p.terminal8(i)
+	case 9:
+		return p.terminal9(i)
+	case 10:
+		return p.terminal10(i)
+	case 11:
+		return p.terminal11(i)
+	case 12:
+		return p.terminal12(i)
+	case 13:
+		return p.terminal13(i)
+	case 14:
+		return p.terminal14(i)
+	case 15:
+		return p.terminal15(i)
+	case 16:
+		return p.terminal16(i)
+	case 17:
+		return p.terminal17(i)
+	case 18:
+		return p.terminal18(i)
+	case 19:
+		return p.terminal19(i)
+	case 20:
+		return p.terminal20(i)
+	case 21:
+		return p.terminal21(i)
+	}
+	panic(fmt.Errorf("unexpected terminal: %v", k))
+}
+
+// rule0 evaluates Regex#0: String#0
+func (p *state) rule0(i int) step {
+	return p.at(i, 33)
+}
+
+// rule1 evaluates #Sequence#19: #Sequence#18*
+func (p *state) rule1(i int) step {
+	current := i
+	for {
+		next := p.at(current, 2)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule2 evaluates #Sequence#18: =~"^[\t\r ]+" / =~"^//[^\n]+" / #Sequence#13 / #Sequence#14 / FoldedString#0 / String#0 / CharClass#0 / Token#0 / Quantifier#0 / Control#0 / BuiltinSymbol#0 / Annotation#0 / Dot#0 / #Sequence#17
+func (p *state) rule2(i int) step {
+	if next := p.terminal0(i); next.ok {
+		return next
+	}
+	if next := p.terminal1(i); next.ok {
+		return next
+	}
+	if next := p.at(i, 7); next.ok {
+		return next
+	}
+	if next := p.at(i, 6); next.ok {
+		return next
+	}
+	if next := p.at(i, 34); next.ok {
+		return next
+	}
+	if next := p.at(i, 33); next.ok {
+		return next
+	}
+	if next := p.at(i, 32); next.ok {
+		return next
+	}
+	if next := p.at(i, 31); next.ok {
+		return next
+	}
+	if next := p.at(i, 30); next.ok {
+		return next
+	}
+	if next := p.at(i, 29); next.ok {
+		return next
+	}
+	if next := p.at(i, 28); next.ok {
+		return next
+	}
+	if next := p.at(i, 27); next.ok {
+		return next
+	}
+	if next := p.at(i, 26); next.ok {
+		return next
+	}
+	if next := p.at(i, 3); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule3 evaluates #Sequence#17: Open#0 #Sequence#16 Close#0
+func (p *state) rule3(i int) step {
+	current := i
+	if next := p.at(current, 25); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 4); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 16); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule4 evaluates #Sequence#16: #Sequence#15*
+func (p *state) rule4(i int) step {
+	current := i
+	for {
+		next := p.at(current, 5)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule5 evaluates #Sequence#15: #Sequence#0 / "\n"
+func (p *state) rule5(i int) step {
+	if next := p.at(i, 22); next.ok {
+		return next
+	}
+	if next := p.terminal2(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule6 evaluates #Sequence#14: "=~" Regex#0
+func (p *state) rule6(i int) step {
+	current := i
+	if next := p.terminal3(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 0); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule7 evaluates #Sequence#13: "/*" #Sequence#12 "*/"
+func (p *state) rule7(i int) step {
+	current := i
+	if next := p.terminal4(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 8); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal5(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule8 evaluates #Sequence#12: #Sequence#11*
+func (p *state) rule8(i int) step {
+	current := i
+	for {
+		next := p.at(current, 9)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule9 evaluates #Sequence#11: #Sequence#10 .
+func (p *state) rule9(i int) step {
+	current := i
+	if next := p.at(current, 10); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal6(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule10 evaluates #Sequence#10: !"*/"
+func (p *state) rule10(i int) step {
+	if p.terminal5(i).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule11 evaluates #Sequence#7: #Sequence#6*
+func (p *state) rule11(i int) step {
+	current := i
+	for {
+		next := p.at(current, 12)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule12 evaluates #Sequence#6: #Sequence#0 / "\n"
+func (p *state) rule12(i int) step {
+	if next := p.at(i, 22); next.ok {
+		return next
+	}
+	if next := p.terminal2(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule13 evaluates #Sequence#3: #Sequence#2*
+func (p *state) rule13(i int) step {
+	current := i
+	for {
+		next := p.at(current, 14)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule14 evaluates #Sequence#2: #Sequence#1 .
+func (p *state) rule14(i int) step {
+	current := i
+	if next := p.at(current, 15); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal6(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule15 evaluates #Sequence#1: !"*/"
+func (p *state) rule15(i int) step {
+	if p.terminal5(i).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule16 evaluates Close#0: ")"
+func (p *state) rule16(i int) step {
+	return p.terminal7(i)
+}
+
+// rule17 evaluates Text#0: Text#2*
+func (p *state) rule17(i int) step {
+	current := i
+	for {
+		next := p.at(current, 18)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule18 evaluates Text#2: Text#1 EndOfLine#0
+func (p *state) rule18(i int) step {
+	current := i
+	if next := p.at(current, 21); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 19); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule19 evaluates EndOfLine#0: "\n" / EndOfLine#1
+func (p *state) rule19(i int) step {
+	if next := p.terminal2(i); next.ok {
+		return next
+	}
+	if next := p.at(i, 20); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule20 evaluates EndOfLine#1: !.
+func (p *state) rule20(i int) step {
+	if p.terminal6(i).ok {
+		return step{}
+	}
+	return step{ok: true}
+}
+
+// rule21 evaluates Text#1: #Sequence#0*
+func (p *state) rule21(i int) step {
+	current := i
+	for {
+		next := p.at(current, 22)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule22 evaluates #Sequence#0: #Sequence#9 #Sequence#19
+func (p *state) rule22(i int) step {
+	current := i
+	if next := p.at(current, 23); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 1); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule23 evaluates #Sequence#9: =~"^[\t\r ]+" / =~"^//[^\n]+" / #Sequence#4 / #Sequence#5 / FoldedString#0 / String#0 / CharClass#0 / Token#0 / Quantifier#0 / Control#0 / BuiltinSymbol#0 / Annotation#0 / Dot#0 / #Sequence#8
+func (p *state) rule23(i int) step {
+	if next := p.terminal0(i); next.ok {
+		return next
+	}
+	if next := p.terminal1(i); next.ok {
+		return next
+	}
+	if next := p.at(i, 36); next.ok {
+		return next
+	}
+	if next := p.at(i, 35); next.ok {
+		return next
+	}
+	if next := p.at(i, 34); next.ok {
+		return next
+	}
+	if next := p.at(i, 33); next.ok {
+		return next
+	}
+	if next := p.at(i, 32); next.ok {
+		return next
+	}
+	if next := p.at(i, 31); next.ok {
+		return next
+	}
+	if next := p.at(i, 30); next.ok {
+		return next
+	}
+	if next := p.at(i, 29); next.ok {
+		return next
+	}
+	if next := p.at(i, 28); next.ok {
+		return next
+	}
+	if next := p.at(i, 27); next.ok {
+		return next
+	}
+	if next := p.at(i, 26); next.ok {
+		return next
+	}
+	if next := p.at(i, 24); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule24 evaluates #Sequence#8: Open#0 #Sequence#7 Close#0
+func (p *state) rule24(i int) step {
+	current := i
+	if next := p.at(current, 25); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 11); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 16); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule25 evaluates Open#0: "("
+func (p *state) rule25(i int) step {
+	return p.terminal8(i)
+}
+
+// rule26 evaluates Dot#0: "."
+func (p *state) rule26(i int) step {
+	return p.terminal9(i)
+}
+
+// rule27 evaluates Annotation#0: =~"^@[a-z][a-z-]*(\\([^()\\n]*\\))?"
+func (p *state) rule27(i int) step {
+	return p.terminal10(i)
+}
+
+// rule28 evaluates BuiltinSymbol#0: "@sof" / "@eof" / "@invalid-utf8"
+func (p *state) rule28(i int) step {
+	if next := p.terminal11(i); next.ok {
+		return next
+	}
+	if next := p.terminal12(i); next.ok {
+		return next
+	}
+	if next := p.terminal13(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule29 evaluates Control#0: [:/*+?{},!&~<>]
+func (p *state) rule29(i int) step {
+	return p.terminal14(i)
+}
+
+// rule30 evaluates Quantifier#0: =~"^\\{[0-9]+(,[0-9]*)?\\}"
+func (p *state) rule30(i int) step {
+	return p.terminal15(i)
+}
+
+// rule31 evaluates Token#0: =~"^[#a-zA-Z][0-9a-zA-Z_]*(\\.[a-zA-Z][0-9a-zA-Z_]*)?"
+func (p *state) rule31(i int) step {
+	return p.terminal16(i)
+}
+
+// rule32 evaluates CharClass#0: =~"^\\[(\\\\.|[^\\]\\\\\\n])+\\]"
+func (p *state) rule32(i int) step {
+	return p.terminal17(i)
+}
+
+// rule33 evaluates String#0: =~"^\"(\\\\.|[^\"\\\\])*\"" / =~"^`[^`]*`"
+func (p *state) rule33(i int) step {
+	if next := p.terminal18(i); next.ok {
+		return next
+	}
+	if next := p.terminal19(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule34 evaluates FoldedString#0: =~"^\"(\\\\.|[^\"\\\\])*\"i\\b" / =~"^`[^`]*`i\\b"
+func (p *state) rule34(i int) step {
+	if next := p.terminal20(i); next.ok {
+		return next
+	}
+	if next := p.terminal21(i); next.ok {
+		return next
+	}
+	return step{}
+}
+
+// rule35 evaluates #Sequence#5: "=~" Regex#0
+func (p *state) rule35(i int) step {
+	current := i
+	if next := p.terminal3(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 0); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// rule36 evaluates #Sequence#4: "/*" #Sequence#3 "*/"
+func (p *state) rule36(i int) step {
+	current := i
+	if next := p.terminal4(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.at(current, 13); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	if next := p.terminal5(current); !next.ok {
+		return step{}
+	} else {
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i}
+}
+
+// terminal0 matches =~"^[\t\r ]+"
+func (p *state) terminal0(i int) step {
+	location := pattern0.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal1 matches =~"^//[^\n]+"
+func (p *state) terminal1(i int) step {
+	location := pattern1.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal2 matches "\n"
+func (p *state) terminal2(i int) step {
+	if bytes.HasPrefix(p.data[i:], token2) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal3 matches "=~"
+func (p *state) terminal3(i int) step {
+	if bytes.HasPrefix(p.data[i:], token3) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal4 matches "/*"
+func (p *state) terminal4(i int) step {
+	if bytes.HasPrefix(p.data[i:], token4) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal5 matches "*/"
+func (p *state) terminal5(i int) step {
+	if bytes.HasPrefix(p.data[i:], token5) {
+		return step{ok: true, advance: 2}
+	}
+	return step{}
+}
+
+// terminal6 matches .
+func (p *state) terminal6(i int) step {
+	if i < len(p.data) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal7 matches ")"
+func (p *state) terminal7(i int) step {
+	if bytes.HasPrefix(p.data[i:], token7) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal8 matches "("
+func (p *state) terminal8(i int) step {
+	if bytes.HasPrefix(p.data[i:], token8) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal9 matches "."
+func (p *state) terminal9(i int) step {
+	if bytes.HasPrefix(p.data[i:], token9) {
+		return step{ok: true, advance: 1}
+	}
+	return step{}
+}
+
+// terminal10 matches =~"^@[a-z][a-z-]*(\\([^()\\n]*\\))?"
+func (p *state) terminal10(i int) step {
+	location := pattern10.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal11 matches "@sof"
+func (p *state) terminal11(i int) step {
+	if bytes.HasPrefix(p.data[i:], token11) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal12 matches "@eof"
+func (p *state) terminal12(i int) step {
+	if bytes.HasPrefix(p.data[i:], token12) {
+		return step{ok: true, advance: 4}
+	}
+	return step{}
+}
+
+// terminal13 matches "@invalid-utf8"
+func (p *state) terminal13(i int) step {
+	if bytes.HasPrefix(p.data[i:], token13) {
+		return step{ok: true, advance: 13}
+	}
+	return step{}
+}
+
+// terminal14 matches [:/*+?{},!&~<>]
+func (p *state) terminal14(i int) step {
+	advance, ok := charClass14.Match(p.data[i:])
+	return step{ok: ok, advance: advance}
+}
+
+// terminal15 matches =~"^\\{[0-9]+(,[0-9]*)?\\}"
+func (p *state) terminal15(i int) step {
+	location := pattern15.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal16 matches =~"^[#a-zA-Z][0-9a-zA-Z_]*(\\.[a-zA-Z][0-9a-zA-Z_]*)?"
+func (p *state) terminal16(i int) step {
+	location := pattern16.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal17 matches =~"^\\[(\\\\.|[^\\]\\\\\\n])+\\]"
+func (p *state) terminal17(i int) step {
+	location := pattern17.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal18 matches =~"^\"(\\\\.|[^\"\\\\])*\""
+func (p *state) terminal18(i int) step {
+	location := pattern18.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal19 matches =~"^`[^`]*`"
+func (p *state) terminal19(i int) step {
+	location := pattern19.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal20 matches =~"^\"(\\\\.|[^\"\\\\])*\"i\\b"
+func (p *state) terminal20(i int) step {
+	location := pattern20.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+// terminal21 matches =~"^`[^`]*`i\\b"
+func (p *state) terminal21(i int) step {
+	location := pattern21.FindIndex(p.data[i:])
+	if location == nil || location[0] != 0 {
+		return step{}
+	}
+	return step{ok: true, advance: location[1]}
+}
+
+func (p *state) derive(parent frame, i int, l leaf) (step, int) {
+	if l.rule < 0 {
+		return p.terminal(i, l.terminal), 0
+	}
+	if !recursive[l.rule] {
+		return p.at(i, l.rule), 0
+	}
+	limit := math.MaxInt
+	if i == parent.node.Segment.Start && component[l.rule] == component[parent.rule] {
+		limit = parent.seq
+	}
+	p.at(i, l.rule)
+	return p.versionBefore(i, l.rule, limit)
+}
+
+func (p *state) build(rootStep step) *parser.ParsingNode {
+	rootNode := parser.NewParsingNode[byte](names[root], nil, p.data, definition.Segment{Start: 0, End: rootStep.advance})
+	rootFrame := frame{node: &rootNode, rule: root, seq: math.MaxInt}
+	if recursive[root] {
+		_, rootFrame.seq = p.versionBefore(0, root, math.MaxInt)
+	}
+	derivation := []frame{rootFrame}
+	for k := 0; k < len(derivation); k++ {
+		current := derivation[k]
+		addChild := func(l leaf, segment definition.Segment, seq int) {
+			if l.rule < 0 {
+				return
+			}
+			next := parser.NewParsingNode[byte](l.name, l.attributes, p.data, segment)
+			current.node.Children = append(current.node.Children, &next)
+			derivation = append(derivation, frame{node: &next, rule: l.rule, seq: seq})
+		}
+		start := current.node.Segment.Start
+		rule := shapes[current.rule]
+		switch rule.kind {
+		case kindSymbol:
+			next, seq := p.derive(current, start, rule.leaves[0])
+			if next.ok {
+				addChild(rule.leaves[0], current.node.Segment, seq)
+			}
+		case kindKleene:
+			for position := start; ; {
+				next, seq := p.derive(current, position, rule.leaves[0])
+				if !next.ok || next.advance == 0 {
+					break
+				}
+				addChild(rule.leaves[0], definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindJunction:
+			position := start
+			for _, l := range rule.leaves {
+				next, seq := p.derive(current, position, l)
+				addChild(l, definition.Segment{Start: position, End: position + next.advance}, seq)
+				position += next.advance
+			}
+		case kindChoice:
+			for _, l := range rule.leaves {
+				if next, seq := p.derive(current, start, l); next.ok {
+					addChild(l, definition.Segment{Start: start, End: start + next.advance}, seq)
+					break
+				}
+			}
+		}
+	}
+	return &rootNode
+}
+
+func transform(node *parser.ParsingNode) []*parser.ParsingNode {
+	name, ok := mapping[node.Atom.Symbol]
+	symbol, hidden := definition.AnalyzeSymbolName(name)
+	shape := nodeShapes[name]
+	if !ok || hidden || shape.inline {
+		nodes := make([]*parser.ParsingNode, 0, len(node.Children))
+		for _, child := range node.Children {
+			nodes = append(nodes, transform(child)...)
+		}
+		return nodes
+	}
+	if shape.drop {
+		return nil
+	}
+	atom := node.Atom
+	atom.Symbol = symbol
+	if shape.rename != "" {
+		atom.Symbol = shape.rename
+	}
+	next := parser.ParsingNode{Atom: atom, Segment: node.Segment}
+	if shape.token {
+		return []*parser.ParsingNode{&next}
+	}
+	for _, child := range node.Children {
+		for _, transformed := range transform(child) {
+			if shape.flatten && transformed.Atom.Symbol == next.Atom.Symbol {
+				next.Children = append(next.Children, transformed.Children...)
+				continue
+			}
+			next.Children = append(next.Children, transformed)
+		}
+	}
+	if shape.collapse && len(next.Children) == 1 {
+		return next.Children
+	}
+	return []*parser.ParsingNode{&next}
+}
@@ -1,7 +1,7 @@
 package highlight
 
 import (
-	_ "embed"
+	"embed"
 	"fmt"
 	"strings"
 
@@ -11,68 +11,100 @@ import (
 )
 
 var (
+	// tokenizers holds all grammars in order to resolve imports of the rules shared by the tokenizers
+	//go:embed *.peg
+	tokenizers embed.FS
 	//go:embed python-tokenizer.peg
-	PythonTokenizer      string
-	PythonTokenizerRules definition.Rules
+	PythonTokenizer        string
+	PythonTokenizerRules   definition.Rules
+	PythonTokenizerGrammar *parser.Grammar
 	//go:embed c-tokenizer.peg
-	CTokenizer      string
-	CTokenizerRules definition.Rules
+	CTokenizer        string
+	CTokenizerRules   definition.Rules
+	CTokenizerGrammar *parser.Grammar
 	//go:embed rust-tokenizer.peg
-	RustTokenizer      string
-	RustTokenizerRules definition.Rules
+	RustTokenizer        string
+	RustTokenizerRules   definition.Rules
+	RustTokenizerGrammar *parser.Grammar
 	//go:embed shell-tokenizer.peg
-	ShellTokenizer      string
-	ShellTokenizerRules definition.Rules
+	ShellTokenizer        string
+	ShellTokenizerRules   definition.Rules
+	ShellTokenizerGrammar *parser.Grammar
 	//go:embed go-tokenizer.peg
-	GoTokenizer      string
-	GoTokenizerRules definition.Rules
+	GoTokenizer        string
+	GoTokenizerRules   definition.Rules
+	GoTokenizerGrammar *parser.Grammar
 	//go:embed zig-tokenizer.peg
-	ZigTokenizer      string
-	ZigTokenizerRules definition.Rules
+	ZigTokenizer        string
+	ZigTokenizerRules   definition.Rules
+	ZigTokenizerGrammar *parser.Grammar
 	//go:embed asm-tokenizer.peg
-	AsmTokenizer      string
-	AsmTokenizerRules definition.Rules
+	AsmTokenizer        string
+	AsmTokenizerRules   definition.Rules
+	AsmTokenizerGrammar *parser.Grammar
 )
 
 func init() {
-	var err error
-	PythonTokenizerRules, err = extension.Load(PythonTokenizer)
-	if err != nil {
-		panic(fmt.Errorf("unable to load PythonTokenizer rules: %w", err))
-	}
-	CTokenizerRules, err = extension.Load(CTokenizer)
-	if err != nil {
-		panic(fmt.Errorf("unable to load CTokenizer rules: %w", err))
-	}
-	RustTokenizerRules, err = extension.Load(RustTokenizer)
-	if err != nil {
-		panic(fmt.Errorf("unable to load RustTokenizer rules: %w", err))
-	}
-	ShellTokenizerRules, err = extension.Load(ShellTokenizer)
+	PythonTokenizerRules, PythonTokenizerGrammar = mustCompile("python-tokenizer.peg")
+	CTokenizerRules, CTokenizerGrammar = mustCompile("c-tokenizer.peg")
+	RustTokenizerRules, RustTokenizerGrammar = mustCompile("rust-tokenizer.peg")
+	ShellTokenizerRules, ShellTokenizerGrammar = mustCompile("shell-tokenizer.peg")
+	GoTokenizerRules, GoTokenizerGrammar = mustCompile("go-tokenizer.peg")
+	AsmTokenizerRules, AsmTokenizerGrammar = mustCompile("asm-tokenizer.peg")
+	ZigTokenizerRules, ZigTokenizerGrammar = mustCompile("zig-tokenizer.peg")
+}
+
+func mustCompile(name string) (definition.Rules, *parser.Grammar) {
+	rules, err := extension.LoadFS(tokenizers, name)
 	if err != nil {
-		panic(fmt.Errorf("unable to load ShellTokenizer rules: %w", err))
+		panic(fmt.Errorf("unable to load %v rules: %w", name, err))
 	}
-	GoTokenizerRules, err = extension.Load(GoTokenizer)
+	grammar, err := compile(rules)
 	if err != nil {
-		panic(fmt.Errorf("unable to load GoTokenizer rules: %w", err))
+		panic(fmt.Errorf("unable to compile %v rules: %w", name, err))
 	}
-	AsmTokenizerRules, err = extension.Load(AsmTokenizer)
-	if err != nil {
-		panic(fmt.Errorf("unable to load AsmTokenizer rules: %w", err))
+	precompiled[identify(rules)] = grammar
+	return rules, grammar
+}
+
+func compile(tokenRules definition.Rules) (*parser.Grammar, error) {
+	return parser.Compile(tokenRules, parser.WithMatchMode(parser.FullMatch), parser.WithUTF8())
+}
+
+// rulesIdentity identifies the rules slice by its backing array, so only the exported tokenizer rules hit the precompiled grammars
+type rulesIdentity struct {
+	first  *definition.Rule
+	length int
+}
+
+func identify(rules definition.Rules) rulesIdentity {
+	return rulesIdentity{first: &rules[0], length: len(rules)}
+}
+
+// precompiled holds grammars of the exported tokenizer rules; it is filled in the init function and never changes afterwards
+var precompiled = make(map[rulesIdentity]*parser.Grammar)
+
+// Highlight wraps tokens into html tags; the first rule is the root which must match the whole document.
+// Grammars of the exported tokenizer rules are compiled once while other rules are compiled on every call (use HighlightGrammar to avoid this)
+func Highlight(text string, tokenRules definition.Rules) (string, error) {
+	if len(tokenRules) == 0 {
+		return "", fmt.Errorf("unable to highlight without token rules")
 	}
-	ZigTokenizerRules, err = extension.Load(ZigTokenizer)
-	if err != nil {
-		panic(fmt.Errorf("unable to load ZigTokenizer rules: %w", err))
+	grammar, ok := precompiled[identify(tokenRules)]
+	if !ok {
+		var err error
+		if grammar, err = compile(tokenRules); err != nil {
+			return "", fmt.Errorf("unable to compile token rules for highlight: root=%v, err=%w", tokenRules[0].Name, err)
+		}
 	}
+	return HighlightGrammar(text, grammar)
 }
 
-func Highlight(text string, tokenRules definition.Rules) (string, error) {
-	tokens, err := parser.ParseText(tokenRules, tokenRules[0].Name, []byte(text))
+// HighlightGrammar wraps tokens into html tags; grammar must be compiled with full match mode in order to highlight whole document
+func HighlightGrammar(text string, grammar *parser.Grammar) (string, error) {
+	tokens, err := grammar.ParseText([]byte(text))
 	if err != nil {
-		return "", fmt.Errorf("unable to parse tokens for highlight: root=%v, err=%w", tokenRules[0].Name, err)
-	}
-	if tokens.Segment.Length() != len(text) {
-		return "", fmt.Errorf("unable to highlight whole document, matched only first %v symbols: root=%v, err=%w", tokens.Segment.Length(), tokenRules[0].Name, err)
+		return "", fmt.Errorf("unable to parse tokens for highlight: %w", err)
 	}
 	result := strings.Builder{}
 	tokens.Traverse(func(node *parser.ParsingNode, next func(nodes []*parser.ParsingNode)) {
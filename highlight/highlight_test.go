@@ -2,9 +2,14 @@ package highlight
 
 import (
 	_ "embed"
+	"slices"
 	"testing"
+	"unicode/utf8"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/parser"
 )
 
 func TestPython(t *testing.T) {
@@ -33,6 +38,28 @@ func TestRust(t *testing.T) {
 	require.Equal(t, `<span class="keyword">fn</span> <span class="function">main</span>() { <span class="identifier">println</span>!(<span class="string">"Hello, world!"</span>); }`, highlighted)
 }
 
+func TestHighlightGrammar(t *testing.T) {
+	text := `fn main() { println!("Hello, world!"); }`
+	expected, err := Highlight(text, RustTokenizerRules)
+	require.Nil(t, err)
+	highlighted, err := HighlightGrammar(text, RustTokenizerGrammar)
+	require.Nil(t, err)
+	require.Equal(t, expected, highlighted)
+}
+
+func TestPrecompiled(t *testing.T) {
+	require.Len(t, precompiled, 7)
+	require.Same(t, GoTokenizerGrammar, precompiled[identify(GoTokenizerRules)])
+	copied := slices.Clone(GoTokenizerRules)
+	_, ok := precompiled[identify(copied)]
+	require.False(t, ok)
+	expected, err := Highlight("x := 1", GoTokenizerRules)
+	require.Nil(t, err)
+	highlighted, err := Highlight("x := 1", copied)
+	require.Nil(t, err)
+	require.Equal(t, expected, highlighted)
+}
+
 func TestShell(t *testing.T) {
 	highlighted, err := Highlight(`$> echo hi
 123`, ShellTokenizerRules)
@@ -78,3 +105,31 @@ RET`, AsmTokenizerRules)
 <span class="keyword">XORL</span>     AX, AX
 <span class="keyword">RET</span>`, highlighted)
 }
+
+func TestAsmCharacters(t *testing.T) {
+	text := []byte("FUNCDATA $0, gclocals·g2BeySu+wFnoycgXfElmcg==(SB)")
+	tokens, err := AsmTokenizerGrammar.ParseText(text)
+	require.Nil(t, err)
+	tokens.Traverse(func(node *parser.ParsingNode, next func(nodes []*parser.ParsingNode)) {
+		require.True(t, utf8.Valid(text[node.Segment.Start:node.Segment.End]), "node %v splits characters", node.Segment)
+		next(node.Children)
+	})
+}
+
+func TestInvalidUTF8(t *testing.T) {
+	for _, rules := range []definition.Rules{
+		PythonTokenizerRules,
+		CTokenizerRules,
+		RustTokenizerRules,
+		ShellTokenizerRules,
+		GoTokenizerRules,
+		ZigTokenizerRules,
+		AsmTokenizerRules,
+	} {
+		highlighted, err := Highlight("x \xff y\n# \xc3 // \xfe", rules)
+		require.Nil(t, err)
+		require.Contains(t, highlighted, "\xff")
+		require.Contains(t, highlighted, "\xc3")
+		require.Contains(t, highlighted, "\xfe")
+	}
+}
@@ -0,0 +1,69 @@
+package highlight
+
+import (
+	_ "embed"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gopeg/parser"
+)
+
+//go:embed highlight.go
+var sample []byte
+
+type namedGrammar struct {
+	name    string
+	grammar *parser.Grammar
+}
+
+// grammars are compiled in init, so they can't be referenced from package variables
+func grammars() []namedGrammar {
+	return []namedGrammar{
+		{name: "python", grammar: PythonTokenizerGrammar},
+		{name: "c", grammar: CTokenizerGrammar},
+		{name: "rust", grammar: RustTokenizerGrammar},
+		{name: "shell", grammar: ShellTokenizerGrammar},
+		{name: "go", grammar: GoTokenizerGrammar},
+		{name: "zig", grammar: ZigTokenizerGrammar},
+		{name: "asm", grammar: AsmTokenizerGrammar},
+	}
+}
+
+var strategies = []struct {
+	name     string
+	strategy parser.Strategy
+}{
+	{name: "table", strategy: parser.TableStrategy},
+	{name: "lazy", strategy: parser.LazyStrategy},
+	{name: "vm", strategy: parser.VMStrategy},
+}
+
+func TestStrategies(t *testing.T) {
+	for _, grammar := range grammars() {
+		t.Run(grammar.name, func(t *testing.T) {
+			expected, err := grammar.grammar.ParseText(sample, parser.WithStrategy(parser.TableStrategy))
+			require.Nil(t, err)
+			for _, strategy := range strategies[1:] {
+				actual, err := grammar.grammar.ParseText(sample, parser.WithStrategy(strategy.strategy))
+				require.Nil(t, err)
+				require.Equal(t, expected, actual, strategy.name)
+			}
+		})
+	}
+}
+
+func BenchmarkStrategies(b *testing.B) {
+	for _, grammar := range grammars() {
+		for _, strategy := range strategies {
+			b.Run(grammar.name+"/"+strategy.name, func(b *testing.B) {
+				b.SetBytes(int64(len(sample)))
+				for i := 0; i < b.N; i++ {
+					if _, err := grammar.grammar.ParseText(sample, parser.WithStrategy(strategy.strategy)); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
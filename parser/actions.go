@@ -0,0 +1,143 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/sivukhin/gopeg/analysis"
+	"github.com/sivukhin/gopeg/definition"
+)
+
+type (
+	// Match describes the node for which semantic action is executed
+	Match struct {
+		Symbol     string
+		Text       []byte
+		Segment    definition.Segment
+		Attributes map[string][]byte
+		// Values holds results of the children in the order of their appearance
+		Values []any
+	}
+	Action  func(match Match) (any, error)
+	Actions map[string]Action
+)
+
+// evaluateActions computes values of the derivation subtree without building ParsingNode tree:
+// hidden rules, @inline rules and rules without actions are transparent and pass values of their children to the parent
+// while @drop rules produce no values. Other annotations follow the tree shape: actions are looked up by @rename names,
+// @token rules skip their children, @collapse-single-child rules pass the only value through
+// and children of the @flatten rule with its symbol (given as flattened) pass their values to it instead of running the action
+func evaluateActions[T any](e *evaluator[T], actions Actions, symbol definition.Symbol, frame derivationFrame, flattened string) ([]any, error) {
+	name, named := e.grammar.transformation.Backward[symbol.Name]
+	shape := e.grammar.shapes[name]
+	if named && shape.drop {
+		return nil, nil
+	}
+	visible, hidden := definition.AnalyzeSymbolName(name)
+	transparent := !named || hidden || shape.inline
+	if shape.rename != "" {
+		visible = shape.rename
+	}
+	childFlattened := flattened
+	if !transparent {
+		childFlattened = ""
+		if shape.flatten {
+			childFlattened = visible
+		}
+	}
+	values := make([]any, 0)
+	var err error
+	if transparent || !shape.token {
+		deriveChildren(e, frame, func(child definition.Symbol, next derivationFrame) {
+			if err != nil {
+				return
+			}
+			var childValues []any
+			childValues, err = evaluateActions(e, actions, child, next, childFlattened)
+			values = append(values, childValues...)
+		}, nil, func(definition.Segment, definition.Expr) {})
+	}
+	if err != nil {
+		return nil, err
+	}
+	if transparent || (shape.collapse && len(values) == 1) || visible == flattened {
+		return values, nil
+	}
+	action, hasAction := actions[visible]
+	if !hasAction {
+		return values, nil
+	}
+	atom := NewParsingNode[T](visible, symbol.Attributes, e.data, frame.segment).Atom
+	value, err := action(Match{
+		Symbol:     visible,
+		Text:       atom.SelectText(),
+		Segment:    frame.segment,
+		Attributes: symbol.Attributes,
+		Values:     values,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("action of %v[%v..%v) failed: %w", visible, frame.segment.Start, frame.segment.End, err)
+	}
+	return []any{value}, nil
+}
+
+func evaluate[T any](g *grammar, data []T, actions Actions, options parseOptions) (any, error) {
+	e, err := newEvaluator(g, data, options)
+	if err != nil {
+		return nil, err
+	}
+	rootRule, err := g.rootRule(options.root)
+	if err != nil {
+		return nil, err
+	}
+	rootStep := e.steps.stepAt(0, rootRule)
+	if e.err != nil {
+		return nil, e.err
+	}
+	if !rootStep.ok || (options.matchMode == FullMatch && rootStep.advance != len(data)) {
+		return nil, newParseError(e, rootRule, rootStep)
+	}
+	values, err := evaluateActions(e, actions, definition.NewSymbol(g.order[rootRule]), rootDerivationFrame(e, rootRule, rootStep), "")
+	if err != nil {
+		return nil, err
+	}
+	if len(values) != 1 {
+		return nil, fmt.Errorf("root rule must produce exactly one value, got %v", len(values))
+	}
+	return values[0], nil
+}
+
+func evaluateCompiled[T any](g *Grammar, terminalType analysis.TerminalType, data []T, actions Actions, opts []Option) (any, error) {
+	if err := g.grammar.checkTerminalType(terminalType); err != nil {
+		return nil, err
+	}
+	options, err := g.parseOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return evaluate(g.grammar, data, actions, options)
+}
+
+// EvaluateText parses the text and computes the value of the root rule with semantic actions registered by rule names
+func (g *Grammar) EvaluateText(text []byte, actions Actions, opts ...Option) (any, error) {
+	return evaluateCompiled(g, analysis.ByteTerminalType, text, actions, opts)
+}
+
+func (g *Grammar) EvaluateAtoms(atoms []definition.Atom, actions Actions, opts ...Option) (any, error) {
+	return evaluateCompiled(g, analysis.AtomTerminalType, atoms, actions, opts)
+}
+
+func EvaluateText(rules definition.Rules, root string, text []byte, actions Actions, opts ...Option) (any, error) {
+	g, err := Compile(rules)
+	if err != nil {
+		return nil, err
+	}
+	return g.EvaluateText(text, actions, withRoot(root, opts)...)
+}
+
+func EvaluateAtoms(rules definition.Rules, root string, atoms []definition.Atom, actions Actions, opts ...Option) (any, error) {
+	g, err := Compile(rules)
+	if err != nil {
+		return nil, err
+	}
+	return g.EvaluateAtoms(atoms, actions, withRoot(root, opts)...)
+}
@@ -0,0 +1,110 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/stretchr/testify/require"
+)
+
+type operand struct {
+	value   int
+	segment definition.Segment
+}
+
+// arithmeticActions evaluate arithmeticRules: operators are not named so they are taken from the text between operands
+var arithmeticActions = Actions{
+	"Expr": func(m Match) (any, error) {
+		return operand{value: m.Values[0].(operand).value, segment: m.Segment}, nil
+	},
+	"Sum":     foldOperands,
+	"Product": foldOperands,
+	"Value": func(m Match) (any, error) {
+		if len(m.Values) == 1 {
+			return operand{value: m.Values[0].(operand).value, segment: m.Segment}, nil
+		}
+		value, err := strconv.Atoi(string(m.Text))
+		return operand{value: value, segment: m.Segment}, err
+	},
+}
+
+func foldOperands(m Match) (any, error) {
+	previous := m.Values[0].(operand)
+	result := previous.value
+	for _, value := range m.Values[1:] {
+		current := value.(operand)
+		switch operator := m.Text[previous.segment.End-m.Segment.Start]; operator {
+		case '+':
+			result += current.value
+		case '-':
+			result -= current.value
+		case '*':
+			result *= current.value
+		case '/':
+			if current.value == 0 {
+				return nil, fmt.Errorf("division by zero")
+			}
+			result /= current.value
+		}
+		previous = current
+	}
+	return operand{value: result, segment: m.Segment}, nil
+}
+
+func TestEvaluate(t *testing.T) {
+	for text, expected := range map[string]int{
+		"10+2":            12,
+		"1+(2*4-5)-10*44": -436,
+		"((7))":           7,
+		"8/2/2":           2,
+	} {
+		t.Run(text, func(t *testing.T) {
+			for _, strategy := range []Strategy{TableStrategy, LazyStrategy, VMStrategy} {
+				value, err := EvaluateText(arithmeticRules, "Expr", []byte(text), arithmeticActions, WithStrategy(strategy), WithMatchMode(FullMatch))
+				require.Nil(t, err)
+				require.Equal(t, expected, value.(operand).value)
+			}
+		})
+	}
+}
+
+func TestEvaluateErrors(t *testing.T) {
+	_, err := EvaluateText(arithmeticRules, "Expr", []byte("1+(4/0)"), arithmeticActions)
+	require.ErrorContains(t, err, "action of Product[3..6) failed: division by zero")
+
+	_, err = EvaluateText(arithmeticRules, "Expr", []byte("1+"), arithmeticActions, WithMatchMode(FullMatch))
+	require.ErrorIs(t, err, TextNotMatchErr)
+
+	_, err = EvaluateText(arithmeticRules, "Expr", []byte("1+2"), Actions{"Value": arithmeticActions["Value"]})
+	require.ErrorContains(t, err, "root rule must produce exactly one value, got 2")
+}
+
+func TestEvaluateAnnotations(t *testing.T) {
+	shape := func(m Match) (any, error) {
+		if len(m.Values) == 0 {
+			return m.Symbol + ":" + string(m.Text), nil
+		}
+		children := make([]string, 0, len(m.Values))
+		for _, value := range m.Values {
+			children = append(children, value.(string))
+		}
+		return m.Symbol + "(" + strings.Join(children, " ") + ")", nil
+	}
+	actions := Actions{
+		"List":  shape,
+		"Item":  shape,
+		"Tuple": shape,
+		"Num":   shape,
+		"Digit": shape,
+		"Inner": func(Match) (any, error) { return nil, fmt.Errorf("renamed rule must be evaluated by its new name") },
+	}
+	text := []byte("1,23#x,(4,5#y)")
+	node, err := ParseText(annotatedRules, "List", text, WithMatchMode(FullMatch))
+	require.Nil(t, err)
+	value, err := EvaluateText(annotatedRules, "List", text, actions, WithMatchMode(FullMatch))
+	require.Nil(t, err)
+	require.Equal(t, shapeOf(node), value)
+}
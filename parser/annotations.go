@@ -0,0 +1,218 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+// nodeShape describes how transform changes nodes of the rule according to its annotations
+type nodeShape struct {
+	inline   bool
+	flatten  bool
+	collapse bool
+	token    bool
+	drop     bool
+	rename   string
+}
+
+func buildNodeShapes(rules definition.Rules) (map[string]nodeShape, error) {
+	shapes := make(map[string]nodeShape)
+	for _, rule := range rules {
+		if len(rule.Annotations) == 0 {
+			continue
+		}
+		var shape nodeShape
+		for _, annotation := range rule.Annotations {
+			if annotation.Name != definition.RenameAnnotation && annotation.Argument != "" {
+				return nil, fmt.Errorf("annotation %v of rule '%v' doesn't accept arguments", annotation, rule.Name)
+			}
+			switch annotation.Name {
+			case definition.InlineAnnotation:
+				shape.inline = true
+			case definition.FlattenAnnotation:
+				shape.flatten = true
+			case definition.CollapseSingleChildAnnotation:
+				shape.collapse = true
+			case definition.TokenAnnotation:
+				shape.token = true
+			case definition.DropAnnotation:
+				shape.drop = true
+			case definition.RenameAnnotation:
+				if annotation.Argument == "" {
+					return nil, fmt.Errorf("annotation %v of rule '%v' requires new name as argument", annotation, rule.Name)
+				}
+				shape.rename = annotation.Argument
+			default:
+				return nil, fmt.Errorf("unknown annotation %v of rule '%v'", annotation, rule.Name)
+			}
+		}
+		shapes[rule.Name] = shape
+	}
+	return shapes, nil
+}
+
+// flattenedSymbols collects symbols of the rules which merge children with the same symbol
+func flattenedSymbols(shapes map[string]nodeShape) map[string]bool {
+	symbols := make(map[string]bool)
+	for name, shape := range shapes {
+		if shape.flatten {
+			symbol, _ := definition.AnalyzeSymbolName(name)
+			if shape.rename != "" {
+				symbol = shape.rename
+			}
+			symbols[symbol] = true
+		}
+	}
+	return symbols
+}
+
+// kept reports whether the node of the normalized rule is kept in the transformed tree unless it is collapsed or merged into the flattened parent
+func (g *grammar) kept(normalized string, concrete bool) (string, bool) {
+	name, ok := g.transformation.Backward[normalized]
+	if !ok {
+		return "", false
+	}
+	symbol, hidden := definition.AnalyzeSymbolName(name)
+	shape := g.shapes[name]
+	if (hidden || shape.inline || shape.drop) && !concrete {
+		return "", false
+	}
+	if shape.rename != "" {
+		symbol = shape.rename
+	}
+	return symbol, !shape.collapse
+}
+
+// persistent reports whether the node of the normalized rule surely stays in the transformed tree:
+// collapsed nodes and nodes which can be merged into the flattened parent may disappear
+func (g *grammar) persistent(normalized string, concrete bool) bool {
+	symbol, ok := g.kept(normalized, concrete)
+	return ok && !g.flattened[symbol]
+}
+
+// pruned reports whether transform drops children of the node of the normalized rule
+func (g *grammar) pruned(normalized string, concrete bool) bool {
+	name, ok := g.transformation.Backward[normalized]
+	if !ok {
+		return false
+	}
+	_, hidden := definition.AnalyzeSymbolName(name)
+	shape := g.shapes[name]
+	if (hidden || shape.inline) && !concrete {
+		return false
+	}
+	return shape.token || (shape.drop && !concrete)
+}
+
+// treeLimits stops construction of the tree as soon as it exceeds the node count or depth limits:
+// nodes are counted only if they surely stay in the final tree and depth never exceeds the final one,
+// so the limits are never exceeded prematurely and the complete tree is verified precisely by checkTree
+type treeLimits struct {
+	maxNodes int
+	maxDepth int
+	nodes    int
+	err      error
+}
+
+func newTreeLimits(options parseOptions) *treeLimits {
+	if options.maxNodes == 0 && options.maxDepth == 0 {
+		return nil
+	}
+	return &treeLimits{maxNodes: options.maxNodes, maxDepth: options.maxDepth}
+}
+
+// add registers the node at the depth and reports whether construction can proceed
+func (l *treeLimits) add(depth int) bool {
+	if l == nil {
+		return true
+	}
+	if l.err != nil {
+		return false
+	}
+	l.nodes++
+	if l.maxNodes > 0 && l.nodes > l.maxNodes {
+		l.err = &LimitError{Kind: NodesLimit, Limit: l.maxNodes}
+	} else if l.maxDepth > 0 && depth > l.maxDepth {
+		l.err = &LimitError{Kind: DepthLimit, Limit: l.maxDepth}
+	}
+	return l.err == nil
+}
+
+func (l *treeLimits) remove() {
+	if l != nil {
+		l.nodes--
+	}
+}
+
+func (l *treeLimits) exceeded() error {
+	if l == nil {
+		return nil
+	}
+	return l.err
+}
+
+// transform replaces normalized rules with the original ones, removes nodes which are not visible to the user and applies annotations.
+// Concrete syntax tree keeps hidden, inlined and dropped rules as HiddenNode nodes in order to preserve the whole input.
+// Nodes are registered in limits when they are attached to the parent at the depth (the depth of the node itself is given),
+// so the root must be registered by the caller
+func (g *grammar) transform(node *ParsingNode, concrete bool, limits *treeLimits, depth int) []*ParsingNode {
+	if node.Kind == ErrorNode || node.Kind == AnonymousNode {
+		return []*ParsingNode{node}
+	}
+	name, ok := g.transformation.Backward[node.Atom.Symbol]
+	symbol, hidden := definition.AnalyzeSymbolName(name)
+	shape := g.shapes[name]
+	if !ok || ((hidden || shape.inline) && !concrete) {
+		nodes := make([]*ParsingNode, 0, len(node.Children))
+		for _, child := range node.Children {
+			if limits.exceeded() != nil {
+				return nil
+			}
+			nodes = append(nodes, g.transform(child, concrete, limits, depth)...)
+		}
+		return nodes
+	}
+	if shape.drop && !concrete {
+		return nil
+	}
+	atom := node.Atom
+	atom.Symbol = symbol
+	if shape.rename != "" {
+		atom.Symbol = shape.rename
+	}
+	next := ParsingNode{
+		Atom:     atom,
+		Segment:  node.Segment,
+		Children: nil,
+	}
+	if hidden || shape.inline || shape.drop {
+		next.Kind = HiddenNode
+	}
+	if shape.token {
+		return []*ParsingNode{&next}
+	}
+	// children of the collapsed node and merged children of the flattened node take place of their parent
+	childDepth := depth + 1
+	if shape.collapse || shape.flatten {
+		childDepth = depth
+	}
+	for _, child := range node.Children {
+		for _, transformed := range g.transform(child, concrete, limits, childDepth) {
+			if shape.flatten && transformed.Kind == next.Kind && transformed.Atom.Symbol == next.Atom.Symbol {
+				next.Children = append(next.Children, transformed.Children...)
+				continue
+			}
+			if !limits.add(childDepth) {
+				return nil
+			}
+			next.Children = append(next.Children, transformed)
+		}
+	}
+	if shape.collapse && len(next.Children) == 1 {
+		// the only child is registered again by the new parent
+		limits.remove()
+		return next.Children
+	}
+	return []*ParsingNode{&next}
+}
@@ -0,0 +1,83 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/stretchr/testify/require"
+)
+
+var annotatedRules = definition.Rules{
+	definition.NewAnnotatedRule("List", definition.NewChoice(
+		definition.NewJunction(definition.NewSymbol("Item"), definition.NewTextToken(","), definition.NewSymbol("List")),
+		definition.NewSymbol("Item"),
+	), definition.NewAnnotation(definition.FlattenAnnotation)),
+	definition.NewAnnotatedRule("Item", definition.NewChoice(
+		definition.NewSymbol("Value"),
+		definition.NewJunction(definition.NewTextToken("("), definition.NewSymbol("Inner"), definition.NewTextToken(")")),
+	), definition.NewAnnotation(definition.CollapseSingleChildAnnotation)),
+	definition.NewAnnotatedRule("Inner", definition.NewSymbol("List"), definition.NewAnnotation(definition.RenameAnnotation, "Tuple")),
+	definition.NewAnnotatedRule("Value", definition.NewJunction(
+		definition.NewSymbol("Number"),
+		definition.NewOptional(definition.NewSymbol("Comment")),
+	), definition.NewAnnotation(definition.InlineAnnotation)),
+	definition.NewAnnotatedRule("Number", definition.NewRepetitionN(definition.NewSymbol("Digit"), 1),
+		definition.NewAnnotation(definition.TokenAnnotation),
+		definition.NewAnnotation(definition.RenameAnnotation, "Num"),
+	),
+	definition.NewAnnotatedRule("Comment", definition.NewTextPattern("#[a-z]*"), definition.NewAnnotation(definition.DropAnnotation)),
+	definition.NewRule("Digit", definition.NewTextPattern("[0-9]")),
+}
+
+func shapeOf(node *ParsingNode) string {
+	if len(node.Children) == 0 {
+		return node.Atom.Symbol + ":" + node.Atom.SelectString()
+	}
+	children := make([]string, 0, len(node.Children))
+	for _, child := range node.Children {
+		children = append(children, shapeOf(child))
+	}
+	return node.Atom.Symbol + "(" + strings.Join(children, " ") + ")"
+}
+
+func TestAnnotations(t *testing.T) {
+	text := "1,23#x,(4,5#y)"
+	node, err := ParseText(annotatedRules, "List", []byte(text), WithMatchMode(FullMatch))
+	require.Nil(t, err)
+	require.Equal(t, "List(Num:1 Num:23 Tuple(List(Num:4 Num:5)))", shapeOf(node))
+
+	concrete, err := ParseText(annotatedRules, "List", []byte(text), WithMatchMode(FullMatch), WithConcreteSyntaxTree())
+	require.Nil(t, err)
+	require.Equal(t, text, concatLeaves(concrete))
+	comments := make([]*ParsingNode, 0)
+	concrete.Traverse(func(node *ParsingNode, next func(nodes []*ParsingNode)) {
+		if node.Atom.Symbol == "Comment" {
+			comments = append(comments, node)
+		}
+		next(node.Children)
+	})
+	require.Len(t, comments, 2)
+	require.Equal(t, HiddenNode, comments[0].Kind)
+}
+
+func TestAnnotationsWithActions(t *testing.T) {
+	value, err := EvaluateText(annotatedRules, "List", []byte("1#x,2"), Actions{
+		"Comment": func(m Match) (any, error) { return "comment", nil },
+		"Num":     func(m Match) (any, error) { return string(m.Text), nil },
+		"List":    func(m Match) (any, error) { return m.Values, nil },
+	})
+	require.Nil(t, err)
+	require.Equal(t, []any{"1", "2"}, value)
+}
+
+func TestInvalidAnnotations(t *testing.T) {
+	for _, annotation := range []definition.Annotation{
+		definition.NewAnnotation("unknown"),
+		definition.NewAnnotation(definition.RenameAnnotation),
+		definition.NewAnnotation(definition.TokenAnnotation, "X"),
+	} {
+		_, err := Compile(definition.Rules{definition.NewAnnotatedRule("A", definition.NewTextToken("a"), annotation)})
+		require.NotNil(t, err, annotation.String())
+	}
+}
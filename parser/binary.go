@@ -0,0 +1,215 @@
+package parser
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+const (
+	binaryMagic   = "GPEG"
+	binaryVersion = 1
+)
+
+// EncodeBinary serializes the tree in the compact binary format: header "GPEG" with version byte followed by the table of texts
+// and nodes in pre-order. Integers are encoded as uvarints and byte strings are prefixed with their length
+// (nullable values are shifted by one, so zero stands for nil)
+func EncodeBinary(node *ParsingNode) []byte {
+	var texts serializedTexts
+	nodes := encodeBinaryNode(nil, node, &texts)
+	data := append([]byte(binaryMagic), binaryVersion)
+	data = binary.AppendUvarint(data, uint64(len(texts.texts)))
+	for _, text := range texts.texts {
+		data = appendBinaryBytes(data, text)
+	}
+	return append(data, nodes...)
+}
+
+func appendBinaryBytes(data, value []byte) []byte {
+	data = binary.AppendUvarint(data, uint64(len(value)))
+	return append(data, value...)
+}
+
+func appendBinaryNullableBytes(data, value []byte) []byte {
+	if value == nil {
+		return binary.AppendUvarint(data, 0)
+	}
+	data = binary.AppendUvarint(data, uint64(len(value))+1)
+	return append(data, value...)
+}
+
+func encodeBinaryNode(data []byte, node *ParsingNode, texts *serializedTexts) []byte {
+	data = binary.AppendUvarint(data, uint64(node.Kind))
+	data = appendBinaryBytes(data, []byte(node.Atom.Symbol))
+	data = binary.AppendUvarint(data, uint64(node.Segment.Start))
+	data = binary.AppendUvarint(data, uint64(node.Segment.End))
+	data = binary.AppendUvarint(data, uint64(texts.index(node.Atom.Text)+1))
+	segments := node.Atom.TextSelector.List()
+	data = binary.AppendUvarint(data, uint64(len(segments)))
+	for _, segment := range segments {
+		data = binary.AppendUvarint(data, uint64(segment.Start))
+		data = binary.AppendUvarint(data, uint64(segment.End))
+	}
+	data = binary.AppendUvarint(data, uint64(len(node.Atom.Attributes)))
+	for _, key := range sortedAttributeKeys(node.Atom.Attributes) {
+		data = appendBinaryBytes(data, []byte(key))
+		data = appendBinaryNullableBytes(data, node.Atom.Attributes[key])
+	}
+	data = binary.AppendUvarint(data, uint64(len(node.Children)))
+	for _, child := range node.Children {
+		data = encodeBinaryNode(data, child, texts)
+	}
+	return data
+}
+
+var errBinaryTruncated = errors.New("data is truncated")
+
+type binaryReader struct {
+	data   []byte
+	offset int
+}
+
+func (r *binaryReader) uvarint() (int, error) {
+	value, size := binary.Uvarint(r.data[r.offset:])
+	if size == 0 {
+		return 0, errBinaryTruncated
+	}
+	if size < 0 || value > math.MaxInt {
+		return 0, fmt.Errorf("integer at offset %v is too large", r.offset)
+	}
+	r.offset += size
+	return int(value), nil
+}
+
+func (r *binaryReader) bytes(length int) ([]byte, error) {
+	if length > len(r.data)-r.offset {
+		return nil, errBinaryTruncated
+	}
+	value := r.data[r.offset : r.offset+length : r.offset+length]
+	r.offset += length
+	return value, nil
+}
+
+func (r *binaryReader) string() (string, error) {
+	length, err := r.uvarint()
+	if err != nil {
+		return "", err
+	}
+	value, err := r.bytes(length)
+	return string(value), err
+}
+
+func (r *binaryReader) nullableBytes() ([]byte, error) {
+	length, err := r.uvarint()
+	if err != nil || length == 0 {
+		return nil, err
+	}
+	value, err := r.bytes(length - 1)
+	if err != nil {
+		return nil, err
+	}
+	return append(make([]byte, 0, len(value)), value...), nil
+}
+
+// DecodeBinary restores the tree serialized with EncodeBinary; decoded texts are copied so the data can be reused afterward
+func DecodeBinary(data []byte) (*ParsingNode, error) {
+	if len(data) < len(binaryMagic)+1 || string(data[:len(binaryMagic)]) != binaryMagic {
+		return nil, fmt.Errorf("unable to decode binary tree: header is missing")
+	}
+	if version := data[len(binaryMagic)]; version != binaryVersion {
+		return nil, fmt.Errorf("unable to decode binary tree: unsupported version %v", version)
+	}
+	reader := &binaryReader{data: data, offset: len(binaryMagic) + 1}
+	count, err := reader.uvarint()
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode binary tree: %w", err)
+	}
+	texts := make([][]byte, 0, min(count, len(data)))
+	for i := 0; i < count; i++ {
+		length, err := reader.uvarint()
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode binary tree: %w", err)
+		}
+		text, err := reader.bytes(length)
+		if err != nil {
+			return nil, fmt.Errorf("unable to decode binary tree: %w", err)
+		}
+		texts = append(texts, append(make([]byte, 0, len(text)), text...))
+	}
+	node, err := decodeBinaryNode(reader, texts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode binary tree at offset %v: %w", reader.offset, err)
+	}
+	if reader.offset != len(data) {
+		return nil, fmt.Errorf("unable to decode binary tree: unexpected data at offset %v", reader.offset)
+	}
+	return node, nil
+}
+
+func decodeBinaryNode(reader *binaryReader, texts [][]byte) (*ParsingNode, error) {
+	kind, err := reader.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	symbol, err := reader.string()
+	if err != nil {
+		return nil, err
+	}
+	// segment start, segment end, text index shifted by one and amount of selector segments
+	header := make([]int, 4)
+	for i := range header {
+		if header[i], err = reader.uvarint(); err != nil {
+			return nil, err
+		}
+	}
+	start, end, textIndex, selectorLength := header[0], header[1], header[2]-1, header[3]
+	selector := make([]definition.Segment, 0, min(selectorLength, len(reader.data)))
+	for i := 0; i < selectorLength; i++ {
+		segmentStart, err := reader.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		segmentEnd, err := reader.uvarint()
+		if err != nil {
+			return nil, err
+		}
+		selector = append(selector, definition.Segment{Start: segmentStart, End: segmentEnd})
+	}
+	attributesLength, err := reader.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	var attributes map[string][]byte
+	if attributesLength > 0 {
+		attributes = make(map[string][]byte, min(attributesLength, len(reader.data)))
+	}
+	for i := 0; i < attributesLength; i++ {
+		key, err := reader.string()
+		if err != nil {
+			return nil, err
+		}
+		if attributes[key], err = reader.nullableBytes(); err != nil {
+			return nil, err
+		}
+	}
+	atom, err := newSerializedAtom(texts, symbol, attributes, textIndex, selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node %v: %w", symbol, err)
+	}
+	node := &ParsingNode{Kind: NodeKind(kind), Atom: atom, Segment: definition.Segment{Start: start, End: end}}
+	childrenLength, err := reader.uvarint()
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < childrenLength; i++ {
+		child, err := decodeBinaryNode(reader, texts)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, child)
+	}
+	return node, nil
+}
@@ -0,0 +1,79 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/stretchr/testify/require"
+)
+
+func concatLeaves(node *ParsingNode) string {
+	if len(node.Children) == 0 {
+		return node.Atom.SelectString()
+	}
+	text := ""
+	for _, child := range node.Children {
+		text += concatLeaves(child)
+	}
+	return text
+}
+
+var spacedRules = definition.Rules{
+	definition.NewRule("List", definition.NewJunction(
+		definition.NewSymbol("#Spaces"),
+		definition.NewSymbol("Item"),
+		definition.NewRepetition(definition.NewJunction(
+			definition.NewSymbol("#Spaces"),
+			definition.NewTextToken(","),
+			definition.NewSymbol("#Spaces"),
+			definition.NewSymbol("Item"),
+		)),
+		definition.NewSymbol("#Spaces"),
+	)),
+	definition.NewRule("#Spaces", definition.NewTextPattern("[ \n]*")),
+	definition.NewRule("Item", definition.NewTextPattern("[a-z]+")),
+}
+
+func TestConcreteSyntaxTree(t *testing.T) {
+	for _, strategy := range []Strategy{TableStrategy, LazyStrategy, VMStrategy} {
+		text := "1+(2*4-5)-10*44"
+		node, err := ParseText(arithmeticRules, "Expr", []byte(text), WithConcreteSyntaxTree(), WithStrategy(strategy))
+		require.Nil(t, err)
+		require.Equal(t, text, concatLeaves(node))
+		sum := node.MustSelectBySymbol("Sum")
+		require.Len(t, sum.Children, 5)
+		require.Equal(t, AnonymousNode, sum.Children[1].Kind)
+		require.Equal(t, `"+"`, sum.Children[1].Atom.Symbol)
+		require.Equal(t, "+", sum.Children[1].Atom.SelectString())
+
+		abstract, err := ParseText(arithmeticRules, "Expr", []byte(text), WithStrategy(strategy))
+		require.Nil(t, err)
+		require.Len(t, abstract.MustSelectBySymbol("Sum").Children, 3)
+	}
+}
+
+func TestConcreteHiddenRules(t *testing.T) {
+	text := " a, b ,c\n"
+	node, err := ParseText(spacedRules, "List", []byte(text), WithConcreteSyntaxTree(), WithMatchMode(FullMatch))
+	require.Nil(t, err)
+	require.Equal(t, text, concatLeaves(node))
+	require.Len(t, node.FilterBySymbol("Item"), 3)
+	hidden := node.FilterBySymbol("#Spaces")
+	require.Len(t, hidden, 6)
+	for _, child := range hidden {
+		require.Equal(t, HiddenNode, child.Kind)
+	}
+	require.Equal(t, "\n", hidden[5].Atom.SelectString())
+
+	abstract, err := ParseText(spacedRules, "List", []byte(text))
+	require.Nil(t, err)
+	require.Len(t, abstract.Children, 3)
+}
+
+func TestConcreteRecovery(t *testing.T) {
+	text := "1+2;1+*;3*4;5"
+	node, diagnostics, err := ParseTextWithRecovery(statementRules, "Statements", []byte(text), WithConcreteSyntaxTree())
+	require.Nil(t, err)
+	require.Len(t, diagnostics, 2)
+	require.Equal(t, text, concatLeaves(node))
+}
@@ -24,12 +24,18 @@ func isEmptyTerminal(expr definition.Terminals) bool {
 		return peg.Regex.Match([]byte{})
 	case definition.TextToken:
 		return len(peg.Text) == 0
+	case definition.FoldedToken:
+		return len(peg.Text) == 0
+	case definition.CharClass:
+		return false
 	case definition.AtomPattern:
 		return false
 	case definition.StartOfFile:
 		return true
 	case definition.EndOfFile:
 		return true
+	case definition.InvalidUTF8:
+		return false
 	default:
 		panic(fmt.Errorf("unexpected peg terminal type: %#v", expr))
 	}
@@ -38,31 +44,35 @@ func isEmptyTerminal(expr definition.Terminals) bool {
 func isEmptyExpr(expr definition.Expr, emptiness map[string]emptinessState) emptinessState {
 	switch peg := expr.(type) {
 	case definition.Junction:
+		state := ruleIsEmpty
 		for _, e := range peg.Exprs {
 			result := isEmptyExpr(e, emptiness)
 			if result == ruleIsNonEmpty {
 				return ruleIsNonEmpty
 			}
 			if result == ruleIsUnknown {
-				return ruleIsUnknown
+				state = ruleIsUnknown
 			}
 		}
-		return ruleIsEmpty
+		return state
 	case definition.Choice:
+		state := ruleIsNonEmpty
 		for _, e := range peg.Exprs {
 			result := isEmptyExpr(e, emptiness)
 			if result == ruleIsEmpty {
 				return ruleIsEmpty
 			}
 			if result == ruleIsUnknown {
-				return ruleIsUnknown
+				state = ruleIsUnknown
 			}
 		}
-		return ruleIsNonEmpty
+		return state
 	case definition.Kleene:
 		return ruleIsEmpty
 	case definition.Negation:
 		return ruleIsEmpty
+	case definition.Recovery:
+		return ruleIsEmpty
 	case definition.Symbol:
 		return emptiness[peg.Name]
 	case definition.Terminals:
@@ -25,6 +25,29 @@ func TestEmptiness(t *testing.T) {
 			definition.NewRule("A", definition.NewChoice(definition.NewEmpty(), definition.NewTextToken("a"))),
 		}))
 	})
+	t.Run(`A:""i/"a"i`, func(t *testing.T) {
+		require.Equal(t, map[string]bool{"A": true, "B": false}, GetRulesEmptiness(definition.Rules{
+			definition.NewRule("A", definition.NewFoldedToken("")),
+			definition.NewRule("B", definition.NewFoldedToken("a")),
+		}))
+	})
+	t.Run(`A:"a"{0,2}|B:"a"{1,2}|C:A{3}`, func(t *testing.T) {
+		rules, _ := analysis.NormalizeRules(analysis.DesugarRules(definition.Rules{
+			definition.NewRule("A", definition.NewRepetitionRange(definition.NewTextToken("a"), 0, 2)),
+			definition.NewRule("B", definition.NewRepetitionRange(definition.NewTextToken("a"), 1, 2)),
+			definition.NewRule("C", definition.NewRepetitionRange(definition.NewSymbol("A"), 3, 3)),
+		}))
+		emptiness := GetRulesEmptiness(rules)
+		require.True(t, emptiness["A#0"])
+		require.False(t, emptiness["B#0"])
+		require.True(t, emptiness["C#0"])
+	})
+	t.Run(`A:[a-z]*|B:[^a-z]`, func(t *testing.T) {
+		require.Equal(t, map[string]bool{"A": true, "B": false}, GetRulesEmptiness(definition.Rules{
+			definition.NewRule("A", definition.NewRepetition(definition.NewCharClass("[a-z]"))),
+			definition.NewRule("B", definition.NewCharClass("[^a-z]")),
+		}))
+	})
 	t.Run(`A:B C|B:"a"+|C:C* "x"`, func(t *testing.T) {
 		rules, _ := analysis.NormalizeRules(analysis.DesugarRules(definition.Rules{
 			definition.NewRule("A", definition.NewJunction(definition.NewSymbol("B"), definition.NewSymbol("C"))),
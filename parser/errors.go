@@ -0,0 +1,271 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+type ParseError struct {
+	Offset   int
+	Line     int
+	Column   int
+	Expected []string
+	Found    string
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf(
+		"%v at %v:%v (offset %v): expected %v, found %v",
+		TextNotMatchErr, e.Line, e.Column, e.Offset, strings.Join(e.Expected, " or "), e.Found,
+	)
+}
+
+func (e *ParseError) Unwrap() error { return TextNotMatchErr }
+
+type LimitKind int
+
+const (
+	InputLengthLimit LimitKind = 0
+	CellsLimit       LimitKind = 1
+	NodesLimit       LimitKind = 2
+	DepthLimit       LimitKind = 3
+)
+
+func (k LimitKind) String() string {
+	switch k {
+	case InputLengthLimit:
+		return "input length"
+	case CellsLimit:
+		return "cells"
+	case NodesLimit:
+		return "nodes"
+	case DepthLimit:
+		return "depth"
+	default:
+		panic(fmt.Errorf("unexpected limit kind: %v", int(k)))
+	}
+}
+
+// LimitError is returned when parsing exceeds one of the resource limits set by the options
+type LimitError struct {
+	Kind  LimitKind
+	Limit int
+}
+
+func (e *LimitError) Error() string { return fmt.Sprintf("%v limit %v exceeded", e.Kind, e.Limit) }
+
+type failure struct {
+	position int
+	expected map[string]struct{}
+}
+
+var noFailure = failure{position: -1}
+
+func newFailure(position int, expected string) failure {
+	return failure{position: position, expected: map[string]struct{}{expected: {}}}
+}
+
+func (f failure) merge(other failure) failure {
+	if other.position > f.position {
+		return other
+	}
+	if other.position < f.position {
+		return f
+	}
+	merged := failure{position: f.position, expected: make(map[string]struct{}, len(f.expected)+len(other.expected))}
+	for expected := range f.expected {
+		merged.expected[expected] = struct{}{}
+	}
+	for expected := range other.expected {
+		merged.expected[expected] = struct{}{}
+	}
+	return merged
+}
+
+// failureCollector walks only the cells reachable from the root (in the same way as evaluation did)
+// and finds the farthest position where some terminal or named rule were expected but not matched
+type failureCollector[T any] struct {
+	*evaluator[T]
+	failures map[cell]failure
+	visiting map[cell]bool
+}
+
+func (c *failureCollector[T]) visit(i int, expr definition.Expr) (step, failure) {
+	if symbol, ok := expr.(definition.Symbol); ok {
+		s := c.grammar.position[symbol.Name]
+		return c.steps.stepAt(i, s), c.visitRule(i, s)
+	}
+	result := c.advance(i, expr)
+	if !result.ok {
+		return result, newFailure(i, expr.String())
+	}
+	return result, noFailure
+}
+
+func (c *failureCollector[T]) visitRule(i, s int) failure {
+	key := cell{position: i, rule: s}
+	if f, ok := c.failures[key]; ok {
+		return f
+	}
+	if c.visiting[key] {
+		return noFailure
+	}
+	c.visiting[key] = true
+	defer delete(c.visiting, key)
+
+	f := noFailure
+	switch peg := c.grammar.exprs[s].(type) {
+	case definition.Terminals:
+		_, f = c.visit(i, peg)
+	case definition.Symbol:
+		_, f = c.visit(i, peg)
+	case definition.Kleene:
+		for p := i; ; {
+			next, nextFailure := c.visit(p, peg.Expr)
+			f = f.merge(nextFailure)
+			if !next.ok || next.advance == 0 {
+				break
+			}
+			p += next.advance
+		}
+	case definition.Junction:
+		for p, k := i, 0; k < len(peg.Exprs); k++ {
+			next, nextFailure := c.visit(p, peg.Exprs[k])
+			f = f.merge(nextFailure)
+			if !next.ok {
+				break
+			}
+			p += next.advance
+		}
+	case definition.Choice:
+		for _, alternative := range peg.Exprs {
+			next, nextFailure := c.visit(i, alternative)
+			f = f.merge(nextFailure)
+			if next.ok {
+				break
+			}
+		}
+	case definition.Negation:
+		if next, _ := c.visit(i, peg.Expr); next.ok {
+			f = newFailure(i, definition.NewNegation(c.grammar.denormalize(peg.Expr)).String())
+		}
+	case definition.Recovery:
+		// recovered failures are reported separately as diagnostics
+		if next, nextFailure := c.visit(i, peg.Expr); next.ok || !c.options.recovering {
+			f = nextFailure
+		}
+	}
+	if name, ok := c.grammar.transformation.Backward[c.grammar.order[s]]; ok && f.position == i {
+		if symbol, hidden := definition.AnalyzeSymbolName(name); !hidden {
+			f = newFailure(i, symbol)
+		}
+	}
+	c.failures[key] = f
+	return f
+}
+
+// denormalize restores expression in terms of the original rules in order to make it readable
+func (g *grammar) denormalize(expr definition.Expr) definition.Expr {
+	symbol, ok := expr.(definition.Symbol)
+	if !ok {
+		return expr
+	}
+	if name, ok := g.transformation.Backward[symbol.Name]; ok {
+		name, _ = definition.AnalyzeSymbolName(name)
+		return definition.NewSymbol(name, symbol.Attributes)
+	}
+	switch peg := g.ruleMap[symbol.Name].Expr.(type) {
+	case definition.Junction:
+		exprs := make([]definition.Expr, 0, len(peg.Exprs))
+		for _, e := range peg.Exprs {
+			exprs = append(exprs, g.denormalize(e))
+		}
+		return definition.NewJunction(exprs...)
+	case definition.Choice:
+		exprs := make([]definition.Expr, 0, len(peg.Exprs))
+		for _, e := range peg.Exprs {
+			exprs = append(exprs, g.denormalize(e))
+		}
+		return definition.NewChoice(exprs...)
+	case definition.Kleene:
+		return definition.NewRepetition(g.denormalize(peg.Expr))
+	case definition.Negation:
+		return definition.NewNegation(g.denormalize(peg.Expr))
+	case definition.Recovery:
+		return definition.NewRecovery(g.denormalize(peg.Expr), g.denormalize(peg.Sync))
+	default:
+		return g.denormalize(peg)
+	}
+}
+
+func newFailureCollector[T any](e *evaluator[T]) *failureCollector[T] {
+	return &failureCollector[T]{
+		evaluator: e,
+		failures:  make(map[cell]failure),
+		visiting:  make(map[cell]bool),
+	}
+}
+
+func newParseError[T any](e *evaluator[T], root int, rootStep step) *ParseError {
+	f := newFailureCollector(e).visitRule(0, root)
+	if rootStep.ok {
+		f = f.merge(newFailure(rootStep.advance, definition.EndOfFileBuiltinSymbol))
+	}
+	return buildParseError(e, f, 0, definition.NewSymbol(e.grammar.order[root]))
+}
+
+// newRecoveryError explains why the expression guarded by the recovery failed at position i
+func newRecoveryError[T any](e *evaluator[T], i int, expr definition.Expr) *ParseError {
+	_, f := newFailureCollector(e).visit(i, expr)
+	return buildParseError(e, f, i, expr)
+}
+
+func buildParseError[T any](e *evaluator[T], f failure, fallbackPosition int, fallback definition.Expr) *ParseError {
+	if f.position < 0 {
+		f = newFailure(fallbackPosition, e.grammar.denormalize(fallback).String())
+	}
+	expected := make([]string, 0, len(f.expected))
+	for e := range f.expected {
+		expected = append(expected, e)
+	}
+	slices.Sort(expected)
+	line, column, found := locate(e.data, f.position)
+	return &ParseError{Offset: f.position, Line: line, Column: column, Expected: expected, Found: found}
+}
+
+func locate[T any](data []T, offset int) (int, int, string) {
+	var text []byte
+	textOffset := 0
+	found := "end of input"
+	switch input := any(data).(type) {
+	case []byte:
+		text, textOffset = input, offset
+		if offset < len(input) {
+			r, _ := utf8.DecodeRune(input[offset:])
+			found = strconv.QuoteRune(r)
+		}
+	case []definition.Atom:
+		if len(input) == 0 {
+			return 1, 1, found
+		}
+		text = input[0].Text
+		if offset < len(input) {
+			found = fmt.Sprintf("%v %v", input[offset].Symbol, strconv.Quote(input[offset].SelectString()))
+			if segments := input[offset].TextSelector.List(); len(segments) > 0 {
+				textOffset = segments[0].Start
+			}
+		} else if segments := input[len(input)-1].TextSelector.List(); len(segments) > 0 {
+			textOffset = segments[len(segments)-1].End
+		}
+	}
+	textOffset = min(textOffset, len(text))
+	line := 1 + bytes.Count(text[:textOffset], []byte("\n"))
+	column := 1 + textOffset - (bytes.LastIndexByte(text[:textOffset], '\n') + 1)
+	return line, column, found
+}
@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+func TestParseError(t *testing.T) {
+	for _, strategy := range []Strategy{TableStrategy, LazyStrategy} {
+		t.Run("unmatched", func(t *testing.T) {
+			_, err := ParseText(arithmeticRules, "Expr", []byte("+1"), WithStrategy(strategy))
+			require.True(t, errors.Is(err, TextNotMatchErr))
+			var parseErr *ParseError
+			require.True(t, errors.As(err, &parseErr))
+			require.Equal(t, &ParseError{Offset: 0, Line: 1, Column: 1, Expected: []string{"Expr"}, Found: `'+'`}, parseErr)
+		})
+		t.Run("farthest failure", func(t *testing.T) {
+			_, err := ParseText(arithmeticRules, "Expr", []byte("1+(2*\n4-"), WithStrategy(strategy), WithMatchMode(FullMatch))
+			var parseErr *ParseError
+			require.True(t, errors.As(err, &parseErr))
+			require.Equal(t, &ParseError{Offset: 5, Line: 1, Column: 6, Expected: []string{"Value"}, Found: `'\n'`}, parseErr)
+		})
+		t.Run("partial match", func(t *testing.T) {
+			node, err := ParseText(arithmeticRules, "Expr", []byte("1+2)"), WithStrategy(strategy))
+			require.Nil(t, err)
+			require.Equal(t, 3, node.Segment.Length())
+
+			_, err = ParseText(arithmeticRules, "Expr", []byte("1+2)"), WithStrategy(strategy), WithMatchMode(FullMatch))
+			var parseErr *ParseError
+			require.True(t, errors.As(err, &parseErr))
+			require.Equal(t, 3, parseErr.Offset)
+			require.Equal(t, []string{`"*"`, `"+"`, `"-"`, `"/"`, "@eof", "Digit"}, parseErr.Expected)
+			require.Equal(t, `TextNotMatch at 1:4 (offset 3): expected "*" or "+" or "-" or "/" or @eof or Digit, found ')'`, err.Error())
+		})
+	}
+	t.Run("atoms", func(t *testing.T) {
+		text := []byte("a\nb c")
+		atoms := []definition.Atom{
+			{Symbol: "Id", Text: text, TextSelector: definition.BuildSegments(definition.Segment{Start: 0, End: 1})},
+			{Symbol: "Id", Text: text, TextSelector: definition.BuildSegments(definition.Segment{Start: 2, End: 3})},
+			{Symbol: "Num", Text: text, TextSelector: definition.BuildSegments(definition.Segment{Start: 4, End: 5})},
+		}
+		rules := definition.Rules{
+			definition.NewRule("Ids", definition.NewRepetition(definition.NewAtomPattern(map[string]definition.TextTerminals{"Id": nil}))),
+		}
+		_, err := ParseAtoms(rules, "Ids", atoms, WithMatchMode(FullMatch))
+		var parseErr *ParseError
+		require.True(t, errors.As(err, &parseErr))
+		require.Equal(t, &ParseError{Offset: 2, Line: 2, Column: 3, Expected: []string{"@eof", "{Id}"}, Found: `Num "c"`}, parseErr)
+	})
+}
@@ -0,0 +1,264 @@
+package parser
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+type (
+	// look is the amount of elements examined by the evaluation starting from its position; it is tracked only for incremental sessions
+	step struct {
+		ok      bool
+		advance int
+		look    int
+	}
+	stepSource interface {
+		stepAt(i, s int) step
+		versionBefore(i, s, seq int) version
+	}
+	evaluator[T any] struct {
+		grammar  *grammar
+		data     []T
+		options  parseOptions
+		steps    stepSource
+		profiler *profiler
+		// cells is the amount of evaluated cells; err is set when evaluation was aborted by the limit or by the context
+		// and since then every cell fails
+		cells int
+		err   error
+	}
+	stepTable struct {
+		cells   [][]step
+		history recursionHistory
+	}
+)
+
+func newEvaluator[T any](g *grammar, data []T, options parseOptions) (*evaluator[T], error) {
+	if err := options.checkInputLength(len(data)); err != nil {
+		return nil, err
+	}
+	e := &evaluator[T]{grammar: g, data: data, options: options}
+	if options.profile != nil {
+		e.profiler = newProfiler(g, options.profile)
+	}
+	if e.interrupted() {
+		return nil, e.err
+	}
+	switch options.strategy {
+	case TableStrategy:
+		if options.maxCells > 0 && (len(data)+1) > options.maxCells/len(g.order) {
+			return nil, &LimitError{Kind: CellsLimit, Limit: options.maxCells}
+		}
+		buildStepTable(e)
+	case LazyStrategy:
+		newLazyTable(e)
+	case VMStrategy:
+		newMachine(e)
+	default:
+		return nil, fmt.Errorf("unknown parsing strategy: %v", options.strategy)
+	}
+	if e.err != nil {
+		return nil, e.err
+	}
+	return e, nil
+}
+
+// contextCheckPeriod is the amount of cells evaluated between checks of the context
+const contextCheckPeriod = 1024
+
+// interrupted checks whether the context of the parse is done
+func (e *evaluator[T]) interrupted() bool {
+	if e.err == nil && e.options.context != nil {
+		if err := e.options.context.Err(); err != nil {
+			e.err = fmt.Errorf("parsing was interrupted: %w", err)
+		}
+	}
+	return e.err != nil
+}
+
+// account registers evaluation of the cell and reports whether the evaluation can proceed
+func (e *evaluator[T]) account() bool {
+	if e.err != nil {
+		return false
+	}
+	e.cells++
+	if e.options.maxCells > 0 && e.cells > e.options.maxCells {
+		e.err = &LimitError{Kind: CellsLimit, Limit: e.options.maxCells}
+		return false
+	}
+	return e.cells%contextCheckPeriod != 0 || !e.interrupted()
+}
+
+func (e *evaluator[T]) advance(i int, expr definition.Expr) step {
+	switch peg := expr.(type) {
+	case definition.Terminals:
+		if _, ok := peg.(definition.TextPattern); ok && e.profiler != nil {
+			start := time.Now()
+			result := e.accept(i, peg)
+			e.profiler.measure(time.Since(start))
+			return result
+		}
+		return e.accept(i, peg)
+	case definition.Symbol:
+		return e.steps.stepAt(i, e.grammar.position[peg.Name])
+	default:
+		panic(fmt.Errorf("invalid usage of advance: unexpected peg expression type: %#v", expr))
+	}
+}
+
+func (e *evaluator[T]) accept(i int, terminals definition.Terminals) step {
+	if _, dot := terminals.(definition.Dot); dot && e.options.utf8 {
+		if text, ok := any(e.data).([]byte); ok {
+			advance, look, ok := definition.ExamineRune(text, i)
+			return step{ok: ok, advance: advance, look: look}
+		}
+	}
+	if e.options.incremental {
+		advance, look, ok := definition.Examine[T](terminals, e.data, i)
+		return step{ok: ok, advance: advance, look: look}
+	}
+	advance, ok := definition.Accept[T](terminals, e.data, i)
+	return step{ok: ok, advance: advance}
+}
+
+func (e *evaluator[T]) evaluateRule(i, s int) step {
+	switch peg := e.grammar.exprs[s].(type) {
+	case definition.Terminals:
+		return e.advance(i, peg)
+	case definition.Symbol:
+		return e.advance(i, peg)
+	case definition.Kleene:
+		next := e.advance(i, peg.Expr)
+		if next.ok && next.advance > 0 {
+			tail := e.steps.stepAt(i+next.advance, s)
+			return step{ok: true, advance: tail.advance + next.advance, look: max(next.look, tail.look+next.advance)}
+		}
+		return step{ok: true, advance: 0, look: next.look}
+	case definition.Junction:
+		current, look := i, 0
+		for _, j := range peg.Exprs {
+			next := e.advance(current, j)
+			look = max(look, current-i+next.look)
+			if !next.ok {
+				return step{look: look}
+			}
+			current += next.advance
+		}
+		return step{ok: true, advance: current - i, look: look}
+	case definition.Choice:
+		look := 0
+		for _, c := range peg.Exprs {
+			next := e.advance(i, c)
+			look = max(look, next.look)
+			if next.ok {
+				return step{ok: true, advance: next.advance, look: look}
+			}
+		}
+		return step{look: look}
+	case definition.Negation:
+		next := e.advance(i, peg.Expr)
+		return step{ok: !next.ok, advance: 0, look: next.look}
+	case definition.Recovery:
+		next := e.advance(i, peg.Expr)
+		if next.ok || !e.options.recovering {
+			return next
+		}
+		current, look := i, next.look
+		for current < len(e.data) {
+			sync := e.advance(current, peg.Sync)
+			look = max(look, current-i+sync.look)
+			if sync.ok {
+				break
+			}
+			current++
+		}
+		// recovery which reached the end of input depends on the length of the input
+		if current == len(e.data) {
+			look = max(look, current-i+1)
+		}
+		return step{ok: true, advance: current - i, look: look}
+	default:
+		panic(fmt.Errorf("unexpected peg expression type: %#v", e.grammar.exprs[s]))
+	}
+}
+
+func (e *evaluator[T]) traceEnter(i, s int) {
+	if e.options.tracer != nil {
+		e.options.tracer.Enter(e.grammar.names[s], i)
+	}
+}
+
+func (e *evaluator[T]) traceExit(i, s int, result step) {
+	if e.options.tracer == nil {
+		return
+	}
+	if result.ok {
+		e.options.tracer.Match(e.grammar.names[s], i, result.advance)
+	} else {
+		e.options.tracer.Fail(e.grammar.names[s], i)
+	}
+}
+
+func (e *evaluator[T]) enter(i, s int) {
+	e.traceEnter(i, s)
+	e.profiler.push(s)
+}
+
+func (e *evaluator[T]) exit(i, s int, result step) {
+	e.profiler.pop()
+	e.profiler.record(s, result)
+	e.traceExit(i, s, result)
+}
+
+// grow evaluates the left-recursive group at the position and reports results of its members
+// in the reversed order in order to keep trace events properly nested
+func (e *evaluator[T]) grow(i int, component []int, history *recursionHistory, steps stepStore, run func(i, s int) step) {
+	for _, s := range component {
+		e.traceEnter(i, s)
+	}
+	history.grow(i, component, steps, func(s int) step {
+		e.profiler.push(s)
+		defer e.profiler.pop()
+		return run(i, s)
+	})
+	for k := len(component) - 1; k >= 0; k-- {
+		result := steps.stepAt(i, component[k])
+		e.profiler.record(component[k], result)
+		e.traceExit(i, component[k], result)
+	}
+}
+
+func (t *stepTable) stepAt(i, s int) step       { return t.cells[i][s] }
+func (t *stepTable) store(i, s int, value step) { t.cells[i][s] = value }
+func (t *stepTable) versionBefore(i, s, seq int) version {
+	return t.history.versionBefore(cell{position: i, rule: s}, seq)
+}
+
+func buildStepTable[T any](e *evaluator[T]) *stepTable {
+	g := e.grammar
+	// todo (sivukhin, 2023-09-02): should we use single table of size (len(text)+1) * len(order) in order to reduce amount of allocations and GC pressure?
+	table := &stepTable{cells: make([][]step, len(e.data)+1), history: newRecursionHistory()}
+	for i := 0; i <= len(e.data); i++ {
+		table.cells[i] = make([]step, len(g.order))
+	}
+	e.steps = table
+
+	for i := len(e.data); i >= 0; i-- {
+		if e.interrupted() {
+			return table
+		}
+		for c := len(g.components) - 1; c >= 0; c-- {
+			component := g.components[c]
+			if g.recursive[component[0]] {
+				e.grow(i, component, &table.history, table, e.evaluateRule)
+				continue
+			}
+			e.enter(i, component[0])
+			table.cells[i][component[0]] = e.evaluateRule(i, component[0])
+			e.exit(i, component[0], table.cells[i][component[0]])
+		}
+	}
+	return table
+}
@@ -0,0 +1,112 @@
+package parser
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/sivukhin/gopeg/analysis"
+	"github.com/sivukhin/gopeg/definition"
+)
+
+// Grammar holds rules prepared for parsing: consistency checks, desugaring, normalization and ordering are done only once.
+// Grammar is immutable and safe for concurrent use
+type Grammar struct {
+	grammar *grammar
+	options parseOptions
+}
+
+// Compile prepares rules for parsing; options given here are defaults for every parse and can be overridden by parse options.
+// Root defaults to the first rule
+func Compile(rules definition.Rules, opts ...Option) (*Grammar, error) {
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("grammar must have at least one rule")
+	}
+	g, err := compileGrammar(rules)
+	if err != nil {
+		return nil, err
+	}
+	options := buildOptions(append([]Option{WithRoot(rules[0].Name)}, opts...))
+	if err := g.checkOptions(options); err != nil {
+		return nil, err
+	}
+	return &Grammar{grammar: g, options: options}, nil
+}
+
+func (g *grammar) checkOptions(options parseOptions) error {
+	if _, err := g.rootRule(options.root); err != nil {
+		return err
+	}
+	if options.strategy != TableStrategy && options.strategy != LazyStrategy && options.strategy != VMStrategy {
+		return fmt.Errorf("unknown parsing strategy: %v", options.strategy)
+	}
+	for _, limit := range []struct {
+		kind  LimitKind
+		value int
+	}{{InputLengthLimit, options.maxInputLength}, {CellsLimit, options.maxCells}, {NodesLimit, options.maxNodes}, {DepthLimit, options.maxDepth}} {
+		if limit.value < 0 {
+			return fmt.Errorf("%v limit must be non-negative: %v", limit.kind, limit.value)
+		}
+	}
+	return nil
+}
+
+func (g *Grammar) parseOptions(opts []Option) (parseOptions, error) {
+	options := applyOptions(g.options, opts)
+	if err := g.grammar.checkOptions(options); err != nil {
+		return parseOptions{}, err
+	}
+	return options, nil
+}
+
+// withRoot makes explicit root argument of the rules-based functions take priority over options
+func withRoot(root string, opts []Option) []Option {
+	return append(slices.Clip(opts), WithRoot(root))
+}
+
+func (g *Grammar) ParseText(text []byte, opts ...Option) (*ParsingNode, error) {
+	return parseCompiled(g, analysis.ByteTerminalType, text, opts)
+}
+
+func (g *Grammar) ParseAtoms(atoms []definition.Atom, opts ...Option) (*ParsingNode, error) {
+	return parseCompiled(g, analysis.AtomTerminalType, atoms, opts)
+}
+
+// ParseTextContext parses the text and aborts evaluation with the context error as soon as the context is done
+func (g *Grammar) ParseTextContext(ctx context.Context, text []byte, opts ...Option) (*ParsingNode, error) {
+	return parseCompiled(g, analysis.ByteTerminalType, text, append(slices.Clip(opts), withContext(ctx)))
+}
+
+func (g *Grammar) ParseAtomsContext(ctx context.Context, atoms []definition.Atom, opts ...Option) (*ParsingNode, error) {
+	return parseCompiled(g, analysis.AtomTerminalType, atoms, append(slices.Clip(opts), withContext(ctx)))
+}
+
+func (g *Grammar) ParseTextWithRecovery(text []byte, opts ...Option) (*ParsingNode, []Diagnostic, error) {
+	return parseCompiledWithRecovery(g, analysis.ByteTerminalType, text, opts)
+}
+
+func (g *Grammar) ParseAtomsWithRecovery(atoms []definition.Atom, opts ...Option) (*ParsingNode, []Diagnostic, error) {
+	return parseCompiledWithRecovery(g, analysis.AtomTerminalType, atoms, opts)
+}
+
+func parseCompiled[T any](g *Grammar, terminalType analysis.TerminalType, data []T, opts []Option) (*ParsingNode, error) {
+	if err := g.grammar.checkTerminalType(terminalType); err != nil {
+		return nil, err
+	}
+	options, err := g.parseOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	return parse(g.grammar, data, options)
+}
+
+func parseCompiledWithRecovery[T any](g *Grammar, terminalType analysis.TerminalType, data []T, opts []Option) (*ParsingNode, []Diagnostic, error) {
+	if err := g.grammar.checkTerminalType(terminalType); err != nil {
+		return nil, nil, err
+	}
+	options, err := g.parseOptions(opts)
+	if err != nil {
+		return nil, nil, err
+	}
+	return parseWithRecovery(g.grammar, data, options)
+}
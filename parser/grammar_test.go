@@ -0,0 +1,87 @@
+package parser
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+func TestCompile(t *testing.T) {
+	t.Run("errors", func(t *testing.T) {
+		_, err := Compile(nil)
+		require.NotNil(t, err)
+		_, err = Compile(arithmeticRules, WithRoot("Unknown"))
+		require.NotNil(t, err)
+		_, err = Compile(definition.Rules{definition.NewRule("A", nil)})
+		require.NotNil(t, err)
+		_, err = Compile(definition.Rules{definition.NewRule("A", definition.NewSymbol("B"))})
+		require.NotNil(t, err)
+		_, err = Compile(arithmeticRules, WithStrategy(Strategy(42)))
+		require.NotNil(t, err)
+
+		g, err := Compile(arithmeticRules)
+		require.Nil(t, err)
+		_, err = g.ParseAtoms(nil)
+		require.NotNil(t, err)
+		_, err = g.ParseText([]byte("1"), WithRoot("Unknown"))
+		require.NotNil(t, err)
+	})
+	t.Run("options", func(t *testing.T) {
+		g, err := Compile(arithmeticRules, WithMatchMode(FullMatch))
+		require.Nil(t, err)
+		_, err = g.ParseText([]byte("1+2)"))
+		require.True(t, errors.Is(err, TextNotMatchErr))
+		node, err := g.ParseText([]byte("1+2)"), WithMatchMode(PrefixMatch))
+		require.Nil(t, err)
+		require.Equal(t, "Expr", node.Atom.Symbol)
+		require.Equal(t, 3, node.Segment.Length())
+		node, err = g.ParseText([]byte("12"), WithRoot("Digit"), WithMatchMode(PrefixMatch))
+		require.Nil(t, err)
+		require.Equal(t, "Digit", node.Atom.Symbol)
+	})
+	t.Run("trace", func(t *testing.T) {
+		g, err := Compile(arithmeticRules, WithStrategy(LazyStrategy))
+		require.Nil(t, err)
+		var trace strings.Builder
+		_, err = g.ParseText([]byte("1+2"), WithTrace(&trace))
+		require.Nil(t, err)
+		require.Contains(t, trace.String(), "Digit at 0: matched 1\n")
+		require.Contains(t, trace.String(), "Digit at 1: failed\n")
+		require.Contains(t, trace.String(), "Expr at 0: matched 3\n")
+	})
+	t.Run("input length limit", func(t *testing.T) {
+		g, err := Compile(arithmeticRules, WithMaxInputLength(3))
+		require.Nil(t, err)
+		_, err = g.ParseText([]byte("1+2"))
+		require.Nil(t, err)
+		_, err = g.ParseText([]byte("1+23"))
+		require.NotNil(t, err)
+	})
+	t.Run("concurrent use", func(t *testing.T) {
+		g, err := Compile(arithmeticRules, WithRoot("Expr"))
+		require.Nil(t, err)
+		expected, err := ParseText(arithmeticRules, "Expr", []byte("1+(2*4-5)-10*44"))
+		require.Nil(t, err)
+		var wg sync.WaitGroup
+		results := make([]string, 16)
+		for i := range results {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				node, err := g.ParseText([]byte("1+(2*4-5)-10*44"), WithStrategy(Strategy(i%2)))
+				if err == nil {
+					results[i] = StringParsingNode(node)
+				}
+			}(i)
+		}
+		wg.Wait()
+		for _, result := range results {
+			require.Equal(t, StringParsingNode(expected), result)
+		}
+	})
+}
@@ -43,6 +43,8 @@ func buildForwardRuleDeps(rules definition.Rules) map[string][]string {
 			ruleDeps[rule.Name] = selectForwardDeps([]definition.Expr{peg.Expr})
 		case definition.Negation:
 			ruleDeps[rule.Name] = selectForwardDeps([]definition.Expr{peg.Expr})
+		case definition.Recovery:
+			ruleDeps[rule.Name] = selectForwardDeps(peg.Children())
 		case definition.Symbol:
 			ruleDeps[rule.Name] = selectForwardDeps([]definition.Expr{rule.Expr})
 		case definition.Terminals:
@@ -69,6 +71,8 @@ func buildBackwardRuleDeps(rules definition.Rules) map[string][]string {
 			addBackwardDeps(ruleDeps, rule.Name, []definition.Expr{peg.Expr})
 		case definition.Negation:
 			addBackwardDeps(ruleDeps, rule.Name, []definition.Expr{peg.Expr})
+		case definition.Recovery:
+			addBackwardDeps(ruleDeps, rule.Name, peg.Children())
 		case definition.Symbol:
 			addBackwardDeps(ruleDeps, rule.Name, []definition.Expr{rule.Expr})
 		case definition.Terminals:
@@ -0,0 +1,68 @@
+package parser
+
+import (
+	"github.com/sivukhin/gopeg/definition"
+)
+
+type cell struct{ position, rule int }
+
+// lazyTable evaluates only (position, rule) pairs reachable from the requested cells and memoizes them
+type lazyTable[T any] struct {
+	*evaluator[T]
+	memo    map[cell]step
+	history recursionHistory
+}
+
+func newLazyTable[T any](e *evaluator[T]) *lazyTable[T] {
+	table := &lazyTable[T]{
+		evaluator: e,
+		memo:      make(map[cell]step),
+		history:   newRecursionHistory(),
+	}
+	e.steps = table
+	return table
+}
+
+func (t *lazyTable[T]) store(i, s int, value step) { t.memo[cell{position: i, rule: s}] = value }
+
+func (t *lazyTable[T]) versionBefore(i, s, seq int) version {
+	return t.history.versionBefore(cell{position: i, rule: s}, seq)
+}
+
+func (t *lazyTable[T]) stepAt(i, s int) step {
+	key := cell{position: i, rule: s}
+	if result, ok := t.memo[key]; ok {
+		return result
+	}
+	if !t.account() {
+		return step{}
+	}
+	if t.grammar.recursive[s] {
+		component := t.grammar.components[t.grammar.componentOf[s]]
+		t.grow(i, component, &t.history, t, t.evaluate)
+		return t.memo[key]
+	}
+	t.enter(i, s)
+	result := t.evaluate(i, s)
+	t.memo[key] = result
+	t.exit(i, s, result)
+	return result
+}
+
+func (t *lazyTable[T]) evaluate(i, s int) step {
+	peg, ok := t.grammar.exprs[s].(definition.Kleene)
+	if !ok {
+		return t.evaluateRule(i, s)
+	}
+	// iterate instead of recursing into the same rule at the next position in order to keep stack shallow
+	current, look := i, 0
+	for {
+		next := t.advance(current, peg.Expr)
+		look = max(look, current-i+next.look)
+		if !next.ok || next.advance == 0 {
+			break
+		}
+		current += next.advance
+	}
+	return step{ok: true, advance: current - i, look: look}
+}
@@ -0,0 +1,157 @@
+package parser
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func requireLimitError(t *testing.T, err error, kind LimitKind) {
+	var limitErr *LimitError
+	require.True(t, errors.As(err, &limitErr), "unexpected error: %v", err)
+	require.Equal(t, kind, limitErr.Kind)
+}
+
+// expiringContext becomes canceled after the given amount of checks
+type expiringContext struct {
+	context.Context
+	checks int
+}
+
+func (c *expiringContext) Err() error {
+	if c.checks == 0 {
+		return context.Canceled
+	}
+	c.checks--
+	return nil
+}
+
+func TestContext(t *testing.T) {
+	text := []byte(strings.Repeat("1+", 10000) + "1")
+	for _, strategy := range []Strategy{TableStrategy, LazyStrategy, VMStrategy} {
+		g, err := Compile(arithmeticRules, WithStrategy(strategy))
+		require.Nil(t, err)
+		node, err := g.ParseTextContext(context.Background(), text)
+		require.Nil(t, err)
+		require.Equal(t, len(text), node.Segment.Length())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err = g.ParseTextContext(ctx, text)
+		require.True(t, errors.Is(err, context.Canceled))
+
+		ctx, cancel = context.WithTimeout(context.Background(), time.Nanosecond)
+		<-ctx.Done()
+		_, err = ParseTextContext(ctx, arithmeticRules, "Expr", text, WithStrategy(strategy))
+		require.True(t, errors.Is(err, context.DeadlineExceeded))
+		cancel()
+
+		_, err = g.ParseTextContext(&expiringContext{Context: context.Background(), checks: 2}, text)
+		require.True(t, errors.Is(err, context.Canceled))
+	}
+}
+
+func TestLimits(t *testing.T) {
+	t.Run("input length", func(t *testing.T) {
+		_, err := ParseText(arithmeticRules, "Expr", []byte("1+23"), WithMaxInputLength(3))
+		requireLimitError(t, err, InputLengthLimit)
+	})
+	t.Run("cells", func(t *testing.T) {
+		for _, strategy := range []Strategy{TableStrategy, LazyStrategy, VMStrategy} {
+			_, err := ParseText(arithmeticRules, "Expr", []byte("1+2*3"), WithStrategy(strategy), WithMaxCells(1000))
+			require.Nil(t, err)
+			_, err = ParseText(arithmeticRules, "Expr", []byte(strings.Repeat("1+", 1000)+"1"), WithStrategy(strategy), WithMaxCells(1000))
+			requireLimitError(t, err, CellsLimit)
+			_, err = EvaluateText(arithmeticRules, "Expr", []byte(strings.Repeat("1+", 1000)+"1"), arithmeticActions, WithStrategy(strategy), WithMaxCells(1000))
+			requireLimitError(t, err, CellsLimit)
+		}
+	})
+	t.Run("nodes and depth", func(t *testing.T) {
+		g, err := Compile(arithmeticRules, WithStrategy(LazyStrategy))
+		require.Nil(t, err)
+		node, err := g.ParseText([]byte("(((1)))"))
+		require.Nil(t, err)
+		require.Equal(t, 17, strings.Count(StringParsingNode(node), "\n"))
+
+		_, err = g.ParseText([]byte("(((1)))"), WithMaxNodes(17), WithMaxDepth(17))
+		require.Nil(t, err)
+		_, err = g.ParseText([]byte("(((1)))"), WithMaxNodes(16))
+		requireLimitError(t, err, NodesLimit)
+		_, err = g.ParseText([]byte("(((1)))"), WithMaxDepth(16))
+		requireLimitError(t, err, DepthLimit)
+		_, _, err = g.ParseTextWithRecovery([]byte("(((1)))"), WithMaxDepth(16))
+		requireLimitError(t, err, DepthLimit)
+	})
+	t.Run("annotations", func(t *testing.T) {
+		text := []byte("1,23#x,(4,(5#y,6),7),((8))")
+		for _, concrete := range []bool{false, true} {
+			options := []Option{WithMatchMode(FullMatch)}
+			if concrete {
+				options = append(options, WithConcreteSyntaxTree())
+			}
+			g, err := Compile(annotatedRules, options...)
+			require.Nil(t, err)
+			node, err := g.ParseText(text)
+			require.Nil(t, err)
+			nodes, depth := 0, 0
+			var visit func(node *ParsingNode, level int)
+			visit = func(node *ParsingNode, level int) {
+				nodes, depth = nodes+1, max(depth, level)
+				for _, child := range node.Children {
+					visit(child, level+1)
+				}
+			}
+			visit(node, 1)
+			_, err = g.ParseText(text, WithMaxNodes(nodes), WithMaxDepth(depth))
+			require.Nil(t, err)
+			_, err = g.ParseText(text, WithMaxNodes(nodes-1))
+			requireLimitError(t, err, NodesLimit)
+			_, err = g.ParseText(text, WithMaxDepth(depth-1))
+			requireLimitError(t, err, DepthLimit)
+		}
+	})
+	t.Run("tree construction stops early", func(t *testing.T) {
+		g, err := Compile(arithmeticRules, WithStrategy(VMStrategy))
+		require.Nil(t, err)
+		for _, c := range []struct {
+			text  []byte
+			limit Option
+			kind  LimitKind
+		}{
+			{text: []byte(strings.Repeat("1+", 5000) + "1"), limit: WithMaxNodes(10), kind: NodesLimit},
+			{text: []byte(strings.Repeat("(", 2000) + "1" + strings.Repeat(")", 2000)), limit: WithMaxDepth(10), kind: DepthLimit},
+		} {
+			full := testing.AllocsPerRun(1, func() {
+				_, err := g.ParseText(c.text)
+				require.Nil(t, err)
+			})
+			limited := testing.AllocsPerRun(1, func() {
+				_, err := g.ParseText(c.text, c.limit)
+				requireLimitError(t, err, c.kind)
+			})
+			// evaluation allocates much less than the tree, so the limited parse must skip the most of tree allocations
+			require.Less(t, limited, full/5)
+		}
+	})
+	t.Run("session", func(t *testing.T) {
+		g, err := Compile(arithmeticRules)
+		require.Nil(t, err)
+		session, err := g.NewSession([]byte("1+2"), WithMaxCells(100))
+		require.Nil(t, err)
+		_, err = session.Parse()
+		require.Nil(t, err)
+		_, err = session.Edit(3, 0, []byte(strings.Repeat("+1", 100)))
+		requireLimitError(t, err, CellsLimit)
+		node, err := session.Edit(3, 200, nil)
+		require.Nil(t, err)
+		require.Equal(t, 3, node.Segment.Length())
+	})
+	t.Run("negative", func(t *testing.T) {
+		_, err := Compile(arithmeticRules, WithMaxDepth(-1))
+		require.NotNil(t, err)
+	})
+}
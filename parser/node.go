@@ -6,7 +6,22 @@ import (
 	"strings"
 )
 
+type NodeKind int
+
+const (
+	NamedNode NodeKind = 0
+	// ErrorNode covers the input skipped by the recovery expression
+	ErrorNode NodeKind = 1
+	// AnonymousNode covers the input matched by the terminal which is not wrapped in a named rule (only in concrete syntax tree)
+	AnonymousNode NodeKind = 2
+	// HiddenNode covers the input matched by the hidden #rule (only in concrete syntax tree)
+	HiddenNode NodeKind = 3
+)
+
+const ErrorSymbol = "@error"
+
 type ParsingNode struct {
+	Kind     NodeKind
 	Atom     definition.Atom
 	Segment  definition.Segment
 	Children []*ParsingNode
@@ -91,7 +106,9 @@ func NewParsingNode[T any](symbol string, attributes map[string][]byte, data []T
 		for _, atom := range atoms[segment.Start:segment.End] {
 			segments = append(segments, atom.TextSelector)
 		}
-		text = atoms[0].Text
+		if len(atoms) > 0 {
+			text = atoms[0].Text
+		}
 		textSelector = definition.JoinSegments(segments...)
 	}
 
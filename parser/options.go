@@ -0,0 +1,153 @@
+package parser
+
+import (
+	"context"
+	"io"
+)
+
+type Strategy int
+
+const (
+	TableStrategy Strategy = 0
+	LazyStrategy  Strategy = 1
+	// VMStrategy compiles rules into the bytecode program with dedicated instructions for text terminals
+	// and memoizes results of rule calls in the dense table with 4 bytes per (position, rule) pair
+	VMStrategy Strategy = 2
+)
+
+type MatchMode int
+
+const (
+	PrefixMatch MatchMode = 0
+	FullMatch   MatchMode = 1
+)
+
+type (
+	Option       func(options *parseOptions)
+	parseOptions struct {
+		root           string
+		strategy       Strategy
+		matchMode      MatchMode
+		tracer         Tracer
+		profile        *Profile
+		maxInputLength int
+		maxCells       int
+		maxNodes       int
+		maxDepth       int
+		context        context.Context
+		recovering     bool
+		incremental    bool
+		concrete       bool
+		utf8           bool
+	}
+)
+
+// WithRoot selects the rule which must match the input; by default compiled grammar starts from the first rule
+func WithRoot(root string) Option {
+	return func(options *parseOptions) { options.root = root }
+}
+
+func WithStrategy(strategy Strategy) Option {
+	return func(options *parseOptions) { options.strategy = strategy }
+}
+
+func WithMatchMode(matchMode MatchMode) Option {
+	return func(options *parseOptions) { options.matchMode = matchMode }
+}
+
+// WithTrace writes every evaluated (position, rule) pair together with its result to the writer as an indented trace
+func WithTrace(writer io.Writer) Option {
+	return WithTracer(NewPrintingTracer(writer))
+}
+
+// WithTracer reports evaluation of every (position, rule) pair to the tracer; memoized results are not reported again
+func WithTracer(tracer Tracer) Option {
+	return func(options *parseOptions) { options.tracer = tracer }
+}
+
+// WithProfile accumulates statistics of the rules evaluation in the profile
+func WithProfile(profile *Profile) Option {
+	return func(options *parseOptions) { options.profile = profile }
+}
+
+// WithMaxInputLength rejects inputs which are longer than limit (in bytes for text and in atoms for atoms); zero means no limit
+func WithMaxInputLength(limit int) Option {
+	return func(options *parseOptions) { options.maxInputLength = limit }
+}
+
+// WithMaxCells rejects parses which evaluate more than limit (position, rule) pairs; zero means no limit.
+// Table strategy evaluates all pairs so the limit is checked before the table is allocated
+func WithMaxCells(limit int) Option {
+	return func(options *parseOptions) { options.maxCells = limit }
+}
+
+// WithMaxNodes rejects parse trees with more than limit nodes; zero means no limit
+func WithMaxNodes(limit int) Option {
+	return func(options *parseOptions) { options.maxNodes = limit }
+}
+
+// WithMaxDepth rejects parse trees which are deeper than limit (root has depth 1); zero means no limit
+func WithMaxDepth(limit int) Option {
+	return func(options *parseOptions) { options.maxDepth = limit }
+}
+
+func withContext(ctx context.Context) Option {
+	return func(options *parseOptions) { options.context = ctx }
+}
+
+// WithUTF8 makes Dot consume single UTF-8 encoded character of the text instead of single byte;
+// Dot doesn't match invalid encoding which can be matched explicitly with InvalidUTF8 terminal. Segments are still measured in bytes
+func WithUTF8() Option {
+	return func(options *parseOptions) { options.utf8 = true }
+}
+
+// WithConcreteSyntaxTree keeps text matched by unnamed terminals and hidden rules as AnonymousNode and HiddenNode nodes,
+// so concatenation of the tree leaves reproduces the matched input
+func WithConcreteSyntaxTree() Option {
+	return func(options *parseOptions) { options.concrete = true }
+}
+
+func buildOptions(opts []Option) parseOptions {
+	return applyOptions(parseOptions{strategy: TableStrategy, matchMode: PrefixMatch}, opts)
+}
+
+func applyOptions(options parseOptions, opts []Option) parseOptions {
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+func (options parseOptions) checkInputLength(length int) error {
+	if options.maxInputLength > 0 && length > options.maxInputLength {
+		return &LimitError{Kind: InputLengthLimit, Limit: options.maxInputLength}
+	}
+	return nil
+}
+
+// checkTree verifies that the parse tree fits into the node count and depth limits
+func (options parseOptions) checkTree(root *ParsingNode) error {
+	if options.maxNodes == 0 && options.maxDepth == 0 {
+		return nil
+	}
+	type item struct {
+		node  *ParsingNode
+		depth int
+	}
+	stack, nodes := []item{{node: root, depth: 1}}, 0
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		nodes++
+		if options.maxNodes > 0 && nodes > options.maxNodes {
+			return &LimitError{Kind: NodesLimit, Limit: options.maxNodes}
+		}
+		if options.maxDepth > 0 && current.depth > options.maxDepth {
+			return &LimitError{Kind: DepthLimit, Limit: options.maxDepth}
+		}
+		for _, child := range current.node.Children {
+			stack = append(stack, item{node: child, depth: current.depth + 1})
+		}
+	}
+	return nil
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/sivukhin/gopeg/analysis"
 	"github.com/sivukhin/gopeg/definition"
+	"slices"
 )
 
 func add(graph map[string][]string, a, b string) {
@@ -13,66 +14,100 @@ func add(graph map[string][]string, a, b string) {
 	graph[a] = append(graph[a], b)
 }
 
-type visitState int
-
-const (
-	notVisited     visitState = 0
-	visitedEntered visitState = 1
-	visitedExited  visitState = 2
-)
+type tarjanState struct {
+	graph      map[string][]string
+	index      map[string]int
+	lowLink    map[string]int
+	onStack    map[string]bool
+	stack      []string
+	components [][]string
+}
 
-func orderFrom(v string, graph map[string][]string, color map[string]visitState, parent map[string]string, order *[]string) error {
-	color[v] = visitedEntered
-	for _, u := range graph[v] {
-		if color[u] == notVisited {
-			parent[u] = v
-			if err := orderFrom(u, graph, color, parent, order); err != nil {
-				return err
-			}
-		} else if color[u] == visitedEntered {
-			cycle := []string{v}
-			for cycle[len(cycle)-1] != u {
-				cycle = append(cycle, parent[cycle[len(cycle)-1]])
-			}
-			return fmt.Errorf("possible rules cycle detected: %v", cycle)
+func (t *tarjanState) connect(v string) {
+	t.index[v] = len(t.index)
+	t.lowLink[v] = t.index[v]
+	t.stack = append(t.stack, v)
+	t.onStack[v] = true
+	for _, u := range t.graph[v] {
+		if _, visited := t.index[u]; !visited {
+			t.connect(u)
+			t.lowLink[v] = min(t.lowLink[v], t.lowLink[u])
+		} else if t.onStack[u] {
+			t.lowLink[v] = min(t.lowLink[v], t.index[u])
 		}
 	}
-	color[v] = visitedExited
-	*order = append(*order, v)
-	return nil
+	if t.lowLink[v] != t.index[v] {
+		return
+	}
+	component := make([]string, 0)
+	for {
+		u := t.stack[len(t.stack)-1]
+		t.stack = t.stack[:len(t.stack)-1]
+		t.onStack[u] = false
+		component = append(component, u)
+		if u == v {
+			break
+		}
+	}
+	t.components = append(t.components, component)
 }
 
-func topoSort(graph map[string][]string) ([]string, map[string]int, error) {
-	color := make(map[string]visitState)
-	parent := make(map[string]string)
-	sequence := make([]string, 0, len(graph))
-	for v := range graph {
-		if color[v] == visitedExited {
-			continue
-		}
-		err := orderFrom(v, graph, color, parent, &sequence)
-		if err != nil {
-			return nil, nil, err
+func componentSort(vertices []string, graph map[string][]string) ([][]string, error) {
+	state := tarjanState{
+		graph:   graph,
+		index:   make(map[string]int),
+		lowLink: make(map[string]int),
+		onStack: make(map[string]bool),
+	}
+	for _, v := range vertices {
+		if _, visited := state.index[v]; !visited {
+			state.connect(v)
 		}
 	}
-	for i, j := 0, len(sequence)-1; i < j; i, j = i+1, j-1 {
-		sequence[i], sequence[j] = sequence[j], sequence[i]
+	definitionOrder := make(map[string]int)
+	for i, v := range vertices {
+		definitionOrder[v] = i
+	}
+	components := state.components
+	for i, j := 0, len(components)-1; i < j; i, j = i+1, j-1 {
+		components[i], components[j] = components[j], components[i]
 	}
 	position := make(map[string]int)
-	for i, v := range sequence {
-		position[v] = i
+	for i, component := range components {
+		slices.SortFunc(component, func(a, b string) int { return definitionOrder[a] - definitionOrder[b] })
+		for _, v := range component {
+			position[v] = i
+		}
 	}
-	for _, v := range sequence {
+	for _, v := range vertices {
 		for _, next := range graph[v] {
-			if position[next] <= position[v] {
-				return nil, nil, fmt.Errorf("cycle detected: incorrect order between rules %v and %v", v, next)
+			if position[next] < position[v] {
+				return nil, fmt.Errorf("cycle detected: incorrect order between rules %v and %v", v, next)
 			}
 		}
 	}
-	return sequence, position, nil
+	return components, nil
 }
 
 func OrderRules(rules definition.Rules) ([]string, map[string]int, error) {
+	components, _, err := OrderRuleComponents(rules)
+	if err != nil {
+		return nil, nil, err
+	}
+	order := make([]string, 0, len(rules))
+	for _, component := range components {
+		order = append(order, component...)
+	}
+	position := make(map[string]int)
+	for i, v := range order {
+		position[v] = i
+	}
+	return order, position, nil
+}
+
+// OrderRuleComponents groups mutually left-recursive rules together and orders groups in such a way
+// that rule can depend on the rule at the same position only from its own or subsequent group
+func OrderRuleComponents(rules definition.Rules) ([][]string, map[string]bool, error) {
 	if _, err := analysis.CheckRulesConsistency(rules); err != nil {
 		panic(fmt.Errorf("rules must be consistent: %w", err))
 	}
@@ -110,6 +145,8 @@ func OrderRules(rules definition.Rules) ([]string, map[string]int, error) {
 			ruleNonEmptyDeps[rule.Name] = ruleForwardDeps[rule.Name]
 		case definition.Negation:
 			ruleNonEmptyDeps[rule.Name] = ruleForwardDeps[rule.Name]
+		case definition.Recovery:
+			ruleNonEmptyDeps[rule.Name] = ruleForwardDeps[rule.Name]
 		case definition.Kleene:
 			ruleNonEmptyDeps[rule.Name] = ruleForwardDeps[rule.Name]
 		case definition.Symbol:
@@ -120,5 +157,19 @@ func OrderRules(rules definition.Rules) ([]string, map[string]int, error) {
 			panic(fmt.Errorf("unexpected peg expression type: %#v", rule.Expr))
 		}
 	}
-	return topoSort(ruleNonEmptyDeps)
+	vertices := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		vertices = append(vertices, rule.Name)
+	}
+	components, err := componentSort(vertices, ruleNonEmptyDeps)
+	if err != nil {
+		return nil, nil, err
+	}
+	recursive := make(map[string]bool)
+	for _, component := range components {
+		for _, v := range component {
+			recursive[v] = len(component) > 1 || slices.Contains(ruleNonEmptyDeps[v], v)
+		}
+	}
+	return components, recursive, nil
 }
@@ -1,232 +1,342 @@
 package parser
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"github.com/sivukhin/gopeg/analysis"
 	"github.com/sivukhin/gopeg/definition"
+	"math"
 )
 
-type step struct {
-	ok      bool
-	advance int
-}
-
 var (
 	TextNotMatchErr = errors.New("TextNotMatch")
 )
 
-func ParseAtoms(rules definition.Rules, root string, atoms []definition.Atom) (*ParsingNode, error) {
-	terminalsType, err := analysis.CheckRulesConsistency(rules)
+type grammar struct {
+	ruleMap        map[string]definition.Rule
+	order          []string
+	names          []string
+	sources        []string
+	position       map[string]int
+	exprs          []definition.Expr
+	components     [][]int
+	componentOf    []int
+	recursive      []bool
+	program        *program
+	shapes         map[string]nodeShape
+	flattened      map[string]bool
+	terminalType   analysis.TerminalType
+	transformation analysis.Transformation
+}
+
+func prepareGrammar(rules definition.Rules, terminalType analysis.TerminalType) (*grammar, error) {
+	g, err := compileGrammar(rules)
+	if err != nil {
+		return nil, err
+	}
+	if err := g.checkTerminalType(terminalType); err != nil {
+		return nil, err
+	}
+	return g, nil
+}
+
+func compileGrammar(rules definition.Rules) (*grammar, error) {
+	terminalType, err := analysis.CheckRulesConsistency(rules)
 	if err != nil {
 		return nil, fmt.Errorf("rules must be consistent: %w", err)
 	}
-	if terminalsType != analysis.AnyTerminalType && terminalsType != analysis.AtomTerminalType {
-		return nil, fmt.Errorf("rules must be compatible with %v", analysis.AtomTerminalType)
+	shapes, err := buildNodeShapes(rules)
+	if err != nil {
+		return nil, err
 	}
 	rules = analysis.DesugarRules(rules)
 	rules, transformation := analysis.NormalizeRules(rules)
-	order, position, err := OrderRules(rules)
+	components, recursive, err := OrderRuleComponents(rules)
 	if err != nil {
 		return nil, fmt.Errorf("unable to topologically order rules: %w", err)
 	}
-	ruleMap := buildRuleMap(rules)
-	table := buildStepTable(ruleMap, order, position, atoms)
-	derivation, err := buildDerivationTree(ruleMap, transformation.Forward[root], position, table, atoms)
+	g := &grammar{
+		shapes:         shapes,
+		flattened:      flattenedSymbols(shapes),
+		ruleMap:        buildRuleMap(rules),
+		position:       make(map[string]int),
+		terminalType:   terminalType,
+		transformation: transformation,
+	}
+	for c, component := range components {
+		indices := make([]int, 0, len(component))
+		for _, name := range component {
+			g.position[name] = len(g.order)
+			indices = append(indices, len(g.order))
+			g.order = append(g.order, name)
+			g.exprs = append(g.exprs, g.ruleMap[name].Expr)
+			g.componentOf = append(g.componentOf, c)
+			g.recursive = append(g.recursive, recursive[name])
+		}
+		g.components = append(g.components, indices)
+	}
+	for _, name := range g.order {
+		g.names = append(g.names, g.denormalize(definition.NewSymbol(name)).String())
+		g.sources = append(g.sources, transformation.Source(name))
+	}
+	g.program = compileProgram(g)
+	return g, nil
+}
+
+func (g *grammar) checkTerminalType(terminalType analysis.TerminalType) error {
+	if g.terminalType != analysis.AnyTerminalType && g.terminalType != terminalType {
+		return fmt.Errorf("rules must be compatible with %v", terminalType)
+	}
+	return nil
+}
+
+func (g *grammar) rootRule(root string) (int, error) {
+	name, ok := g.transformation.Forward[root]
+	if !ok {
+		return 0, fmt.Errorf("root rule '%v' is not defined", root)
+	}
+	return g.position[name], nil
+}
+
+func parse[T any](g *grammar, data []T, options parseOptions) (*ParsingNode, error) {
+	e, err := newEvaluator(g, data, options)
+	if err != nil {
+		return nil, err
+	}
+	return parseWith(e)
+}
+
+func parseWith[T any](e *evaluator[T]) (*ParsingNode, error) {
+	g, data := e.grammar, e.data
+	rootRule, err := g.rootRule(e.options.root)
+	if err != nil {
+		return nil, err
+	}
+	rootStep := e.steps.stepAt(0, rootRule)
+	if e.err != nil {
+		return nil, e.err
+	}
+	if !rootStep.ok || (e.options.matchMode == FullMatch && rootStep.advance != len(data)) {
+		return nil, newParseError(e, rootRule, rootStep)
+	}
+	parsing, _, err := buildTree(e, rootRule, rootStep)
 	if err != nil {
 		return nil, err
 	}
-	parsing := transform(transformation.Backward, derivation)
 	if len(parsing) != 1 {
 		return nil, fmt.Errorf("tree with multiple root was formed")
 	}
+	if err := e.options.checkTree(parsing[0]); err != nil {
+		return nil, err
+	}
 	return parsing[0], nil
 }
 
-func ParseText(rules definition.Rules, root string, text []byte) (*ParsingNode, error) {
-	terminalsType, err := analysis.CheckRulesConsistency(rules)
+func ParseAtoms(rules definition.Rules, root string, atoms []definition.Atom, opts ...Option) (*ParsingNode, error) {
+	g, err := Compile(rules)
 	if err != nil {
-		return nil, fmt.Errorf("rules must be consistent: %w", err)
+		return nil, err
 	}
-	if terminalsType != analysis.AnyTerminalType && terminalsType != analysis.ByteTerminalType {
-		return nil, fmt.Errorf("rules must be compatible with %v", analysis.ByteTerminalType)
+	return g.ParseAtoms(atoms, withRoot(root, opts)...)
+}
+
+func ParseText(rules definition.Rules, root string, text []byte, opts ...Option) (*ParsingNode, error) {
+	g, err := Compile(rules)
+	if err != nil {
+		return nil, err
 	}
-	rules = analysis.DesugarRules(rules)
-	rules, transformation := analysis.NormalizeRules(rules)
-	order, position, err := OrderRules(rules)
+	return g.ParseText(text, withRoot(root, opts)...)
+}
+
+func ParseAtomsContext(ctx context.Context, rules definition.Rules, root string, atoms []definition.Atom, opts ...Option) (*ParsingNode, error) {
+	g, err := Compile(rules)
 	if err != nil {
-		return nil, fmt.Errorf("unable to topologically order rules: %w", err)
+		return nil, err
 	}
-	ruleMap := buildRuleMap(rules)
-	table := buildStepTable(ruleMap, order, position, text)
-	derivation, err := buildDerivationTree(ruleMap, transformation.Forward[root], position, table, text)
+	return g.ParseAtomsContext(ctx, atoms, withRoot(root, opts)...)
+}
+
+func ParseTextContext(ctx context.Context, rules definition.Rules, root string, text []byte, opts ...Option) (*ParsingNode, error) {
+	g, err := Compile(rules)
 	if err != nil {
 		return nil, err
 	}
-	parsing := transform(transformation.Backward, derivation)
-	if len(parsing) != 1 {
-		return nil, fmt.Errorf("tree with multiple root was formed")
+	return g.ParseTextContext(ctx, text, withRoot(root, opts)...)
+}
+
+type derivationFrame struct {
+	segment definition.Segment
+	rule    int
+	seq     int
+}
+
+// deriveStep reads the step which was observed by the parent rule during its evaluation:
+// left-recursive rules see previous versions of the cells from their own group at the same position
+func deriveStep[T any](e *evaluator[T], parent derivationFrame, p int, expr definition.Expr) (step, int) {
+	g, steps := e.grammar, e.steps
+	symbol, ok := expr.(definition.Symbol)
+	if !ok {
+		return e.advance(p, expr), 0
 	}
-	return parsing[0], nil
+	s := g.position[symbol.Name]
+	if !g.recursive[s] {
+		return steps.stepAt(p, s), 0
+	}
+	limit := math.MaxInt
+	if p == parent.segment.Start && g.componentOf[s] == g.componentOf[parent.rule] {
+		limit = parent.seq
+	}
+	steps.stepAt(p, s)
+	v := steps.versionBefore(p, s, limit)
+	return v.step, v.seq
 }
 
-func advance[T any](i int, expr definition.Expr, position map[string]int, table [][]step, data []T) step {
-	switch peg := expr.(type) {
-	case definition.Terminals:
-		advance, ok := definition.Accept[T](peg, data, i)
-		return step{ok: ok, advance: advance}
-	case definition.Symbol:
-		return table[i][position[peg.Name]]
-	default:
-		panic(fmt.Errorf("invalid usage of advance: unexpected peg expression type: %#v", expr))
-	}
-}
-
-func buildDerivationTree[T any](
-	ruleMap map[string]definition.Rule,
-	root string,
-	position map[string]int,
-	table [][]step,
-	data []T,
-) (*ParsingNode, error) {
-	if !table[0][position[root]].ok {
-		return nil, TextNotMatchErr
-	}
-	rootNode := NewParsingNode[T](
-		root,
-		nil,
-		data,
-		definition.Segment{Start: 0, End: table[0][position[root]].advance},
-	)
-	derivation := []*ParsingNode{&rootNode}
-	for i := 0; i < len(derivation); i++ {
-		current := derivation[i]
-		switch peg := ruleMap[current.Atom.Symbol].Expr.(type) {
-		case definition.Terminals:
-			continue
-		case definition.Negation:
-			continue
+func rootDerivationFrame[T any](e *evaluator[T], root int, rootStep step) derivationFrame {
+	rootFrame := derivationFrame{segment: definition.Segment{Start: 0, End: rootStep.advance}, rule: root, seq: math.MaxInt}
+	if e.grammar.recursive[rootFrame.rule] {
+		rootFrame.seq = e.steps.versionBefore(0, rootFrame.rule, math.MaxInt).seq
+	}
+	return rootFrame
+}
+
+// deriveChildren reports symbols matched by the rule of the frame in the order of their appearance
+// together with the segments which were recovered by the recovery expressions;
+// non-empty matches of terminals are reported too if terminal callback is set
+func deriveChildren[T any](
+	e *evaluator[T],
+	frame derivationFrame,
+	child func(symbol definition.Symbol, next derivationFrame),
+	terminal func(expr definition.Terminals, segment definition.Segment),
+	recovered func(segment definition.Segment, expr definition.Expr),
+) {
+	g := e.grammar
+	addChild := func(expr definition.Expr, segment definition.Segment, seq int) {
+		switch peg := expr.(type) {
 		case definition.Symbol:
-			next := NewParsingNode[T](peg.Name, peg.Attributes, data, current.Segment)
-			current.Children = append(current.Children, &next)
-			derivation = append(derivation, &next)
-			continue
-		case definition.Kleene:
-			p := current.Segment.Start
-			for {
-				step := advance(p, peg.Expr, position, table, data)
-				if !step.ok || step.advance == 0 {
-					break
-				}
-				if s, ok := peg.Expr.(definition.Symbol); ok {
-					next := NewParsingNode[T](s.Name, s.Attributes, data, definition.Segment{Start: p, End: p + step.advance})
-					current.Children = append(current.Children, &next)
-					derivation = append(derivation, &next)
-				}
-				p += step.advance
-			}
-		case definition.Junction:
-			p := current.Segment.Start
-			for _, j := range peg.Exprs {
-				step := advance(p, j, position, table, data)
-				if s, ok := j.(definition.Symbol); ok {
-					next := NewParsingNode[T](s.Name, s.Attributes, data, definition.Segment{Start: p, End: p + step.advance})
-					current.Children = append(current.Children, &next)
-					derivation = append(derivation, &next)
-				}
-				p += step.advance
+			child(peg, derivationFrame{segment: segment, rule: g.position[peg.Name], seq: seq})
+		case definition.Terminals:
+			if terminal != nil && segment.Length() > 0 {
+				terminal(peg, segment)
 			}
-		case definition.Choice:
-			for _, c := range peg.Exprs {
-				step := advance(current.Segment.Start, c, position, table, data)
-				if !step.ok {
-					continue
-				}
-				if s, ok := c.(definition.Symbol); ok {
-					next := NewParsingNode[T](s.Name, s.Attributes, data, definition.Segment{Start: current.Segment.Start, End: current.Segment.Start + step.advance})
-					current.Children = append(current.Children, &next)
-					derivation = append(derivation, &next)
-				}
+		}
+	}
+	start := frame.segment.Start
+	switch peg := g.exprs[frame.rule].(type) {
+	case definition.Terminals:
+		addChild(peg, frame.segment, 0)
+	case definition.Symbol:
+		_, seq := deriveStep(e, frame, start, peg)
+		addChild(peg, frame.segment, seq)
+	case definition.Kleene:
+		p := start
+		for {
+			step, seq := deriveStep(e, frame, p, peg.Expr)
+			if !step.ok || step.advance == 0 {
 				break
 			}
+			addChild(peg.Expr, definition.Segment{Start: p, End: p + step.advance}, seq)
+			p += step.advance
+		}
+	case definition.Junction:
+		p := start
+		for _, j := range peg.Exprs {
+			step, seq := deriveStep(e, frame, p, j)
+			addChild(j, definition.Segment{Start: p, End: p + step.advance}, seq)
+			p += step.advance
+		}
+	case definition.Choice:
+		for _, c := range peg.Exprs {
+			step, seq := deriveStep(e, frame, start, c)
+			if !step.ok {
+				continue
+			}
+			addChild(c, definition.Segment{Start: start, End: start + step.advance}, seq)
+			break
+		}
+	case definition.Recovery:
+		step, seq := deriveStep(e, frame, start, peg.Expr)
+		if step.ok {
+			addChild(peg.Expr, definition.Segment{Start: start, End: start + step.advance}, seq)
+			break
 		}
+		recovered(frame.segment, peg.Expr)
 	}
-	return &rootNode, nil
-}
-
-func buildStepTable[T any](ruleMap map[string]definition.Rule, order []string, position map[string]int, data []T) [][]step {
-	// todo (sivukhin, 2023-09-02): should we use single table of size (len(text)+1) * len(order) in order to reduce amount of allocations and GC pressure?
-	table := make([][]step, len(data)+1)
-	for i := 0; i <= len(data); i++ {
-		table[i] = make([]step, len(order))
-	}
-
-	for i := len(data); i >= 0; i-- {
-		for s := len(order) - 1; s >= 0; s-- {
-			switch peg := ruleMap[order[s]].Expr.(type) {
-			case definition.Terminals:
-				table[i][s] = advance(i, peg, position, table, data)
-			case definition.Symbol:
-				table[i][s] = advance(i, peg, position, table, data)
-			case definition.Kleene:
-				next := advance(i, peg.Expr, position, table, data)
-				if next.ok && next.advance > 0 {
-					table[i][s] = step{ok: true, advance: table[i+next.advance][s].advance + next.advance}
-				} else {
-					table[i][s] = step{ok: true, advance: 0}
-				}
-			case definition.Junction:
-				current := i
-				ok := true
-				for _, j := range peg.Exprs {
-					next := advance(current, j, position, table, data)
-					if !next.ok {
-						ok = false
-					} else {
-						current += next.advance
-					}
-				}
-				if ok {
-					table[i][s] = step{ok: true, advance: current - i}
-				}
-			case definition.Choice:
-				for _, c := range peg.Exprs {
-					next := advance(i, c, position, table, data)
-					if next.ok {
-						table[i][s] = next
-						break
-					}
-				}
-			case definition.Negation:
-				next := advance(i, peg.Expr, position, table, data)
-				if !next.ok {
-					table[i][s] = step{ok: true, advance: 0}
+}
+
+// buildDerivationTree stops as soon as the nodes which will surely stay in the transformed tree exceed the limits:
+// depth of the derivation node is the depth of its closest ancestor which stays in the tree and children of pruned nodes are not counted
+func buildDerivationTree[T any](e *evaluator[T], root int, rootStep step, limits *treeLimits) (*ParsingNode, []Diagnostic, error) {
+	g, data, concrete := e.grammar, e.data, e.options.concrete
+	rootFrame := rootDerivationFrame(e, root, rootStep)
+	rootNode := NewParsingNode[T](g.order[root], nil, data, rootFrame.segment)
+	type derivationItem struct {
+		node   *ParsingNode
+		frame  derivationFrame
+		depth  int
+		pruned bool
+	}
+	rootItem := derivationItem{node: &rootNode, frame: rootFrame, pruned: g.pruned(g.order[root], concrete)}
+	if _, ok := g.kept(g.order[root], concrete); ok {
+		rootItem.depth = 1
+		limits.add(rootItem.depth)
+	}
+	derivation := []derivationItem{rootItem}
+	diagnostics := make([]Diagnostic, 0)
+	for i := 0; i < len(derivation) && limits.exceeded() == nil; i++ {
+		current, depth, pruned := derivation[i].node, derivation[i].depth, derivation[i].pruned
+		var terminal func(expr definition.Terminals, segment definition.Segment)
+		if concrete {
+			terminal = func(expr definition.Terminals, segment definition.Segment) {
+				leaf := NewParsingNode[T](expr.String(), nil, data, segment)
+				leaf.Kind = AnonymousNode
+				current.Children = append(current.Children, &leaf)
+				if !pruned {
+					limits.add(depth + 1)
 				}
 			}
 		}
+		deriveChildren(e, derivation[i].frame, func(symbol definition.Symbol, next derivationFrame) {
+			child := NewParsingNode[T](symbol.Name, symbol.Attributes, data, next.segment)
+			current.Children = append(current.Children, &child)
+			item := derivationItem{node: &child, frame: next, depth: depth, pruned: pruned || g.pruned(symbol.Name, concrete)}
+			if !pruned && g.persistent(symbol.Name, concrete) {
+				item.depth++
+				limits.add(item.depth)
+			}
+			derivation = append(derivation, item)
+		}, terminal, func(segment definition.Segment, expr definition.Expr) {
+			current.Children = append(current.Children, newErrorNode(data, segment))
+			diagnostics = append(diagnostics, Diagnostic{
+				Segment: segment,
+				Error:   newRecoveryError(e, segment.Start, expr),
+			})
+			if !pruned {
+				limits.add(depth + 1)
+			}
+		})
+	}
+	if err := limits.exceeded(); err != nil {
+		return nil, nil, err
 	}
-	return table
+	return &rootNode, diagnostics, nil
 }
 
-func transform(mapping map[string]string, node *ParsingNode) []*ParsingNode {
-	name, ok := mapping[node.Atom.Symbol]
-	symbol, hidden := definition.AnalyzeSymbolName(name)
-	if ok && !hidden {
-		atom := node.Atom
-		atom.Symbol = symbol
-		next := ParsingNode{
-			Atom:     atom,
-			Segment:  node.Segment,
-			Children: nil,
-		}
-		for _, child := range node.Children {
-			next.Children = append(next.Children, transform(mapping, child)...)
-		}
-		return []*ParsingNode{&next}
+// buildTree derives the tree of the root rule and transforms it; limits are checked on the fly only if the root node is kept in the tree
+// because otherwise the root can be replaced with several trees which are checked separately
+func buildTree[T any](e *evaluator[T], root int, rootStep step) ([]*ParsingNode, []Diagnostic, error) {
+	var derivationLimits, limits *treeLimits
+	if _, ok := e.grammar.kept(e.grammar.order[root], e.options.concrete); ok {
+		derivationLimits, limits = newTreeLimits(e.options), newTreeLimits(e.options)
+	}
+	derivation, diagnostics, err := buildDerivationTree(e, root, rootStep, derivationLimits)
+	if err != nil {
+		return nil, nil, err
 	}
-	nodes := make([]*ParsingNode, 0, len(node.Children))
-	for _, child := range node.Children {
-		nodes = append(nodes, transform(mapping, child)...)
+	limits.add(1)
+	parsing := e.grammar.transform(derivation, e.options.concrete, limits, 1)
+	if err := limits.exceeded(); err != nil {
+		return nil, nil, err
 	}
-	return nodes
+	return parsing, diagnostics, nil
 }
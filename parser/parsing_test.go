@@ -1,6 +1,7 @@
 package parser
 
 import (
+	"github.com/sivukhin/gopeg/analysis"
 	"github.com/sivukhin/gopeg/definition"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -27,46 +28,48 @@ func TestAttributes(t *testing.T) {
 	require.Equal(t, []byte("B"), node.Children[3].Atom.Attributes["Ctx"])
 }
 
-func TestArithmetic(t *testing.T) {
-	rs := definition.Rules{
-		definition.NewRule("Expr", definition.NewSymbol("Sum")),
-		definition.NewRule("Sum", definition.NewJunction(
-			definition.NewSymbol("Product"),
-			definition.NewRepetition(
-				definition.NewJunction(
-					definition.NewChoice(
-						definition.NewTextToken("+"),
-						definition.NewTextToken("-"),
-					),
-					definition.NewSymbol("Product"),
-				),
-			),
-		)),
-		definition.NewRule("Product", definition.NewJunction(
-			definition.NewSymbol("Value"),
-			definition.NewRepetition(
-				definition.NewJunction(
-					definition.NewChoice(
-						definition.NewTextToken("*"),
-						definition.NewTextToken("/"),
-					),
-					definition.NewSymbol("Value"),
-				),
-			),
-		)),
-		definition.NewRule("Digit", definition.NewTextPattern("[0-9]")),
-		definition.NewRule("Value", definition.NewChoice(
+var arithmeticRules = definition.Rules{
+	definition.NewRule("Expr", definition.NewSymbol("Sum")),
+	definition.NewRule("Sum", definition.NewJunction(
+		definition.NewSymbol("Product"),
+		definition.NewRepetition(
 			definition.NewJunction(
-				definition.NewSymbol("Digit"),
-				definition.NewRepetition(definition.NewSymbol("Digit")),
+				definition.NewChoice(
+					definition.NewTextToken("+"),
+					definition.NewTextToken("-"),
+				),
+				definition.NewSymbol("Product"),
 			),
+		),
+	)),
+	definition.NewRule("Product", definition.NewJunction(
+		definition.NewSymbol("Value"),
+		definition.NewRepetition(
 			definition.NewJunction(
-				definition.NewTextToken("("),
-				definition.NewSymbol("Expr"),
-				definition.NewTextToken(")"),
+				definition.NewChoice(
+					definition.NewTextToken("*"),
+					definition.NewTextToken("/"),
+				),
+				definition.NewSymbol("Value"),
 			),
-		)),
-	}
+		),
+	)),
+	definition.NewRule("Digit", definition.NewTextPattern("[0-9]")),
+	definition.NewRule("Value", definition.NewChoice(
+		definition.NewJunction(
+			definition.NewSymbol("Digit"),
+			definition.NewRepetition(definition.NewSymbol("Digit")),
+		),
+		definition.NewJunction(
+			definition.NewTextToken("("),
+			definition.NewSymbol("Expr"),
+			definition.NewTextToken(")"),
+		),
+	)),
+}
+
+func TestArithmetic(t *testing.T) {
+	rs := arithmeticRules
 	text := "10+2"
 	n1, err := ParseText(rs, "Expr", []byte(text))
 	assert.Nil(t, err)
@@ -78,3 +81,68 @@ func TestArithmetic(t *testing.T) {
 	assert.Nil(t, err)
 	t.Logf("\n%v\n", StringParsingNode(n2))
 }
+
+func TestLazyStrategy(t *testing.T) {
+	for _, text := range []string{"10+2", "1+(2*4-5)-10*44", "1+(2*4-5", "+1"} {
+		t.Run(text, func(t *testing.T) {
+			table, tableErr := ParseText(arithmeticRules, "Expr", []byte(text), WithStrategy(TableStrategy))
+			lazy, lazyErr := ParseText(arithmeticRules, "Expr", []byte(text), WithStrategy(LazyStrategy))
+			require.Equal(t, tableErr, lazyErr)
+			require.Equal(t, table, lazy)
+		})
+	}
+	t.Run("visits only reachable cells", func(t *testing.T) {
+		g, err := prepareGrammar(arithmeticRules, analysis.ByteTerminalType)
+		require.Nil(t, err)
+		text := []byte("1+2")
+		lazy := newLazyTable(&evaluator[byte]{grammar: g, data: text})
+		require.True(t, lazy.stepAt(0, g.position[g.transformation.Forward["Expr"]]).ok)
+		require.Less(t, len(lazy.memo), (len(text)+1)*len(g.order))
+	})
+}
+
+func TestLeftRecursion(t *testing.T) {
+	t.Run("direct", func(t *testing.T) {
+		rs := definition.Rules{
+			definition.NewRule("Sum", definition.NewChoice(
+				definition.NewJunction(definition.NewSymbol("Sum"), definition.NewTextToken("-"), definition.NewSymbol("Num")),
+				definition.NewSymbol("Num"),
+			)),
+			definition.NewRule("Num", definition.NewTextPattern("[0-9]+")),
+		}
+		for _, strategy := range []Strategy{TableStrategy, LazyStrategy} {
+			node, err := ParseText(rs, "Sum", []byte("7-2-1"), WithStrategy(strategy))
+			require.Nil(t, err)
+			require.Equal(t, `Sum[0..5): '7-2-1'
+  Sum[0..3): '7-2'
+    Sum[0..1): '7'
+      Num[0..1): '7'
+    Num[2..3): '2'
+  Num[4..5): '1'
+`, StringParsingNode(node))
+		}
+	})
+	t.Run("indirect", func(t *testing.T) {
+		rs := definition.Rules{
+			definition.NewRule("Call", definition.NewJunction(definition.NewSymbol("Expr"), definition.NewTextToken("()"))),
+			definition.NewRule("Expr", definition.NewChoice(definition.NewSymbol("Call"), definition.NewTextPattern("[a-z]+"))),
+		}
+		for _, strategy := range []Strategy{TableStrategy, LazyStrategy} {
+			node, err := ParseText(rs, "Expr", []byte("f()()"), WithStrategy(strategy))
+			require.Nil(t, err)
+			require.Equal(t, `Expr[0..5): 'f()()'
+  Call[0..5): 'f()()'
+    Expr[0..3): 'f()'
+      Call[0..3): 'f()'
+        Expr[0..1): 'f'
+`, StringParsingNode(node))
+		}
+	})
+	t.Run("non-recursive grammar", func(t *testing.T) {
+		g, err := prepareGrammar(arithmeticRules, analysis.ByteTerminalType)
+		require.Nil(t, err)
+		for _, recursive := range g.recursive {
+			require.False(t, recursive)
+		}
+	})
+}
@@ -0,0 +1,176 @@
+package parser
+
+import (
+	"compress/gzip"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sort"
+	"text/tabwriter"
+	"time"
+)
+
+type (
+	// RuleProfile accumulates statistics of all normalized rules originated from the same source rule
+	RuleProfile struct {
+		Rule      string
+		Cells     int
+		Successes int
+		Failures  int
+		// Consumed is the total advance of the successful cells (in bytes for text and in atoms for atoms)
+		Consumed int
+		// PatternTime is the time spent in matching of TextPattern terminals referenced directly by the rule
+		PatternTime time.Duration
+	}
+	// Profile accumulates statistics over all parses which received it with WithProfile option;
+	// it must not be shared between concurrent parses
+	Profile struct {
+		Rules []RuleProfile
+		index map[string]int
+	}
+	profiler struct {
+		profile *Profile
+		// slots maps rule index of the grammar to the position in the profile
+		slots []int
+		// stack holds rules which are currently evaluated in order to attribute pattern matching time
+		stack []int
+	}
+)
+
+func newProfiler(g *grammar, profile *Profile) *profiler {
+	if profile.index == nil {
+		profile.index = make(map[string]int)
+		for k, rule := range profile.Rules {
+			profile.index[rule.Rule] = k
+		}
+	}
+	slots := make([]int, 0, len(g.sources))
+	for _, source := range g.sources {
+		slot, ok := profile.index[source]
+		if !ok {
+			slot = len(profile.Rules)
+			profile.index[source] = slot
+			profile.Rules = append(profile.Rules, RuleProfile{Rule: source})
+		}
+		slots = append(slots, slot)
+	}
+	return &profiler{profile: profile, slots: slots}
+}
+
+func (p *profiler) push(s int) {
+	if p != nil {
+		p.stack = append(p.stack, s)
+	}
+}
+
+func (p *profiler) pop() {
+	if p != nil {
+		p.stack = p.stack[:len(p.stack)-1]
+	}
+}
+
+func (p *profiler) record(s int, result step) {
+	if p == nil {
+		return
+	}
+	rule := &p.profile.Rules[p.slots[s]]
+	rule.Cells++
+	if result.ok {
+		rule.Successes++
+		rule.Consumed += result.advance
+	} else {
+		rule.Failures++
+	}
+}
+
+func (p *profiler) measure(elapsed time.Duration) {
+	// patterns can be matched outside of rules evaluation (for example, while collecting failures for the error message)
+	if len(p.stack) > 0 {
+		p.profile.Rules[p.slots[p.stack[len(p.stack)-1]]].PatternTime += elapsed
+	}
+}
+
+// sorted returns rules ordered by the amount of evaluated cells
+func (p *Profile) sorted() []RuleProfile {
+	rules := append([]RuleProfile(nil), p.Rules...)
+	sort.SliceStable(rules, func(i, j int) bool {
+		if rules[i].Cells != rules[j].Cells {
+			return rules[i].Cells > rules[j].Cells
+		}
+		return rules[i].Rule < rules[j].Rule
+	})
+	return rules
+}
+
+// WriteText writes the profile as a table ordered by the amount of evaluated cells
+func (p *Profile) WriteText(writer io.Writer) error {
+	table := tabwriter.NewWriter(writer, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(table, "rule\tcells\tsuccesses\tfailures\tconsumed\tpattern time\t")
+	for _, rule := range p.sorted() {
+		_, _ = fmt.Fprintf(table, "%v\t%v\t%v\t%v\t%v\t%v\t\n", rule.Rule, rule.Cells, rule.Successes, rule.Failures, rule.Consumed, rule.PatternTime)
+	}
+	return table.Flush()
+}
+
+// WritePprof writes the profile in the gzipped profile.proto format with one sample per rule, so it can be inspected with go tool pprof
+func (p *Profile) WritePprof(writer io.Writer) error {
+	strings := []string{"", "cells", "count", "successes", "failures", "consumed", "elements", "pattern", "nanoseconds"}
+	valueType := func(name, unit int) []byte {
+		var message protoMessage
+		message.varint(1, uint64(name))
+		message.varint(2, uint64(unit))
+		return message
+	}
+	var profile protoMessage
+	profile.bytes(1, valueType(1, 2))
+	profile.bytes(1, valueType(3, 2))
+	profile.bytes(1, valueType(4, 2))
+	profile.bytes(1, valueType(5, 6))
+	profile.bytes(1, valueType(7, 8))
+	for k, rule := range p.sorted() {
+		id := uint64(k + 1)
+		var sample, locations, values protoMessage
+		locations = binary.AppendUvarint(locations, id)
+		for _, value := range []int64{int64(rule.Cells), int64(rule.Successes), int64(rule.Failures), int64(rule.Consumed), rule.PatternTime.Nanoseconds()} {
+			values = binary.AppendUvarint(values, uint64(value))
+		}
+		sample.bytes(1, locations)
+		sample.bytes(2, values)
+		profile.bytes(2, sample)
+
+		var location, line protoMessage
+		line.varint(1, id)
+		location.varint(1, id)
+		location.bytes(4, line)
+		profile.bytes(4, location)
+
+		var function protoMessage
+		function.varint(1, id)
+		function.varint(2, uint64(len(strings)))
+		function.varint(3, uint64(len(strings)))
+		profile.bytes(5, function)
+		strings = append(strings, rule.Rule)
+	}
+	for _, s := range strings {
+		profile.bytes(6, []byte(s))
+	}
+	compressed := gzip.NewWriter(writer)
+	if _, err := compressed.Write(profile); err != nil {
+		return err
+	}
+	return compressed.Close()
+}
+
+// protoMessage is the minimal protobuf wire format encoder sufficient for the profile.proto messages
+type protoMessage []byte
+
+func (m *protoMessage) varint(field int, value uint64) {
+	*m = binary.AppendUvarint(*m, uint64(field)<<3)
+	*m = binary.AppendUvarint(*m, value)
+}
+
+func (m *protoMessage) bytes(field int, value []byte) {
+	*m = binary.AppendUvarint(*m, uint64(field)<<3|2)
+	*m = binary.AppendUvarint(*m, uint64(len(value)))
+	*m = append(*m, value...)
+}
@@ -0,0 +1,75 @@
+package parser
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func profiledRule(profile *Profile, name string) RuleProfile {
+	for _, rule := range profile.Rules {
+		if rule.Rule == name {
+			return rule
+		}
+	}
+	return RuleProfile{}
+}
+
+func TestProfile(t *testing.T) {
+	t.Run("statistics", func(t *testing.T) {
+		for _, strategy := range []Strategy{TableStrategy, LazyStrategy, VMStrategy} {
+			var profile Profile
+			_, err := ParseText(arithmeticRules, "Expr", []byte("1+2*3"), WithStrategy(strategy), WithProfile(&profile))
+			require.Nil(t, err)
+			require.Len(t, profile.Rules, len(arithmeticRules))
+			for _, rule := range profile.Rules {
+				require.NotContains(t, rule.Rule, "#")
+				require.Equal(t, rule.Cells, rule.Successes+rule.Failures)
+			}
+			digit := profiledRule(&profile, "Digit")
+			require.Equal(t, 3, digit.Successes)
+			require.Equal(t, 3, digit.Consumed)
+			require.Positive(t, digit.Failures)
+			require.Positive(t, digit.PatternTime)
+			require.Zero(t, profiledRule(&profile, "Sum").PatternTime)
+			require.GreaterOrEqual(t, profiledRule(&profile, "Sum").Successes, 2)
+		}
+	})
+	t.Run("accumulation", func(t *testing.T) {
+		g, err := Compile(arithmeticRules, WithStrategy(LazyStrategy))
+		require.Nil(t, err)
+		var profile Profile
+		_, err = g.ParseText([]byte("1"), WithProfile(&profile))
+		require.Nil(t, err)
+		require.Equal(t, 1, profiledRule(&profile, "Expr").Cells)
+		_, err = g.ParseText([]byte("2"), WithProfile(&profile))
+		require.Nil(t, err)
+		require.Equal(t, 2, profiledRule(&profile, "Expr").Cells)
+		require.Len(t, profile.Rules, len(arithmeticRules))
+	})
+	t.Run("formats", func(t *testing.T) {
+		var profile Profile
+		_, err := ParseText(arithmeticRules, "Expr", []byte("1+2"), WithProfile(&profile))
+		require.Nil(t, err)
+
+		var text strings.Builder
+		require.Nil(t, profile.WriteText(&text))
+		lines := strings.Split(strings.TrimSpace(text.String()), "\n")
+		require.Len(t, lines, len(arithmeticRules)+1)
+		require.Equal(t, []string{"rule", "cells", "successes", "failures", "consumed", "pattern", "time"}, strings.Fields(lines[0]))
+
+		var pprof bytes.Buffer
+		require.Nil(t, profile.WritePprof(&pprof))
+		reader, err := gzip.NewReader(&pprof)
+		require.Nil(t, err)
+		decoded, err := io.ReadAll(reader)
+		require.Nil(t, err)
+		for _, name := range []string{"cells", "nanoseconds", "Digit", "Expr"} {
+			require.True(t, bytes.Contains(decoded, []byte(name)))
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package parser
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+// Diagnostic describes the span of input skipped by the parser in the recovery mode
+type Diagnostic struct {
+	Segment definition.Segment
+	Error   *ParseError
+}
+
+func newErrorNode[T any](data []T, segment definition.Segment) *ParsingNode {
+	node := NewParsingNode[T](ErrorSymbol, nil, data, segment)
+	node.Kind = ErrorNode
+	return &node
+}
+
+// parseWithRecovery always builds the tree covering whole input:
+// recovery expressions skip input until the sync expression and unparsed tail is attached to the root as an error node
+func parseWithRecovery[T any](g *grammar, data []T, options parseOptions) (*ParsingNode, []Diagnostic, error) {
+	options.recovering = true
+	e, err := newEvaluator(g, data, options)
+	if err != nil {
+		return nil, nil, err
+	}
+	root := options.root
+	rootRule, err := g.rootRule(root)
+	if err != nil {
+		return nil, nil, err
+	}
+	rootStep := e.steps.stepAt(0, rootRule)
+	if e.err != nil {
+		return nil, nil, e.err
+	}
+	if !rootStep.ok {
+		rootNode := NewParsingNode[T](root, nil, data, definition.Segment{Start: 0, End: len(data)})
+		rootNode.Children = []*ParsingNode{newErrorNode(data, rootNode.Segment)}
+		return &rootNode, []Diagnostic{{Segment: rootNode.Segment, Error: newParseError(e, rootRule, rootStep)}}, nil
+	}
+	parsing, diagnostics, err := buildTree(e, rootRule, rootStep)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(parsing) != 1 {
+		return nil, nil, fmt.Errorf("tree with multiple root was formed")
+	}
+	if err := options.checkTree(parsing[0]); err != nil {
+		return nil, nil, err
+	}
+	rootNode := parsing[0]
+	if rootStep.advance != len(data) {
+		tail := definition.Segment{Start: rootStep.advance, End: len(data)}
+		extended := NewParsingNode[T](rootNode.Atom.Symbol, rootNode.Atom.Attributes, data, definition.Segment{Start: 0, End: len(data)})
+		extended.Children = append(rootNode.Children, newErrorNode(data, tail))
+		rootNode = &extended
+		diagnostics = append(diagnostics, Diagnostic{Segment: tail, Error: newParseError(e, rootRule, rootStep)})
+	}
+	slices.SortStableFunc(diagnostics, func(a, b Diagnostic) int { return a.Segment.Start - b.Segment.Start })
+	return rootNode, diagnostics, nil
+}
+
+func ParseAtomsWithRecovery(rules definition.Rules, root string, atoms []definition.Atom, opts ...Option) (*ParsingNode, []Diagnostic, error) {
+	g, err := Compile(rules)
+	if err != nil {
+		return nil, nil, err
+	}
+	return g.ParseAtomsWithRecovery(atoms, withRoot(root, opts)...)
+}
+
+func ParseTextWithRecovery(rules definition.Rules, root string, text []byte, opts ...Option) (*ParsingNode, []Diagnostic, error) {
+	g, err := Compile(rules)
+	if err != nil {
+		return nil, nil, err
+	}
+	return g.ParseTextWithRecovery(text, withRoot(root, opts)...)
+}
@@ -0,0 +1,64 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+var statementRules = append(definition.Rules{
+	definition.NewRule("Statements", definition.NewRepetition(definition.NewJunction(
+		definition.NewSymbol("Statement"),
+		definition.NewTextToken(";"),
+	))),
+	definition.NewRule("Statement", definition.NewRecovery(
+		definition.NewJunction(definition.NewSymbol("Expr"), definition.NewEnsure(definition.NewTextToken(";"))),
+		definition.NewTextToken(";"),
+	)),
+}, arithmeticRules...)
+
+func TestRecovery(t *testing.T) {
+	for _, strategy := range []Strategy{TableStrategy, LazyStrategy} {
+		t.Run("skip until sync", func(t *testing.T) {
+			node, diagnostics, err := ParseTextWithRecovery(statementRules, "Statements", []byte("1+2;1+*;3*4;"), WithStrategy(strategy))
+			require.Nil(t, err)
+			require.Equal(t, 12, node.Segment.Length())
+			require.Len(t, node.Children, 3)
+			errorNode := node.Children[1].EnsureOnlySingle()
+			require.Equal(t, ErrorNode, errorNode.Kind)
+			require.Equal(t, ErrorSymbol, errorNode.Atom.Symbol)
+			require.Equal(t, "1+*", errorNode.Atom.SelectString())
+			require.Equal(t, "3*4", node.Children[2].MustSelectBySymbol("Expr").Atom.SelectString())
+			require.Equal(t, []Diagnostic{{
+				Segment: definition.Segment{Start: 4, End: 7},
+				Error:   &ParseError{Offset: 6, Line: 1, Column: 7, Expected: []string{"Product"}, Found: `'*'`},
+			}}, diagnostics)
+		})
+		t.Run("unparsed tail", func(t *testing.T) {
+			node, diagnostics, err := ParseTextWithRecovery(statementRules, "Statements", []byte("1;2+"), WithStrategy(strategy))
+			require.Nil(t, err)
+			require.Equal(t, 4, node.Segment.Length())
+			require.Len(t, node.Children, 2)
+			require.Equal(t, ErrorNode, node.Children[1].Kind)
+			require.Equal(t, "2+", node.Children[1].Atom.SelectString())
+			require.Len(t, diagnostics, 1)
+			require.Equal(t, definition.Segment{Start: 2, End: 4}, diagnostics[0].Segment)
+			require.Equal(t, []string{`";"`}, diagnostics[0].Error.Expected)
+		})
+		t.Run("root failure", func(t *testing.T) {
+			node, diagnostics, err := ParseTextWithRecovery(arithmeticRules, "Expr", []byte("+1"), WithStrategy(strategy))
+			require.Nil(t, err)
+			require.Equal(t, "Expr", node.Atom.Symbol)
+			require.Equal(t, ErrorNode, node.EnsureOnlySingle().Kind)
+			require.Len(t, diagnostics, 1)
+			require.Equal(t, definition.Segment{Start: 0, End: 2}, diagnostics[0].Segment)
+		})
+		t.Run("without recovery", func(t *testing.T) {
+			node, err := ParseText(statementRules, "Statements", []byte("1+2;1+*;3*4;"), WithStrategy(strategy))
+			require.Nil(t, err)
+			require.Equal(t, 4, node.Segment.Length())
+		})
+	}
+}
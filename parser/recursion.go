@@ -0,0 +1,72 @@
+package parser
+
+import "fmt"
+
+type (
+	version struct {
+		seq  int
+		step step
+	}
+	stepStore interface {
+		stepAt(i, s int) step
+		store(i, s int, value step)
+	}
+	recursionHistory struct {
+		seq      int
+		versions map[cell][]version
+	}
+)
+
+func newRecursionHistory() recursionHistory {
+	return recursionHistory{versions: make(map[cell][]version)}
+}
+
+// grow evaluates group of mutually left-recursive rules at the same position starting from the failed seeds
+// and repeats evaluation until none of the rules can consume more input (Warth et al. seed-growing)
+func (h *recursionHistory) grow(i int, component []int, steps stepStore, evaluate func(s int) step) {
+	for _, s := range component {
+		steps.store(i, s, step{})
+		h.versions[cell{position: i, rule: s}] = []version{{seq: -1}}
+	}
+	for grown := true; grown; {
+		grown = false
+		for _, s := range component {
+			h.seq++
+			seq := h.seq
+			next := evaluate(s)
+			current := steps.stepAt(i, s)
+			// final value depends on everything examined by all the iterations including the last unsuccessful one
+			next.look = max(next.look, current.look)
+			if !next.ok || (current.ok && next.advance <= current.advance) {
+				current.look = next.look
+				steps.store(i, s, current)
+				continue
+			}
+			key := cell{position: i, rule: s}
+			steps.store(i, s, next)
+			h.versions[key] = append(h.versions[key], version{seq: seq, step: next})
+			grown = true
+		}
+	}
+	// members of the group depend on each other so they share the same look
+	look := 0
+	for _, s := range component {
+		look = max(look, steps.stepAt(i, s).look)
+	}
+	for _, s := range component {
+		current := steps.stepAt(i, s)
+		current.look = look
+		steps.store(i, s, current)
+	}
+}
+
+// versionBefore returns the value of the left-recursive cell which was visible for the evaluation with given sequence number
+func (h *recursionHistory) versionBefore(key cell, seq int) version {
+	versions := h.versions[key]
+	for k := len(versions) - 1; k >= 0; k-- {
+		if versions[k].seq < seq {
+			return versions[k]
+		}
+	}
+	panic(fmt.Errorf("left-recursive cell %v has no versions before %v", key, seq))
+}
@@ -0,0 +1,201 @@
+package parser
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"unicode/utf8"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+// serializedTexts deduplicates texts referenced by the nodes: all nodes of the tree usually share the same input
+type serializedTexts struct {
+	texts   [][]byte
+	indices map[string]int
+}
+
+// index returns position of the text in the table or -1 for nil text
+func (s *serializedTexts) index(text []byte) int {
+	if text == nil {
+		return -1
+	}
+	if s.indices == nil {
+		s.indices = make(map[string]int)
+	}
+	if index, ok := s.indices[string(text)]; ok {
+		return index
+	}
+	s.indices[string(text)] = len(s.texts)
+	s.texts = append(s.texts, text)
+	return len(s.texts) - 1
+}
+
+func sortedAttributeKeys(attributes map[string][]byte) []string {
+	keys := make([]string, 0, len(attributes))
+	for key := range attributes {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// newSerializedAtom restores the atom and checks that text selection stays within the text
+func newSerializedAtom(texts [][]byte, symbol string, attributes map[string][]byte, textIndex int, selector []definition.Segment) (definition.Atom, error) {
+	if textIndex < -1 || textIndex >= len(texts) {
+		return definition.Atom{}, fmt.Errorf("text index %v is out of range [-1..%v)", textIndex, len(texts))
+	}
+	var text []byte
+	if textIndex >= 0 {
+		text = texts[textIndex]
+	}
+	for _, segment := range selector {
+		if segment.Start < 0 || segment.Start > segment.End || segment.End > len(text) {
+			return definition.Atom{}, fmt.Errorf("text selector [%v..%v) is out of text bounds [0..%v)", segment.Start, segment.End, len(text))
+		}
+	}
+	return definition.Atom{
+		Symbol:       symbol,
+		Attributes:   attributes,
+		Text:         text,
+		TextSelector: definition.BuildSegments(selector...),
+	}, nil
+}
+
+// jsonBytes is encoded as JSON string when it is valid UTF-8 and as {"bytes": "<base64>"} object otherwise
+type jsonBytes []byte
+
+type jsonEscapedBytes struct {
+	Bytes []byte `json:"bytes"`
+}
+
+func (b jsonBytes) MarshalJSON() ([]byte, error) {
+	if utf8.Valid(b) {
+		return json.Marshal(string(b))
+	}
+	return json.Marshal(jsonEscapedBytes{Bytes: b})
+}
+
+func (b *jsonBytes) UnmarshalJSON(data []byte) error {
+	if bytes.HasPrefix(data, []byte("{")) {
+		var escaped jsonEscapedBytes
+		if err := json.Unmarshal(data, &escaped); err != nil {
+			return err
+		}
+		*b = escaped.Bytes
+		return nil
+	}
+	var text string
+	if err := json.Unmarshal(data, &text); err != nil {
+		return err
+	}
+	*b = jsonBytes(text)
+	return nil
+}
+
+type (
+	jsonTree struct {
+		Texts []jsonBytes `json:"texts"`
+		Root  *jsonNode   `json:"root"`
+	}
+	jsonNode struct {
+		Kind       NodeKind              `json:"kind,omitempty"`
+		Symbol     string                `json:"symbol"`
+		Attributes map[string]*jsonBytes `json:"attributes,omitempty"`
+		Segment    [2]int                `json:"segment"`
+		Text       int                   `json:"text"`
+		Selector   [][2]int              `json:"selector,omitempty"`
+		Children   []*jsonNode           `json:"children,omitempty"`
+	}
+)
+
+// EncodeJSON serializes the tree in the JSON format: texts are stored once in the "texts" table and nodes refer to them by index
+// (-1 stands for nil text). Texts and attributes which are not valid UTF-8 are stored as {"bytes": "<base64>"} objects.
+// Empty attributes and children are not distinguished from the missing ones in all formats
+func EncodeJSON(node *ParsingNode) ([]byte, error) {
+	var texts serializedTexts
+	root := encodeJSONNode(node, &texts)
+	tree := jsonTree{Texts: make([]jsonBytes, 0, len(texts.texts)), Root: root}
+	for _, text := range texts.texts {
+		tree.Texts = append(tree.Texts, text)
+	}
+	return json.Marshal(tree)
+}
+
+func encodeJSONNode(node *ParsingNode, texts *serializedTexts) *jsonNode {
+	encoded := &jsonNode{
+		Kind:    node.Kind,
+		Symbol:  node.Atom.Symbol,
+		Segment: [2]int{node.Segment.Start, node.Segment.End},
+		Text:    texts.index(node.Atom.Text),
+	}
+	for _, segment := range node.Atom.TextSelector.List() {
+		encoded.Selector = append(encoded.Selector, [2]int{segment.Start, segment.End})
+	}
+	for _, key := range sortedAttributeKeys(node.Atom.Attributes) {
+		if encoded.Attributes == nil {
+			encoded.Attributes = make(map[string]*jsonBytes)
+		}
+		value := node.Atom.Attributes[key]
+		if value == nil {
+			encoded.Attributes[key] = nil
+			continue
+		}
+		text := jsonBytes(value)
+		encoded.Attributes[key] = &text
+	}
+	for _, child := range node.Children {
+		encoded.Children = append(encoded.Children, encodeJSONNode(child, texts))
+	}
+	return encoded
+}
+
+func DecodeJSON(data []byte) (*ParsingNode, error) {
+	var tree jsonTree
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil, fmt.Errorf("unable to decode JSON tree: %w", err)
+	}
+	if tree.Root == nil {
+		return nil, fmt.Errorf("unable to decode JSON tree: root is missing")
+	}
+	texts := make([][]byte, 0, len(tree.Texts))
+	for _, text := range tree.Texts {
+		texts = append(texts, text)
+	}
+	return decodeJSONNode(tree.Root, texts)
+}
+
+func decodeJSONNode(encoded *jsonNode, texts [][]byte) (*ParsingNode, error) {
+	var attributes map[string][]byte
+	if len(encoded.Attributes) > 0 {
+		attributes = make(map[string][]byte, len(encoded.Attributes))
+	}
+	for key, value := range encoded.Attributes {
+		attributes[key] = nil
+		if value != nil {
+			attributes[key] = *value
+		}
+	}
+	selector := make([]definition.Segment, 0, len(encoded.Selector))
+	for _, segment := range encoded.Selector {
+		selector = append(selector, definition.Segment{Start: segment[0], End: segment[1]})
+	}
+	atom, err := newSerializedAtom(texts, encoded.Symbol, attributes, encoded.Text, selector)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode JSON node %v: %w", encoded.Symbol, err)
+	}
+	node := &ParsingNode{
+		Kind:    encoded.Kind,
+		Atom:    atom,
+		Segment: definition.Segment{Start: encoded.Segment[0], End: encoded.Segment[1]},
+	}
+	for _, child := range encoded.Children {
+		decoded, err := decodeJSONNode(child, texts)
+		if err != nil {
+			return nil, err
+		}
+		node.Children = append(node.Children, decoded)
+	}
+	return node, nil
+}
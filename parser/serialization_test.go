@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/stretchr/testify/require"
+)
+
+var serializations = []struct {
+	name   string
+	encode func(node *ParsingNode) ([]byte, error)
+	decode func(data []byte) (*ParsingNode, error)
+}{
+	{name: "json", encode: EncodeJSON, decode: DecodeJSON},
+	{name: "sexpr", encode: func(node *ParsingNode) ([]byte, error) { return EncodeSExpr(node), nil }, decode: DecodeSExpr},
+	{name: "binary", encode: func(node *ParsingNode) ([]byte, error) { return EncodeBinary(node), nil }, decode: DecodeBinary},
+}
+
+func serializationTrees(t *testing.T) map[string]*ParsingNode {
+	arithmetic, err := ParseText(arithmeticRules, "Expr", []byte("1+(2*4-5)-10*44"))
+	require.Nil(t, err)
+	recovered, _, err := ParseTextWithRecovery(statementRules, "Statements", []byte("1+2;1+*;3*4;"))
+	require.Nil(t, err)
+	attributed := &ParsingNode{
+		Atom: definition.Atom{
+			Symbol:       "A",
+			Attributes:   map[string][]byte{"Ctx": []byte("\xff\"quoted\"\n"), "Flag": nil, "Empty": {}},
+			Text:         []byte("0123456789"),
+			TextSelector: definition.BuildSegments(definition.Segment{Start: 1, End: 3}, definition.Segment{Start: 5, End: 9}),
+		},
+		Segment: definition.Segment{Start: 0, End: 2},
+		Children: []*ParsingNode{
+			{Atom: definition.Atom{Symbol: "B"}, Segment: definition.Segment{Start: 0, End: 0}},
+		},
+	}
+	invalid, err := ParseText(charactersRules, "Text", []byte("a\xc3\xffя\xd1"), WithUTF8())
+	require.Nil(t, err)
+	return map[string]*ParsingNode{"arithmetic": arithmetic, "recovered": recovered, "attributed": attributed, "invalid": invalid}
+}
+
+func TestSerializationRoundTrip(t *testing.T) {
+	for treeName, tree := range serializationTrees(t) {
+		for _, serialization := range serializations {
+			t.Run(treeName+"/"+serialization.name, func(t *testing.T) {
+				data, err := serialization.encode(tree)
+				require.Nil(t, err)
+				decoded, err := serialization.decode(data)
+				require.Nil(t, err)
+				require.Equal(t, tree, decoded)
+			})
+		}
+	}
+}
+
+func TestJSONInvalidUTF8(t *testing.T) {
+	node := &ParsingNode{Atom: definition.Atom{
+		Symbol:     "A",
+		Attributes: map[string][]byte{"Valid": []byte("я"), "Invalid": []byte("\xff")},
+		Text:       []byte("\xc3a"),
+	}}
+	data, err := EncodeJSON(node)
+	require.Nil(t, err)
+	require.JSONEq(t, `{
+		"texts": [{"bytes": "w2E="}],
+		"root": {"symbol": "A", "attributes": {"Invalid": {"bytes": "/w=="}, "Valid": "я"}, "segment": [0, 0], "text": 0}
+	}`, string(data))
+	decoded, err := DecodeJSON(data)
+	require.Nil(t, err)
+	require.Equal(t, node, decoded)
+}
+
+func TestSExprGolden(t *testing.T) {
+	node, err := ParseText(arithmeticRules, "Expr", []byte("1+2"))
+	require.Nil(t, err)
+	require.Equal(t, `(tree
+  (texts "1+2")
+  (node "Expr" (segment 0 3) (text 0) (selector (0 3))
+    (node "Sum" (segment 0 3) (text 0) (selector (0 3))
+      (node "Product" (segment 0 1) (text 0) (selector (0 1))
+        (node "Value" (segment 0 1) (text 0) (selector (0 1))
+          (node "Digit" (segment 0 1) (text 0) (selector (0 1)))))
+      (node "Product" (segment 2 3) (text 0) (selector (2 3))
+        (node "Value" (segment 2 3) (text 0) (selector (2 3))
+          (node "Digit" (segment 2 3) (text 0) (selector (2 3))))))))
+`, string(EncodeSExpr(node)))
+}
+
+func TestSerializationErrors(t *testing.T) {
+	node, err := ParseText(arithmeticRules, "Expr", []byte("1+2"))
+	require.Nil(t, err)
+	binary := EncodeBinary(node)
+	for i := 0; i < len(binary); i++ {
+		_, err := DecodeBinary(binary[:i])
+		require.NotNil(t, err)
+	}
+	_, err = DecodeBinary(append(binary, 0))
+	require.ErrorContains(t, err, "unexpected data")
+
+	_, err = DecodeSExpr([]byte(`(tree (texts "1") (node "A" (segment 0 1) (text 0) (selector (0 2))))`))
+	require.ErrorContains(t, err, "out of text bounds")
+	_, err = DecodeSExpr([]byte(`(tree (texts "1") (node "A" (segment 0 1) (text 1)))`))
+	require.ErrorContains(t, err, "text index 1 is out of range")
+	_, err = DecodeSExpr([]byte(`(tree (texts "1") (node "A" (segment 0 1))`))
+	require.ErrorContains(t, err, "unexpected end of input")
+
+	_, err = DecodeJSON([]byte(`{"texts": []}`))
+	require.ErrorContains(t, err, "root is missing")
+}
@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"fmt"
+
+	"github.com/sivukhin/gopeg/analysis"
+	"github.com/sivukhin/gopeg/definition"
+)
+
+// Session keeps memoized cells between parses of the changing text and after every edit reevaluates only the cells
+// which could observe the edited region. Session always evaluates grammar lazily because only memoized cells can be selectively invalidated
+type Session struct {
+	grammar *grammar
+	table   *lazyTable[byte]
+}
+
+type edit struct{ offset, removed, inserted int }
+
+func NewSession(rules definition.Rules, root string, text []byte, opts ...Option) (*Session, error) {
+	g, err := Compile(rules)
+	if err != nil {
+		return nil, err
+	}
+	return g.NewSession(text, withRoot(root, opts)...)
+}
+
+func (g *Grammar) NewSession(text []byte, opts ...Option) (*Session, error) {
+	if err := g.grammar.checkTerminalType(analysis.ByteTerminalType); err != nil {
+		return nil, err
+	}
+	options, err := g.parseOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+	if err := options.checkInputLength(len(text)); err != nil {
+		return nil, err
+	}
+	options.strategy = LazyStrategy
+	options.incremental = true
+	e := &evaluator[byte]{grammar: g.grammar, data: append([]byte(nil), text...), options: options}
+	return &Session{grammar: g.grammar, table: newLazyTable(e)}, nil
+}
+
+func (s *Session) Text() []byte { return s.table.data }
+
+func (s *Session) Parse() (*ParsingNode, error) { return parseWith(s.table.evaluator) }
+
+// Edit replaces removed bytes starting from offset with inserted bytes and parses the new text
+func (s *Session) Edit(offset, removed int, inserted []byte) (*ParsingNode, error) {
+	data := s.table.data
+	if offset < 0 || removed < 0 || offset+removed > len(data) {
+		return nil, fmt.Errorf("edit [%v..%v) is out of text bounds [0..%v)", offset, offset+removed, len(data))
+	}
+	if err := s.table.options.checkInputLength(len(data) - removed + len(inserted)); err != nil {
+		return nil, err
+	}
+	next := make([]byte, 0, len(data)-removed+len(inserted))
+	next = append(next, data[:offset]...)
+	next = append(next, inserted...)
+	next = append(next, data[offset+removed:]...)
+
+	e := &evaluator[byte]{grammar: s.grammar, data: next, options: s.table.options}
+	table := newLazyTable(e)
+	table.history.seq = s.table.history.seq
+	// cells memoized after the evaluation was aborted by the limit are invalid
+	if s.table.err == nil {
+		moved := s.relocateCells(edit{offset: offset, removed: removed, inserted: len(inserted)})
+		for from, to := range moved {
+			table.memo[to] = s.table.memo[from]
+			if versions, ok := s.table.history.versions[from]; ok {
+				table.history.versions[to] = versions
+			}
+		}
+	}
+	s.table = table
+	return s.Parse()
+}
+
+// relocateCells maps cells which can't observe the edit to their positions in the edited text
+func (s *Session) relocateCells(ed edit) map[cell]cell {
+	g := s.grammar
+	moved := make(map[cell]cell, len(s.table.memo))
+	// left-recursive rules at the same position are grown together so they must be invalidated together too
+	invalidGroups := make(map[cell]bool)
+	for key, value := range s.table.memo {
+		if target, ok := ed.relocate(key, value.look); ok {
+			moved[key] = target
+		} else if g.recursive[key.rule] {
+			invalidGroups[cell{position: key.position, rule: g.componentOf[key.rule]}] = true
+		}
+	}
+	for key := range moved {
+		if g.recursive[key.rule] && invalidGroups[cell{position: key.position, rule: g.componentOf[key.rule]}] {
+			delete(moved, key)
+		}
+	}
+	return moved
+}
+
+func (ed edit) relocate(c cell, look int) (cell, bool) {
+	if c.position+look <= ed.offset {
+		return c, true
+	}
+	// cells at the start of the text can observe it with @sof so they are never shifted
+	if c.position >= ed.offset+ed.removed && c.position > 0 {
+		return cell{position: c.position - ed.removed + ed.inserted, rule: c.rule}, true
+	}
+	return cell{}, false
+}
@@ -0,0 +1,132 @@
+package parser
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+func TestSession(t *testing.T) {
+	leftRecursiveRules := definition.Rules{
+		definition.NewRule("Sum", definition.NewChoice(
+			definition.NewJunction(definition.NewSymbol("Sum"), definition.NewTextPattern("[-+]"), definition.NewSymbol("Num")),
+			definition.NewSymbol("Num"),
+		)),
+		definition.NewRule("Num", definition.NewChoice(
+			definition.NewJunction(definition.StartOfFile{}, definition.NewTextPattern("[0-9]+$")),
+			definition.NewTextPattern("[0-9]+"),
+		)),
+	}
+	grammars := []struct {
+		name  string
+		rules definition.Rules
+		root  string
+		opts  []Option
+	}{
+		{name: "arithmetic", rules: arithmeticRules, root: "Expr"},
+		{name: "arithmetic full match", rules: arithmeticRules, root: "Expr", opts: []Option{WithMatchMode(FullMatch)}},
+		{name: "left recursion", rules: leftRecursiveRules, root: "Sum"},
+	}
+	for _, grammar := range grammars {
+		t.Run(grammar.name, func(t *testing.T) {
+			random := rand.New(rand.NewSource(1))
+			alphabet := []byte("0123456789+-*/()")
+			randomText := func(n int) []byte {
+				text := make([]byte, n)
+				for i := range text {
+					text[i] = alphabet[random.Intn(len(alphabet))]
+				}
+				return text
+			}
+			session, err := NewSession(grammar.rules, grammar.root, []byte("1+(2*3)-45"), grammar.opts...)
+			require.Nil(t, err)
+			_, err = session.Parse()
+			require.Nil(t, err)
+			for attempt := 0; attempt < 500; attempt++ {
+				text := session.Text()
+				offset := random.Intn(len(text) + 1)
+				removed := random.Intn(min(3, len(text)-offset) + 1)
+				inserted := randomText(random.Intn(3))
+				actual, actualErr := session.Edit(offset, removed, inserted)
+				expected, expectedErr := ParseText(grammar.rules, grammar.root, session.Text(), grammar.opts...)
+				require.Equal(t, expectedErr, actualErr, "text: %q", session.Text())
+				if expectedErr == nil {
+					require.Equal(t, StringParsingNode(expected), StringParsingNode(actual), "text: %q", session.Text())
+				}
+			}
+		})
+	}
+	t.Run("reuse cells", func(t *testing.T) {
+		session, err := NewSession(arithmeticRules, "Expr", []byte("1+2*3+4*5+6*7"))
+		require.Nil(t, err)
+		_, err = session.Parse()
+		require.Nil(t, err)
+		moved := session.relocateCells(edit{offset: 4, removed: 1, inserted: 2})
+		require.NotEmpty(t, moved)
+		for from, to := range moved {
+			if from.position >= 5 {
+				require.Equal(t, from.position+1, to.position)
+			} else {
+				require.Equal(t, from, to)
+			}
+		}
+		node, err := session.Edit(4, 1, []byte("10"))
+		require.Nil(t, err)
+		require.Equal(t, "1+2*10+4*5+6*7", node.Atom.SelectString())
+	})
+	t.Run("utf-8 edits inside characters", func(t *testing.T) {
+		rules := definition.Rules{
+			definition.NewRule("Line", definition.NewJunction(
+				definition.NewRepetition(definition.NewChoice(
+					definition.NewSymbol("Folded"),
+					definition.NewSymbol("Class"),
+					definition.NewSymbol("Character"),
+					definition.NewSymbol("Invalid"),
+				)),
+				definition.NewTextToken("\n"),
+			)),
+			definition.NewRule("Folded", definition.NewFoldedToken("é")),
+			definition.NewRule("Class", definition.NewCharClass("[-0-9]")),
+			definition.NewRule("Character", definition.NewJunction(definition.NewNegation(definition.NewTextToken("\n")), definition.NewDot())),
+			definition.NewRule("Invalid", definition.InvalidUTF8{}),
+		}
+		g, err := Compile(rules, WithUTF8())
+		require.Nil(t, err)
+		session, err := g.NewSession([]byte("\xc3a1\n"))
+		require.Nil(t, err)
+		_, err = session.Parse()
+		require.Nil(t, err)
+		node, err := session.Edit(1, 2, []byte("\xa9-"))
+		require.Nil(t, err)
+		require.Equal(t, "Line[0..4): 'é-\n'\n"+
+			"  Folded[0..2): 'é'\n"+
+			"  Class[2..3): '-'\n", StringParsingNode(node))
+
+		random := rand.New(rand.NewSource(1))
+		pieces := []string{"\xc3", "\xa9", "\xc9", "\x89", "\xe2", "\x82", "a", "1", "-"}
+		for attempt := 0; attempt < 500; attempt++ {
+			text := session.Text()
+			offset := random.Intn(len(text))
+			removed := random.Intn(min(3, len(text)-1-offset) + 1)
+			var inserted []byte
+			for i := random.Intn(3); i > 0; i-- {
+				inserted = append(inserted, pieces[random.Intn(len(pieces))]...)
+			}
+			actual, actualErr := session.Edit(offset, removed, inserted)
+			expected, expectedErr := g.ParseText(session.Text())
+			require.Equal(t, expectedErr, actualErr, "text: %q", session.Text())
+			if expectedErr == nil {
+				require.Equal(t, StringParsingNode(expected), StringParsingNode(actual), "text: %q", session.Text())
+			}
+		}
+	})
+	t.Run("out of bounds", func(t *testing.T) {
+		session, err := NewSession(arithmeticRules, "Expr", []byte("1+2"))
+		require.Nil(t, err)
+		_, err = session.Edit(2, 2, nil)
+		require.NotNil(t, err)
+	})
+}
@@ -0,0 +1,249 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+// EncodeSExpr serializes the tree as the S-expression with one node per line which is convenient for golden tests:
+//
+//	(tree
+//	  (texts "1+2")
+//	  (node "Sum" (segment 0 3) (text 0) (selector (0 3))
+//	    (node "Value" (segment 0 1) (text 0) (selector (0 1)) (attributes ("Ctx" "B") ("Flag")))))
+//
+// Strings are quoted with Go escaping rules, so arbitrary bytes are preserved
+func EncodeSExpr(node *ParsingNode) []byte {
+	var texts serializedTexts
+	var nodes strings.Builder
+	encodeSExprNode(&nodes, node, &texts, 1)
+	var tree strings.Builder
+	tree.WriteString("(tree\n  (texts")
+	for _, text := range texts.texts {
+		tree.WriteString(" " + strconv.Quote(string(text)))
+	}
+	tree.WriteString(")\n")
+	tree.WriteString(nodes.String())
+	tree.WriteString(")\n")
+	return []byte(tree.String())
+}
+
+func encodeSExprNode(builder *strings.Builder, node *ParsingNode, texts *serializedTexts, depth int) {
+	builder.WriteString(strings.Repeat("  ", depth))
+	fmt.Fprintf(builder, "(node %v (segment %v %v)", strconv.Quote(node.Atom.Symbol), node.Segment.Start, node.Segment.End)
+	if node.Kind != NamedNode {
+		fmt.Fprintf(builder, " (kind %v)", int(node.Kind))
+	}
+	fmt.Fprintf(builder, " (text %v)", texts.index(node.Atom.Text))
+	if segments := node.Atom.TextSelector.List(); len(segments) > 0 {
+		builder.WriteString(" (selector")
+		for _, segment := range segments {
+			fmt.Fprintf(builder, " (%v %v)", segment.Start, segment.End)
+		}
+		builder.WriteString(")")
+	}
+	if len(node.Atom.Attributes) > 0 {
+		builder.WriteString(" (attributes")
+		for _, key := range sortedAttributeKeys(node.Atom.Attributes) {
+			builder.WriteString(" (" + strconv.Quote(key))
+			if value := node.Atom.Attributes[key]; value != nil {
+				builder.WriteString(" " + strconv.Quote(string(value)))
+			}
+			builder.WriteString(")")
+		}
+		builder.WriteString(")")
+	}
+	for _, child := range node.Children {
+		builder.WriteString("\n")
+		encodeSExprNode(builder, child, texts, depth+1)
+	}
+	builder.WriteString(")")
+}
+
+// sexpr is either an atom (number or bare word), a quoted string or a list
+type sexpr struct {
+	atom   string
+	quoted bool
+	list   []sexpr
+	isList bool
+	offset int
+}
+
+type sexprReader struct {
+	data   string
+	offset int
+}
+
+func (r *sexprReader) skipSpaces() {
+	for r.offset < len(r.data) && strings.IndexByte(" \t\r\n", r.data[r.offset]) >= 0 {
+		r.offset++
+	}
+}
+
+func (r *sexprReader) read() (sexpr, error) {
+	r.skipSpaces()
+	if r.offset >= len(r.data) {
+		return sexpr{}, fmt.Errorf("unexpected end of input at offset %v", r.offset)
+	}
+	start := r.offset
+	switch r.data[r.offset] {
+	case '(':
+		r.offset++
+		list := sexpr{isList: true, list: make([]sexpr, 0), offset: start}
+		for {
+			r.skipSpaces()
+			if r.offset < len(r.data) && r.data[r.offset] == ')' {
+				r.offset++
+				return list, nil
+			}
+			item, err := r.read()
+			if err != nil {
+				return sexpr{}, err
+			}
+			list.list = append(list.list, item)
+		}
+	case ')':
+		return sexpr{}, fmt.Errorf("unexpected ')' at offset %v", r.offset)
+	case '"':
+		quoted, err := strconv.QuotedPrefix(r.data[r.offset:])
+		if err != nil {
+			return sexpr{}, fmt.Errorf("invalid string at offset %v: %w", r.offset, err)
+		}
+		r.offset += len(quoted)
+		value, _ := strconv.Unquote(quoted)
+		return sexpr{atom: value, quoted: true, offset: start}, nil
+	default:
+		for r.offset < len(r.data) && strings.IndexByte(" \t\r\n()\"", r.data[r.offset]) < 0 {
+			r.offset++
+		}
+		return sexpr{atom: r.data[start:r.offset], offset: start}, nil
+	}
+}
+
+func (e sexpr) form(head string) ([]sexpr, bool) {
+	if !e.isList || len(e.list) == 0 || e.list[0].isList || e.list[0].quoted || e.list[0].atom != head {
+		return nil, false
+	}
+	return e.list[1:], true
+}
+
+func (e sexpr) integers(count int) ([]int, error) {
+	if !e.isList || len(e.list) != count {
+		return nil, fmt.Errorf("expected list of %v integers at offset %v", count, e.offset)
+	}
+	values := make([]int, 0, count)
+	for _, item := range e.list {
+		if item.isList || item.quoted {
+			return nil, fmt.Errorf("expected integer at offset %v", item.offset)
+		}
+		value, err := strconv.Atoi(item.atom)
+		if err != nil {
+			return nil, fmt.Errorf("expected integer at offset %v: %w", item.offset, err)
+		}
+		values = append(values, value)
+	}
+	return values, nil
+}
+
+func DecodeSExpr(data []byte) (*ParsingNode, error) {
+	reader := &sexprReader{data: string(data)}
+	tree, err := reader.read()
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode S-expression tree: %w", err)
+	}
+	if reader.skipSpaces(); reader.offset != len(reader.data) {
+		return nil, fmt.Errorf("unable to decode S-expression tree: unexpected data at offset %v", reader.offset)
+	}
+	items, ok := tree.form("tree")
+	if !ok || len(items) != 2 {
+		return nil, fmt.Errorf("unable to decode S-expression tree: expected (tree (texts ...) (node ...))")
+	}
+	textItems, ok := items[0].form("texts")
+	if !ok {
+		return nil, fmt.Errorf("unable to decode S-expression tree: expected (texts ...) at offset %v", items[0].offset)
+	}
+	texts := make([][]byte, 0, len(textItems))
+	for _, item := range textItems {
+		if !item.quoted {
+			return nil, fmt.Errorf("unable to decode S-expression tree: expected string at offset %v", item.offset)
+		}
+		texts = append(texts, []byte(item.atom))
+	}
+	node, err := decodeSExprNode(items[1], texts)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode S-expression tree: %w", err)
+	}
+	return node, nil
+}
+
+func decodeSExprNode(e sexpr, texts [][]byte) (*ParsingNode, error) {
+	items, ok := e.form("node")
+	if !ok || len(items) < 2 || !items[0].quoted {
+		return nil, fmt.Errorf("expected (node \"Symbol\" (segment ...) ...) at offset %v", e.offset)
+	}
+	node := &ParsingNode{}
+	symbol := items[0].atom
+	var attributes map[string][]byte
+	var selector []definition.Segment
+	textIndex := -1
+	for _, item := range items[1:] {
+		if arguments, ok := item.form("segment"); ok {
+			values, err := sexpr{isList: true, list: arguments, offset: item.offset}.integers(2)
+			if err != nil {
+				return nil, err
+			}
+			node.Segment = definition.Segment{Start: values[0], End: values[1]}
+		} else if arguments, ok := item.form("kind"); ok {
+			values, err := sexpr{isList: true, list: arguments, offset: item.offset}.integers(1)
+			if err != nil {
+				return nil, err
+			}
+			node.Kind = NodeKind(values[0])
+		} else if arguments, ok := item.form("text"); ok {
+			values, err := sexpr{isList: true, list: arguments, offset: item.offset}.integers(1)
+			if err != nil {
+				return nil, err
+			}
+			textIndex = values[0]
+		} else if arguments, ok := item.form("selector"); ok {
+			for _, argument := range arguments {
+				values, err := argument.integers(2)
+				if err != nil {
+					return nil, err
+				}
+				selector = append(selector, definition.Segment{Start: values[0], End: values[1]})
+			}
+		} else if arguments, ok := item.form("attributes"); ok {
+			attributes = make(map[string][]byte, len(arguments))
+			for _, argument := range arguments {
+				if !argument.isList || len(argument.list) < 1 || len(argument.list) > 2 || !argument.list[0].quoted {
+					return nil, fmt.Errorf("expected (\"key\" \"value\") or (\"key\") at offset %v", argument.offset)
+				}
+				attributes[argument.list[0].atom] = nil
+				if len(argument.list) == 2 {
+					if !argument.list[1].quoted {
+						return nil, fmt.Errorf("expected string at offset %v", argument.list[1].offset)
+					}
+					attributes[argument.list[0].atom] = []byte(argument.list[1].atom)
+				}
+			}
+		} else if _, ok := item.form("node"); ok {
+			child, err := decodeSExprNode(item, texts)
+			if err != nil {
+				return nil, err
+			}
+			node.Children = append(node.Children, child)
+		} else {
+			return nil, fmt.Errorf("unexpected form at offset %v", item.offset)
+		}
+	}
+	atom, err := newSerializedAtom(texts, symbol, attributes, textIndex, selector)
+	if err != nil {
+		return nil, fmt.Errorf("invalid node %v at offset %v: %w", symbol, e.offset, err)
+	}
+	node.Atom = atom
+	return node, nil
+}
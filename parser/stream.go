@@ -0,0 +1,150 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/sivukhin/gopeg/analysis"
+	"github.com/sivukhin/gopeg/definition"
+)
+
+const streamChunkSize = 64 * 1024
+
+// recordStream holds only the unparsed tail of the input: buffer is never modified in place
+// because emitted nodes keep references to it
+type recordStream struct {
+	reader io.Reader
+	buffer []byte
+	eof    bool
+	offset int
+	line   int
+	column int
+}
+
+func (s *recordStream) fill() error {
+	size := max(streamChunkSize, len(s.buffer))
+	next := make([]byte, len(s.buffer), len(s.buffer)+size)
+	copy(next, s.buffer)
+	n, err := io.ReadFull(s.reader, next[len(next):cap(next)])
+	s.buffer = next[:len(next)+n]
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		s.eof = true
+		return nil
+	}
+	return err
+}
+
+func (s *recordStream) consume(n int) {
+	consumed := s.buffer[:n]
+	if lines := bytes.Count(consumed, []byte("\n")); lines > 0 {
+		s.line += lines
+		s.column = n - (bytes.LastIndexByte(consumed, '\n') + 1)
+	} else {
+		s.column += n
+	}
+	s.offset += n
+	s.buffer = s.buffer[n:]
+}
+
+func (s *recordStream) shift(node *ParsingNode) {
+	node.Segment = definition.Segment{Start: node.Segment.Start + s.offset, End: node.Segment.End + s.offset}
+	for _, child := range node.Children {
+		s.shift(child)
+	}
+}
+
+func (s *recordStream) locate(err *ParseError) *ParseError {
+	if err.Line == 1 {
+		err.Column += s.column
+	}
+	err.Line += s.line
+	err.Offset += s.offset
+	return err
+}
+
+// ParseReader parses input with the grammar which root is a repetition of independent records (like `Text: (Line EndOfLine)*`)
+// and emits top-level nodes of every record as soon as it was parsed. Only the record which is currently parsed is kept in memory,
+// so record is completed only when its evaluation haven't examined bytes which weren't read yet.
+// Segments of emitted nodes are absolute offsets in the stream
+func ParseReader(rules definition.Rules, root string, reader io.Reader, emit func(node *ParsingNode) error, opts ...Option) error {
+	g, err := Compile(rules)
+	if err != nil {
+		return err
+	}
+	return g.ParseReader(reader, emit, withRoot(root, opts)...)
+}
+
+// ParseReader parses records from the reader one by one, see ParseReader function for details.
+// Input length and other limits are applied to the single record
+func (g *Grammar) ParseReader(reader io.Reader, emit func(node *ParsingNode) error, opts ...Option) error {
+	prepared := g.grammar
+	if err := prepared.checkTerminalType(analysis.ByteTerminalType); err != nil {
+		return err
+	}
+	options, err := g.parseOptions(opts)
+	if err != nil {
+		return err
+	}
+	options.strategy = LazyStrategy
+	options.incremental = true
+	rootRule, err := prepared.rootRule(options.root)
+	if err != nil {
+		return err
+	}
+	record, ok := prepared.exprs[rootRule].(definition.Kleene)
+	if !ok {
+		return fmt.Errorf("root rule %v must be a repetition of records in order to be parsed from reader", options.root)
+	}
+	stream := &recordStream{reader: reader}
+	for {
+		e := &evaluator[byte]{grammar: prepared, data: stream.buffer, options: options}
+		newLazyTable(e)
+		next := e.advance(0, record.Expr)
+		if e.err != nil {
+			return e.err
+		}
+		if next.look > len(stream.buffer) && !stream.eof {
+			if err := options.checkInputLength(len(stream.buffer) + 1); err != nil {
+				return fmt.Errorf("record at offset %v is too long: %w", stream.offset, err)
+			}
+			if err := stream.fill(); err != nil {
+				return fmt.Errorf("unable to read input: %w", err)
+			}
+			continue
+		}
+		if !next.ok || next.advance == 0 {
+			if options.matchMode == FullMatch && len(stream.buffer) > 0 {
+				_, f := newFailureCollector(e).visit(0, record.Expr)
+				f = f.merge(newFailure(0, definition.EndOfFileBuiltinSymbol))
+				return stream.locate(buildParseError(e, f, 0, record.Expr))
+			}
+			return nil
+		}
+		if symbol, ok := record.Expr.(definition.Symbol); ok {
+			s := prepared.position[symbol.Name]
+			nodes, _, err := buildTree(e, s, next)
+			if err != nil {
+				return fmt.Errorf("record at offset %v is too large: %w", stream.offset, err)
+			}
+			for _, node := range nodes {
+				if err := options.checkTree(node); err != nil {
+					return fmt.Errorf("record at offset %v is too large: %w", stream.offset, err)
+				}
+				stream.shift(node)
+				if err := emit(node); err != nil {
+					return err
+				}
+			}
+		} else if terminal, ok := record.Expr.(definition.Terminals); ok && options.concrete {
+			node := NewParsingNode(terminal.String(), nil, stream.buffer, definition.Segment{Start: 0, End: next.advance})
+			node.Kind = AnonymousNode
+			stream.shift(&node)
+			if err := emit(&node); err != nil {
+				return err
+			}
+		}
+		stream.consume(next.advance)
+	}
+}
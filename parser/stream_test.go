@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+func TestParseReader(t *testing.T) {
+	rules := definition.Rules{
+		definition.NewRule("Text", definition.NewRepetition(definition.NewJunction(
+			definition.NewSymbol("#Record"),
+			definition.NewTextToken("\n"),
+		))),
+		definition.NewRule("#Record", definition.NewJunction(
+			definition.NewSymbol("Key"),
+			definition.NewTextToken("="),
+			definition.NewSymbol("Value"),
+		)),
+		definition.NewRule("Key", definition.NewTextPattern("[a-z]+")),
+		definition.NewRule("Value", definition.NewTextPattern("[^\n]*")),
+	}
+	var builder strings.Builder
+	for i := 0; i < 10000; i++ {
+		builder.WriteString(fmt.Sprintf("key=%v\n", strings.Repeat("v", i%100)))
+	}
+	text := []byte(builder.String())
+	t.Run("same as in memory", func(t *testing.T) {
+		expected, err := ParseText(rules, "Text", text, WithStrategy(LazyStrategy))
+		require.Nil(t, err)
+		actual := make([]string, 0)
+		err = ParseReader(rules, "Text", iotest.HalfReader(bytes.NewReader(text)), func(node *ParsingNode) error {
+			actual = append(actual, StringParsingNode(node))
+			return nil
+		})
+		require.Nil(t, err)
+		require.Len(t, actual, len(expected.Children))
+		for i, child := range expected.Children {
+			require.Equal(t, StringParsingNode(child), actual[i])
+		}
+	})
+	t.Run("stop on callback error", func(t *testing.T) {
+		stop := errors.New("stop")
+		count := 0
+		err := ParseReader(rules, "Text", bytes.NewReader(text), func(node *ParsingNode) error {
+			count++
+			if count == 3 {
+				return stop
+			}
+			return nil
+		})
+		require.ErrorIs(t, err, stop)
+		require.Equal(t, 3, count)
+	})
+	t.Run("full match", func(t *testing.T) {
+		err := ParseReader(rules, "Text", strings.NewReader("a=1\nb=2\n=3\n"), func(node *ParsingNode) error { return nil }, WithMatchMode(FullMatch))
+		var parseErr *ParseError
+		require.True(t, errors.As(err, &parseErr))
+		require.Equal(t, &ParseError{Offset: 8, Line: 3, Column: 1, Expected: []string{"@eof", "Key"}, Found: `'='`}, parseErr)
+	})
+	t.Run("root must be repetition", func(t *testing.T) {
+		err := ParseReader(arithmeticRules, "Expr", strings.NewReader("1+2"), func(node *ParsingNode) error { return nil })
+		require.NotNil(t, err)
+	})
+}
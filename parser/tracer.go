@@ -0,0 +1,84 @@
+package parser
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+type (
+	// Tracer observes evaluation of the rules: rule is reported as the name of the original rule
+	// or as the original expression for the auxiliary rules introduced by the normalization.
+	// Every Enter is followed by the Match or Fail of the same rule at the same position after evaluation of the nested rules
+	Tracer interface {
+		Enter(rule string, position int)
+		Match(rule string, position, advance int)
+		Fail(rule string, position int)
+	}
+	TraceEventKind int
+	TraceEvent     struct {
+		Kind     TraceEventKind
+		Rule     string
+		Position int
+		Advance  int
+	}
+	// RecordingTracer keeps all observed events in memory
+	RecordingTracer struct{ Events []TraceEvent }
+	printingTracer  struct {
+		writer io.Writer
+		depth  int
+	}
+)
+
+const (
+	EnterEvent TraceEventKind = 0
+	MatchEvent TraceEventKind = 1
+	FailEvent  TraceEventKind = 2
+)
+
+func (k TraceEventKind) String() string {
+	switch k {
+	case EnterEvent:
+		return "Enter"
+	case MatchEvent:
+		return "Match"
+	case FailEvent:
+		return "Fail"
+	default:
+		panic(fmt.Errorf("unexpected trace event kind: %v", int(k)))
+	}
+}
+
+func (r *RecordingTracer) Enter(rule string, position int) {
+	r.Events = append(r.Events, TraceEvent{Kind: EnterEvent, Rule: rule, Position: position})
+}
+
+func (r *RecordingTracer) Match(rule string, position, advance int) {
+	r.Events = append(r.Events, TraceEvent{Kind: MatchEvent, Rule: rule, Position: position, Advance: advance})
+}
+
+func (r *RecordingTracer) Fail(rule string, position int) {
+	r.Events = append(r.Events, TraceEvent{Kind: FailEvent, Rule: rule, Position: position})
+}
+
+// NewPrintingTracer writes every event on the separate line indented according to the nesting of the rules evaluation
+func NewPrintingTracer(writer io.Writer) Tracer { return &printingTracer{writer: writer} }
+
+func (p *printingTracer) Enter(rule string, position int) {
+	p.printf("%v at %v: enter\n", rule, position)
+	p.depth++
+}
+
+func (p *printingTracer) Match(rule string, position, advance int) {
+	p.depth--
+	p.printf("%v at %v: matched %v\n", rule, position, advance)
+}
+
+func (p *printingTracer) Fail(rule string, position int) {
+	p.depth--
+	p.printf("%v at %v: failed\n", rule, position)
+}
+
+func (p *printingTracer) printf(format string, args ...any) {
+	_, _ = fmt.Fprintf(p.writer, strings.Repeat("  ", p.depth)+format, args...)
+}
@@ -0,0 +1,63 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+func requireNestedEvents(t *testing.T, events []TraceEvent) {
+	stack := make([]TraceEvent, 0)
+	for _, event := range events {
+		require.NotContains(t, event.Rule, "#")
+		if event.Kind == EnterEvent {
+			stack = append(stack, event)
+			continue
+		}
+		require.NotEmpty(t, stack)
+		enter := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		require.Equal(t, enter.Rule, event.Rule)
+		require.Equal(t, enter.Position, event.Position)
+	}
+	require.Empty(t, stack)
+}
+
+func TestTracer(t *testing.T) {
+	t.Run("recording", func(t *testing.T) {
+		for _, strategy := range []Strategy{TableStrategy, LazyStrategy, VMStrategy} {
+			var tracer RecordingTracer
+			_, err := ParseText(arithmeticRules, "Expr", []byte("1+2"), WithStrategy(strategy), WithTracer(&tracer))
+			require.Nil(t, err)
+			requireNestedEvents(t, tracer.Events)
+			require.Contains(t, tracer.Events, TraceEvent{Kind: MatchEvent, Rule: "Digit", Position: 0, Advance: 1})
+			require.Contains(t, tracer.Events, TraceEvent{Kind: FailEvent, Rule: "Digit", Position: 1})
+			require.Contains(t, tracer.Events, TraceEvent{Kind: MatchEvent, Rule: "Expr", Position: 0, Advance: 3})
+			require.Contains(t, tracer.Events, TraceEvent{Kind: MatchEvent, Rule: `("+" / "-") Product`, Position: 1, Advance: 2})
+		}
+	})
+	t.Run("left recursion", func(t *testing.T) {
+		rs := definition.Rules{
+			definition.NewRule("Call", definition.NewJunction(definition.NewSymbol("Expr"), definition.NewTextToken("()"))),
+			definition.NewRule("Expr", definition.NewChoice(definition.NewSymbol("Call"), definition.NewTextPattern("[a-z]+"))),
+		}
+		for _, strategy := range []Strategy{TableStrategy, LazyStrategy} {
+			var tracer RecordingTracer
+			_, err := ParseText(rs, "Expr", []byte("f()()"), WithStrategy(strategy), WithTracer(&tracer))
+			require.Nil(t, err)
+			requireNestedEvents(t, tracer.Events)
+			require.Contains(t, tracer.Events, TraceEvent{Kind: MatchEvent, Rule: "Expr", Position: 0, Advance: 5})
+			require.Contains(t, tracer.Events, TraceEvent{Kind: MatchEvent, Rule: "Call", Position: 0, Advance: 5})
+		}
+	})
+	t.Run("printing", func(t *testing.T) {
+		var trace strings.Builder
+		_, err := ParseText(arithmeticRules, "Expr", []byte("1"), WithStrategy(LazyStrategy), WithTrace(&trace))
+		require.Nil(t, err)
+		require.True(t, strings.HasPrefix(trace.String(), "Expr at 0: enter\n  Sum at 0: enter\n    Product at 0: enter\n"))
+		require.True(t, strings.HasSuffix(trace.String(), "  Sum at 0: matched 1\nExpr at 0: matched 1\n"))
+	})
+}
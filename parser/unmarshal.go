@@ -0,0 +1,205 @@
+package parser
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+const (
+	// TextTag fills the field with the text of the node itself
+	TextTag = "@text"
+	// SegmentTag fills definition.Segment field with the segment of the node itself
+	SegmentTag = "@segment"
+	// AttributeTagPrefix fills the field with the attribute of the node itself: `peg:"@attr:key"`
+	AttributeTagPrefix = "@attr:"
+)
+
+type UnmarshalError struct {
+	Symbol  string
+	Segment definition.Segment
+	Field   string
+	Err     error
+}
+
+func (e *UnmarshalError) Error() string {
+	return fmt.Sprintf(
+		"unable to unmarshal %v[%v..%v) into field %v: %v",
+		e.Symbol, e.Segment.Start, e.Segment.End, e.Field, e.Err,
+	)
+}
+
+func (e *UnmarshalError) Unwrap() error { return e.Err }
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	parsingNodeType     = reflect.TypeOf((*ParsingNode)(nil))
+	segmentType         = reflect.TypeOf(definition.Segment{})
+)
+
+// Unmarshal fills the struct pointed by v from the node with the help of `peg` field tags:
+//   - `peg:"Name"` selects children with symbol Name: slice field receives all of them, pointer field is left nil if there is no such child,
+//     any other field requires exactly one child
+//   - `peg:"@text"`, `peg:"@segment"` and `peg:"@attr:key"` select the text, the segment and the attribute of the node itself
+//
+// Selected node is converted to the field type: nested structs are unmarshalled recursively, *ParsingNode receives the node as is,
+// encoding.TextUnmarshaler, strings, byte slices, booleans and numbers are decoded from the node text
+func Unmarshal(node *ParsingNode, v any) error {
+	value := reflect.ValueOf(v)
+	if value.Kind() != reflect.Pointer || value.IsNil() || value.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("unmarshal target must be a non-nil pointer to struct, given %T", v)
+	}
+	return unmarshalStruct(node, value.Elem(), "")
+}
+
+// unmarshalStruct names fields after the struct type or after the path of fields leading to the anonymous struct
+func unmarshalStruct(node *ParsingNode, value reflect.Value, path string) error {
+	structType := value.Type()
+	if structType.Name() != "" {
+		path = structType.Name()
+	}
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag, ok := field.Tag.Lookup("peg")
+		if !ok || tag == "-" {
+			continue
+		}
+		name := field.Name
+		if path != "" {
+			name = path + "." + field.Name
+		}
+		if !field.IsExported() {
+			return &UnmarshalError{Symbol: node.Atom.Symbol, Segment: node.Segment, Field: name, Err: fmt.Errorf("tagged field must be exported")}
+		}
+		if err := unmarshalField(node, value.Field(i), name, tag); err != nil {
+			var unmarshalErr *UnmarshalError
+			if errors.As(err, &unmarshalErr) {
+				return err
+			}
+			return &UnmarshalError{Symbol: node.Atom.Symbol, Segment: node.Segment, Field: name, Err: err}
+		}
+	}
+	return nil
+}
+
+func unmarshalField(node *ParsingNode, field reflect.Value, name, tag string) error {
+	switch {
+	case tag == TextTag:
+		return unmarshalText(node.Atom.SelectText(), field)
+	case tag == SegmentTag:
+		if field.Type() != segmentType {
+			return fmt.Errorf("segment can't be stored in field of type %v", field.Type())
+		}
+		field.Set(reflect.ValueOf(node.Segment))
+		return nil
+	case strings.HasPrefix(tag, AttributeTagPrefix):
+		key := strings.TrimPrefix(tag, AttributeTagPrefix)
+		attribute, ok := node.Atom.Attributes[key]
+		if !ok {
+			if field.Kind() == reflect.Pointer {
+				return nil
+			}
+			return fmt.Errorf("attribute '%v' is missing", key)
+		}
+		return unmarshalText(attribute, field)
+	}
+	children := node.FilterBySymbol(tag)
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 {
+		slice := reflect.MakeSlice(field.Type(), len(children), len(children))
+		for i, child := range children {
+			if err := unmarshalNode(child, slice.Index(i), name); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+		return nil
+	}
+	if len(children) > 1 {
+		return fmt.Errorf("unexpected amount of symbols '%v': %v > 1", tag, len(children))
+	}
+	if len(children) == 0 {
+		if field.Kind() == reflect.Pointer {
+			return nil
+		}
+		return fmt.Errorf("symbol '%v' not found", tag)
+	}
+	return unmarshalNode(children[0], field, name)
+}
+
+func unmarshalNode(node *ParsingNode, field reflect.Value, name string) error {
+	if field.Type() == parsingNodeType {
+		field.Set(reflect.ValueOf(node))
+		return nil
+	}
+	if field.Kind() == reflect.Pointer && !field.Type().Implements(textUnmarshalerType) {
+		target := reflect.New(field.Type().Elem())
+		if err := unmarshalNode(node, target.Elem(), name); err != nil {
+			return err
+		}
+		field.Set(target)
+		return nil
+	}
+	if field.Kind() == reflect.Struct && !reflect.PointerTo(field.Type()).Implements(textUnmarshalerType) {
+		return unmarshalStruct(node, field, name)
+	}
+	if err := unmarshalText(node.Atom.SelectText(), field); err != nil {
+		return &UnmarshalError{Symbol: node.Atom.Symbol, Segment: node.Segment, Field: name, Err: err}
+	}
+	return nil
+}
+
+func unmarshalText(text []byte, field reflect.Value) error {
+	if field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		if unmarshaler, ok := field.Interface().(encoding.TextUnmarshaler); ok {
+			return unmarshaler.UnmarshalText(text)
+		}
+		return unmarshalText(text, field.Elem())
+	}
+	if unmarshaler, ok := field.Addr().Interface().(encoding.TextUnmarshaler); ok {
+		return unmarshaler.UnmarshalText(text)
+	}
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(string(text))
+	case reflect.Slice:
+		if field.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Errorf("text can't be stored in field of type %v", field.Type())
+		}
+		field.SetBytes(append([]byte(nil), text...))
+	case reflect.Bool:
+		parsed, err := strconv.ParseBool(string(text))
+		if err != nil {
+			return err
+		}
+		field.SetBool(parsed)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		parsed, err := strconv.ParseInt(string(text), 0, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetInt(parsed)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		parsed, err := strconv.ParseUint(string(text), 0, field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetUint(parsed)
+	case reflect.Float32, reflect.Float64:
+		parsed, err := strconv.ParseFloat(string(text), field.Type().Bits())
+		if err != nil {
+			return err
+		}
+		field.SetFloat(parsed)
+	default:
+		return fmt.Errorf("text can't be stored in field of type %v", field.Type())
+	}
+	return nil
+}
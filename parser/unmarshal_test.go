@@ -0,0 +1,109 @@
+package parser
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/stretchr/testify/require"
+)
+
+var configRules = definition.Rules{
+	definition.NewRule("Config", definition.NewRepetition(definition.NewJunction(definition.NewSymbol("Entry"), definition.NewTextToken(";")))),
+	definition.NewRule("Entry", definition.NewJunction(
+		definition.NewSymbol("Key"),
+		definition.NewTextToken("="),
+		definition.NewSymbol("Value"),
+		definition.NewOptional(definition.NewSymbol("Flag")),
+	)),
+	definition.NewRule("Value", definition.NewChoice(
+		definition.NewSymbol("Number", map[string][]byte{"kind": []byte("int")}),
+		definition.NewSymbol("Word"),
+	)),
+	definition.NewRule("Key", definition.NewTextPattern("[a-z]+")),
+	definition.NewRule("Number", definition.NewTextPattern("[0-9]+")),
+	definition.NewRule("Word", definition.NewTextPattern("[a-z]+")),
+	definition.NewRule("Flag", definition.NewTextToken("!")),
+}
+
+type upperWord string
+
+func (w *upperWord) UnmarshalText(text []byte) error {
+	*w = upperWord(bytes.ToUpper(text))
+	return nil
+}
+
+type (
+	configNumber struct {
+		Kind    string             `peg:"@attr:kind"`
+		Value   int                `peg:"@text"`
+		Segment definition.Segment `peg:"@segment"`
+	}
+	configValue struct {
+		Text   []byte        `peg:"@text"`
+		Number *configNumber `peg:"Number"`
+		Word   *upperWord    `peg:"Word"`
+	}
+	configEntry struct {
+		Key   string       `peg:"Key"`
+		Value configValue  `peg:"Value"`
+		Flag  *ParsingNode `peg:"Flag"`
+	}
+	config struct {
+		Entries []configEntry `peg:"Entry"`
+	}
+)
+
+func TestUnmarshal(t *testing.T) {
+	node, err := ParseText(configRules, "Config", []byte("a=12!;b=xy;"))
+	require.Nil(t, err)
+	var c config
+	require.Nil(t, Unmarshal(node, &c))
+	require.Len(t, c.Entries, 2)
+	require.Equal(t, "a", c.Entries[0].Key)
+	require.Equal(t, []byte("12"), c.Entries[0].Value.Text)
+	require.Equal(t, &configNumber{Kind: "int", Value: 12, Segment: definition.Segment{Start: 2, End: 4}}, c.Entries[0].Value.Number)
+	require.Nil(t, c.Entries[0].Value.Word)
+	require.Equal(t, "Flag", c.Entries[0].Flag.Atom.Symbol)
+	require.Equal(t, "b", c.Entries[1].Key)
+	require.Nil(t, c.Entries[1].Value.Number)
+	require.Equal(t, upperWord("XY"), *c.Entries[1].Value.Word)
+	require.Nil(t, c.Entries[1].Flag)
+}
+
+func TestUnmarshalErrors(t *testing.T) {
+	node, err := ParseText(configRules, "Config", []byte("a=1;b=300;"))
+	require.Nil(t, err)
+
+	var narrow struct {
+		Entries []struct {
+			Value struct {
+				Number int8 `peg:"Number"`
+			} `peg:"Value"`
+		} `peg:"Entry"`
+	}
+	err = Unmarshal(node, &narrow)
+	var unmarshalErr *UnmarshalError
+	require.True(t, errors.As(err, &unmarshalErr))
+	require.Equal(t, "Number", unmarshalErr.Symbol)
+	require.Equal(t, definition.Segment{Start: 6, End: 9}, unmarshalErr.Segment)
+	require.Equal(t, "Entries.Value.Number", unmarshalErr.Field)
+
+	var single struct {
+		Entry configEntry `peg:"Entry"`
+	}
+	err = Unmarshal(node, &single)
+	require.ErrorContains(t, err, "unexpected amount of symbols 'Entry': 2 > 1")
+
+	var missing struct {
+		Entries []struct {
+			Flag string `peg:"Flag"`
+		} `peg:"Entry"`
+	}
+	err = Unmarshal(node, &missing)
+	require.ErrorContains(t, err, "symbol 'Flag' not found")
+	require.ErrorContains(t, err, "Entry[0..3)")
+
+	require.NotNil(t, Unmarshal(node, single))
+}
@@ -0,0 +1,55 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+var charactersRules = definition.Rules{
+	definition.NewRule("Text", definition.NewRepetition(definition.NewChoice(
+		definition.NewSymbol("Character"),
+		definition.NewSymbol("Invalid"),
+	))),
+	definition.NewRule("Character", definition.NewDot()),
+	definition.NewRule("Invalid", definition.InvalidUTF8{}),
+}
+
+func TestUTF8(t *testing.T) {
+	text := []byte("a·\xffя")
+	for _, strategy := range []Strategy{TableStrategy, LazyStrategy, VMStrategy} {
+		node, err := ParseText(charactersRules, "Text", text, WithStrategy(strategy))
+		require.Nil(t, err)
+		require.Len(t, node.Children, 6)
+
+		node, err = ParseText(charactersRules, "Text", text, WithStrategy(strategy), WithUTF8())
+		require.Nil(t, err)
+		require.Equal(t, "Text[0..6): 'a·\xffя'\n"+
+			"  Character[0..1): 'a'\n"+
+			"  Character[1..3): '·'\n"+
+			"  Invalid[3..4): '\xff'\n"+
+			"  Character[4..6): 'я'\n", StringParsingNode(node))
+	}
+	t.Run("session", func(t *testing.T) {
+		g, err := Compile(charactersRules, WithUTF8())
+		require.Nil(t, err)
+		session, err := g.NewSession([]byte("a\xd1"))
+		require.Nil(t, err)
+		node, err := session.Parse()
+		require.Nil(t, err)
+		require.Equal(t, "Invalid", node.Children[1].Atom.Symbol)
+		node, err = session.Edit(2, 0, []byte("\x8f"))
+		require.Nil(t, err)
+		require.Len(t, node.Children, 2)
+		require.Equal(t, "Character", node.Children[1].Atom.Symbol)
+		require.Equal(t, 2, node.Children[1].Segment.Length())
+	})
+	t.Run("atoms", func(t *testing.T) {
+		rules := definition.Rules{definition.NewRule("Atoms", definition.NewRepetition(definition.NewDot()))}
+		node, err := ParseAtoms(rules, "Atoms", []definition.Atom{{Symbol: "A"}, {Symbol: "B"}}, WithUTF8())
+		require.Nil(t, err)
+		require.Equal(t, 2, node.Segment.Length())
+	})
+}
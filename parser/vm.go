@@ -0,0 +1,280 @@
+package parser
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+type opcode uint8
+
+const (
+	// opEnd finishes the rule successfully
+	opEnd opcode = iota
+	// opChoice pushes backtrack entry which restores current position and jumps to the label on failure
+	opChoice
+	// opCommit drops the top backtrack entry and jumps to the label
+	opCommit
+	// opLoop jumps to the label and moves the top backtrack entry to the current position
+	// or leaves the loop (jumps to the exit) if the last iteration consumed nothing
+	opLoop
+	// opFailTwice drops the top backtrack entry and fails
+	opFailTwice
+	// opCall evaluates another rule through memoization table
+	opCall
+	// opToken matches the text token over byte input
+	opToken
+	// opClass matches the character class over byte input
+	opClass
+	// opPattern matches the regular expression over byte input
+	opPattern
+	// opText matches any other text terminal over byte input
+	opText
+	// opTerminal matches any other terminal
+	opTerminal
+	// opSkip skips input until the sync expression matches (only in recovery mode)
+	opSkip
+)
+
+type (
+	instruction struct {
+		op    opcode
+		arg   int
+		label int
+	}
+	// program is compiled once per grammar and shared between parses
+	program struct {
+		code     []instruction
+		entry    []int
+		tokens   [][]byte
+		classes  []definition.CharClass
+		patterns []definition.TextPattern
+		texts    []definition.TextTerminals
+		exprs    []definition.Expr
+	}
+	backtrack struct {
+		label    int
+		position int
+	}
+)
+
+func (p *program) emit(op opcode, arg, label int) int {
+	p.code = append(p.code, instruction{op: op, arg: arg, label: label})
+	return len(p.code) - 1
+}
+
+func (p *program) emitLeaf(g *grammar, expr definition.Expr) {
+	switch peg := expr.(type) {
+	case definition.Symbol:
+		p.emit(opCall, g.position[peg.Name], 0)
+	case definition.Empty:
+	case definition.TextToken:
+		p.tokens = append(p.tokens, peg.Text)
+		p.emit(opToken, len(p.tokens)-1, 0)
+	case definition.CharClass:
+		p.classes = append(p.classes, peg)
+		p.emit(opClass, len(p.classes)-1, 0)
+	case definition.TextPattern:
+		p.patterns = append(p.patterns, peg)
+		p.emit(opPattern, len(p.patterns)-1, 0)
+	case definition.TextTerminals:
+		p.texts = append(p.texts, peg)
+		p.emit(opText, len(p.texts)-1, 0)
+	case definition.Terminals:
+		p.exprs = append(p.exprs, peg)
+		p.emit(opTerminal, len(p.exprs)-1, 0)
+	default:
+		panic(fmt.Errorf("unexpected peg expression type in normalized rule: %#v", expr))
+	}
+}
+
+// compileProgram translates normalized rules into LPeg-like instructions: every rule has its own entry point
+// and references other rules only with opCall
+func compileProgram(g *grammar) *program {
+	p := &program{entry: make([]int, len(g.order))}
+	for s, expr := range g.exprs {
+		p.entry[s] = len(p.code)
+		switch peg := expr.(type) {
+		case definition.Junction:
+			for _, leaf := range peg.Exprs {
+				p.emitLeaf(g, leaf)
+			}
+		case definition.Choice:
+			commits := make([]int, 0, len(peg.Exprs))
+			for k, leaf := range peg.Exprs {
+				if k == len(peg.Exprs)-1 {
+					p.emitLeaf(g, leaf)
+					break
+				}
+				choice := p.emit(opChoice, 0, 0)
+				p.emitLeaf(g, leaf)
+				commits = append(commits, p.emit(opCommit, 0, 0))
+				p.code[choice].label = len(p.code)
+			}
+			for _, commit := range commits {
+				p.code[commit].label = len(p.code)
+			}
+		case definition.Kleene:
+			choice := p.emit(opChoice, 0, 0)
+			p.emitLeaf(g, peg.Expr)
+			loop := p.emit(opLoop, 0, choice+1)
+			p.code[choice].label = len(p.code)
+			p.code[loop].arg = len(p.code)
+		case definition.Negation:
+			choice := p.emit(opChoice, 0, 0)
+			p.emitLeaf(g, peg.Expr)
+			p.emit(opFailTwice, 0, 0)
+			p.code[choice].label = len(p.code)
+		case definition.Recovery:
+			choice := p.emit(opChoice, 0, 0)
+			p.emitLeaf(g, peg.Expr)
+			commit := p.emit(opCommit, 0, 0)
+			p.code[choice].label = len(p.code)
+			p.exprs = append(p.exprs, peg.Sync)
+			p.emit(opSkip, len(p.exprs)-1, 0)
+			p.code[commit].label = len(p.code)
+		default:
+			p.emitLeaf(g, peg)
+		}
+		p.emit(opEnd, 0, 0)
+	}
+	return p
+}
+
+// machine executes rule programs and stores their results in the dense table indexed by position and rule:
+// every cell holds 0 if it wasn't evaluated yet, -1 if the rule fails and the advance increased by one otherwise
+type machine[T any] struct {
+	*evaluator[T]
+	program *program
+	text    []byte
+	memo    []int32
+	rules   int
+	history recursionHistory
+	stack   []backtrack
+}
+
+func newMachine[T any](e *evaluator[T]) *machine[T] {
+	m := &machine[T]{evaluator: e, program: e.grammar.program, rules: len(e.grammar.order), history: newRecursionHistory()}
+	if len(e.data) >= math.MaxInt32 {
+		e.err = fmt.Errorf("input of length %v is too long for the VM strategy", len(e.data))
+		return m
+	}
+	m.text, _ = any(e.data).([]byte)
+	m.memo = make([]int32, (len(e.data)+1)*m.rules)
+	e.steps = m
+	return m
+}
+
+func (m *machine[T]) store(i, s int, value step) {
+	if value.ok {
+		m.memo[i*m.rules+s] = int32(value.advance) + 1
+	} else {
+		m.memo[i*m.rules+s] = -1
+	}
+}
+
+func (m *machine[T]) versionBefore(i, s, seq int) version {
+	return m.history.versionBefore(cell{position: i, rule: s}, seq)
+}
+
+func (m *machine[T]) stepAt(i, s int) step {
+	if value := m.memo[i*m.rules+s]; value != 0 {
+		return step{ok: value > 0, advance: max(int(value)-1, 0)}
+	}
+	if !m.account() {
+		return step{}
+	}
+	if m.grammar.recursive[s] {
+		component := m.grammar.components[m.grammar.componentOf[s]]
+		m.grow(i, component, &m.history, m, m.execute)
+		return m.stepAt(i, s)
+	}
+	m.enter(i, s)
+	result := m.execute(i, s)
+	m.store(i, s, result)
+	m.exit(i, s, result)
+	return result
+}
+
+func (m *machine[T]) execute(i, s int) step {
+	code := m.program.code
+	base := len(m.stack)
+	position, pc := i, m.program.entry[s]
+	for {
+		ok := true
+		switch in := code[pc]; in.op {
+		case opEnd:
+			m.stack = m.stack[:base]
+			return step{ok: true, advance: position - i}
+		case opChoice:
+			m.stack = append(m.stack, backtrack{label: in.label, position: position})
+			pc++
+		case opCommit:
+			m.stack = m.stack[:len(m.stack)-1]
+			pc = in.label
+		case opLoop:
+			if top := &m.stack[len(m.stack)-1]; top.position != position {
+				top.position = position
+				pc = in.label
+			} else {
+				m.stack = m.stack[:len(m.stack)-1]
+				pc = in.arg
+			}
+		case opFailTwice:
+			m.stack = m.stack[:len(m.stack)-1]
+			ok = false
+		case opCall:
+			next := m.stepAt(position, in.arg)
+			ok = next.ok
+			position += next.advance
+			pc++
+		case opToken:
+			token := m.program.tokens[in.arg]
+			ok = bytes.HasPrefix(m.text[position:], token)
+			position += len(token)
+			pc++
+		case opClass:
+			var advance int
+			advance, ok = m.program.classes[in.arg].Match(m.text[position:])
+			position += advance
+			pc++
+		case opPattern:
+			if m.profiler != nil {
+				next := m.advance(position, m.program.patterns[in.arg])
+				ok, position = next.ok, position+next.advance
+			} else {
+				var advance int
+				advance, ok = m.program.patterns[in.arg].Match(m.text[position:])
+				position += advance
+			}
+			pc++
+		case opText:
+			var advance int
+			advance, ok = m.program.texts[in.arg].Match(m.text[position:])
+			position += advance
+			pc++
+		case opTerminal:
+			next := m.advance(position, m.program.exprs[in.arg])
+			ok = next.ok
+			position += next.advance
+			pc++
+		case opSkip:
+			ok = m.options.recovering
+			for ok && position < len(m.data) && !m.advance(position, m.program.exprs[in.arg]).ok {
+				position++
+			}
+			pc++
+		}
+		if ok {
+			continue
+		}
+		if len(m.stack) == base {
+			return step{}
+		}
+		top := m.stack[len(m.stack)-1]
+		m.stack = m.stack[:len(m.stack)-1]
+		position, pc = top.position, top.label
+	}
+}
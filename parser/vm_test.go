@@ -0,0 +1,76 @@
+package parser
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/sivukhin/gopeg/definition"
+)
+
+func TestVMStrategy(t *testing.T) {
+	leftRecursiveRules := definition.Rules{
+		definition.NewRule("Sum", definition.NewChoice(
+			definition.NewJunction(definition.NewSymbol("Sum"), definition.NewTextToken("-"), definition.NewSymbol("Num")),
+			definition.NewSymbol("Num"),
+		)),
+		definition.NewRule("Num", definition.NewTextPattern("[0-9]+")),
+	}
+	textRules := definition.Rules{
+		definition.NewRule("Words", definition.NewRepetition(definition.NewChoice(
+			definition.NewFoldedToken("select"),
+			definition.NewRepetitionN(definition.NewCharClass("[a-zа-я]"), 1),
+			definition.NewCharClass("[^a-zа-я]"),
+		))),
+	}
+	grammars := []struct {
+		rules definition.Rules
+		root  string
+		texts []string
+	}{
+		{rules: arithmeticRules, root: "Expr", texts: []string{"10+2", "1+(2*4-5)-10*44", "1+(2*4-5", "+1", ""}},
+		{rules: leftRecursiveRules, root: "Sum", texts: []string{"7-2-1", "7-", "-"}},
+		{rules: statementRules, root: "Statements", texts: []string{"1+2;1+*;3*4;", "1;2+"}},
+		{rules: textRules, root: "Words", texts: []string{"SELECT слово, x", "Sel\xffect"}},
+	}
+	for _, grammar := range grammars {
+		for _, text := range grammar.texts {
+			t.Run(text, func(t *testing.T) {
+				table, tableErr := ParseText(grammar.rules, grammar.root, []byte(text), WithStrategy(TableStrategy))
+				vm, vmErr := ParseText(grammar.rules, grammar.root, []byte(text), WithStrategy(VMStrategy))
+				require.Equal(t, tableErr, vmErr)
+				require.Equal(t, table, vm)
+
+				table, tableDiagnostics, tableErr := ParseTextWithRecovery(grammar.rules, grammar.root, []byte(text), WithStrategy(TableStrategy))
+				vm, vmDiagnostics, vmErr := ParseTextWithRecovery(grammar.rules, grammar.root, []byte(text), WithStrategy(VMStrategy))
+				require.Equal(t, tableErr, vmErr)
+				require.Equal(t, tableDiagnostics, vmDiagnostics)
+				require.Equal(t, table, vm)
+			})
+		}
+	}
+	t.Run("atoms", func(t *testing.T) {
+		rules := definition.Rules{
+			definition.NewRule("Call", definition.NewJunction(
+				definition.NewSymbol("Name"),
+				definition.NewAtomPattern(map[string]definition.TextTerminals{"Open": nil}),
+				definition.NewOptional(definition.NewSymbol("Name")),
+				definition.NewAtomPattern(map[string]definition.TextTerminals{"Close": nil}),
+			)),
+			definition.NewRule("Name", definition.NewAtomPattern(map[string]definition.TextTerminals{"Token": nil})),
+		}
+		text := []byte("f(x)")
+		atoms := []definition.Atom{
+			{Symbol: "Token", Text: text, TextSelector: definition.BuildSegments(definition.Segment{Start: 0, End: 1})},
+			{Symbol: "Open", Text: text, TextSelector: definition.BuildSegments(definition.Segment{Start: 1, End: 2})},
+			{Symbol: "Token", Text: text, TextSelector: definition.BuildSegments(definition.Segment{Start: 2, End: 3})},
+			{Symbol: "Close", Text: text, TextSelector: definition.BuildSegments(definition.Segment{Start: 3, End: 4})},
+		}
+		table, err := ParseAtoms(rules, "Call", atoms, WithStrategy(TableStrategy))
+		require.Nil(t, err)
+		vm, err := ParseAtoms(rules, "Call", atoms, WithStrategy(VMStrategy))
+		require.Nil(t, err)
+		require.Equal(t, table, vm)
+		require.Len(t, vm.Children, 2)
+	})
+}
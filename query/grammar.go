@@ -0,0 +1,72 @@
+package query
+
+import (
+	"github.com/sivukhin/gopeg/definition"
+)
+
+const (
+	QueryPath       = "Path"
+	QuerySpaces     = "#Spaces"
+	QueryStep       = "Step"
+	QueryAxis       = "Axis"
+	QueryName       = "Name"
+	QueryQuotedName = "QuotedName"
+	QueryWildcard   = "Wildcard"
+	QueryPredicate  = "Predicate"
+	QueryAttribute  = "Attribute"
+	QueryText       = "Text"
+	QueryOperator   = "Operator"
+	QueryString     = "String"
+	QueryCapture    = "Capture"
+)
+
+var QueryRules = definition.Rules{
+	definition.NewRule(QueryPath, definition.NewJunction(
+		definition.NewSymbol(QuerySpaces),
+		definition.NewSymbol(QueryStep),
+		definition.NewRepetition(definition.NewJunction(definition.NewSymbol(QuerySpaces), definition.NewSymbol(QueryStep))),
+		definition.NewSymbol(QuerySpaces),
+	)),
+	definition.NewRule(QuerySpaces, definition.NewTextPattern("[ \t\r\n]*")),
+	definition.NewRule(QueryStep, definition.NewJunction(
+		definition.NewOptional(definition.NewSymbol(QueryAxis)),
+		definition.NewChoice(definition.NewSymbol(QueryName), definition.NewSymbol(QueryQuotedName), definition.NewSymbol(QueryWildcard)),
+		definition.NewRepetition(definition.NewSymbol(QueryPredicate)),
+		definition.NewOptional(definition.NewJunction(definition.NewSymbol(QuerySpaces), definition.NewSymbol(QueryCapture))),
+	)),
+	definition.NewRule(QueryAxis, definition.NewChoice(definition.NewTextToken("//"), definition.NewTextToken("/"))),
+	definition.NewRule(QueryName, definition.NewTextPattern(`[#a-zA-Z_][#a-zA-Z0-9_]*(\.[a-zA-Z_][a-zA-Z0-9_]*)*`)),
+	definition.NewRule(QueryQuotedName, definition.NewTextPattern(`"(\\.|[^"\\])*"`)),
+	definition.NewRule(QueryWildcard, definition.NewTextToken("*")),
+	definition.NewRule(QueryPredicate, definition.NewJunction(
+		definition.NewTextToken("["),
+		definition.NewSymbol(QuerySpaces),
+		definition.NewChoice(
+			definition.NewSymbol(QueryAttribute),
+			definition.NewSymbol(QueryText),
+			definition.NewSymbol(QueryPath),
+		),
+		definition.NewSymbol(QuerySpaces),
+		definition.NewTextToken("]"),
+	)),
+	definition.NewRule(QueryAttribute, definition.NewJunction(
+		definition.NewTextToken("@"),
+		definition.NewSymbol(QueryName),
+		definition.NewOptional(definition.NewJunction(
+			definition.NewSymbol(QuerySpaces),
+			definition.NewSymbol(QueryOperator),
+			definition.NewSymbol(QuerySpaces),
+			definition.NewSymbol(QueryString),
+		)),
+	)),
+	definition.NewRule(QueryText, definition.NewJunction(
+		definition.NewTextToken("text()"),
+		definition.NewSymbol(QuerySpaces),
+		definition.NewSymbol(QueryOperator),
+		definition.NewSymbol(QuerySpaces),
+		definition.NewSymbol(QueryString),
+	)),
+	definition.NewRule(QueryOperator, definition.NewChoice(definition.NewTextToken("=~"), definition.NewTextToken("="))),
+	definition.NewRule(QueryString, definition.NewTextPattern(`"(\\.|[^"\\])*"`)),
+	definition.NewRule(QueryCapture, definition.NewJunction(definition.NewTextToken("$"), definition.NewSymbol(QueryName))),
+}
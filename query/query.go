@@ -0,0 +1,268 @@
+package query
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/parser"
+)
+
+var queryGrammar = mustCompile(QueryRules, QueryPath)
+
+func mustCompile(rules definition.Rules, root string) *parser.Grammar {
+	grammar, err := parser.Compile(rules, parser.WithRoot(root), parser.WithMatchMode(parser.FullMatch))
+	if err != nil {
+		panic(fmt.Errorf("unable to compile %v rules: %w", root, err))
+	}
+	return grammar
+}
+
+type (
+	quotedString string
+	pathNode     struct {
+		Steps []stepNode `peg:"Step"`
+	}
+	stepNode struct {
+		Axis       *string         `peg:"Axis"`
+		Name       *string         `peg:"Name"`
+		QuotedName *quotedString   `peg:"QuotedName"`
+		Predicates []predicateNode `peg:"Predicate"`
+		Capture    *struct {
+			Name string `peg:"Name"`
+		} `peg:"Capture"`
+	}
+	predicateNode struct {
+		Attribute *attributeNode `peg:"Attribute"`
+		Text      *textNode      `peg:"Text"`
+		Path      *pathNode      `peg:"Path"`
+	}
+	attributeNode struct {
+		Name     string        `peg:"Name"`
+		Operator *string       `peg:"Operator"`
+		Value    *quotedString `peg:"String"`
+	}
+	textNode struct {
+		Operator string       `peg:"Operator"`
+		Value    quotedString `peg:"String"`
+	}
+)
+
+func (s *quotedString) UnmarshalText(text []byte) error {
+	value, err := strconv.Unquote(string(text))
+	*s = quotedString(value)
+	return err
+}
+
+type (
+	// Query is an immutable compiled query which can be matched against many trees
+	Query struct {
+		text string
+		path path
+	}
+	// Match holds the node selected by the last step of the query and the nodes captured with $name along the way
+	Match struct {
+		Node     *parser.ParsingNode
+		Captures map[string]*parser.ParsingNode
+	}
+	path []step
+	step struct {
+		descendant bool
+		symbol     string
+		predicates []predicate
+		capture    string
+	}
+	// predicate checks the node and records captures of the nested paths
+	predicate func(node *parser.ParsingNode, captures map[string]*parser.ParsingNode) bool
+)
+
+// Compile parses the query which selects nodes with steps similar to XPath location paths:
+//   - Name or * selects children of the context node (or the root node for the first step), //Name selects descendants
+//   - qualified names of imported rules (alias.Name) can be written as is, any other name can be quoted: "List<Item>"
+//   - [@key], [@key="value"] and [@key=~"regex"] check attribute of the node
+//   - [text()="value"] and [text()=~"regex"] check text of the node
+//   - [Path] checks that relative path matches at least one node
+//   - $name captures the node selected by the step
+func Compile(text string) (*Query, error) {
+	node, err := queryGrammar.ParseText([]byte(text))
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse query: %w", err)
+	}
+	var root pathNode
+	if err := parser.Unmarshal(node, &root); err != nil {
+		return nil, fmt.Errorf("unable to parse query: %w", err)
+	}
+	compiled, err := compilePath(root)
+	if err != nil {
+		return nil, err
+	}
+	return &Query{text: text, path: compiled}, nil
+}
+
+func MustCompile(text string) *Query {
+	q, err := Compile(text)
+	if err != nil {
+		panic(err)
+	}
+	return q
+}
+
+func (q *Query) String() string { return q.text }
+
+func compilePath(node pathNode) (path, error) {
+	compiled := make(path, 0, len(node.Steps))
+	for _, s := range node.Steps {
+		current := step{descendant: s.Axis != nil && *s.Axis == "//"}
+		if s.Name != nil {
+			current.symbol = *s.Name
+		}
+		if s.QuotedName != nil {
+			current.symbol = string(*s.QuotedName)
+		}
+		if s.Capture != nil {
+			current.capture = s.Capture.Name
+		}
+		for _, p := range s.Predicates {
+			compiled, err := compilePredicate(p)
+			if err != nil {
+				return nil, err
+			}
+			current.predicates = append(current.predicates, compiled)
+		}
+		compiled = append(compiled, current)
+	}
+	return compiled, nil
+}
+
+func compilePredicate(node predicateNode) (predicate, error) {
+	switch {
+	case node.Attribute != nil:
+		key := node.Attribute.Name
+		if node.Attribute.Operator == nil {
+			return func(n *parser.ParsingNode, _ map[string]*parser.ParsingNode) bool {
+				_, ok := n.Atom.Attributes[key]
+				return ok
+			}, nil
+		}
+		check, err := compileCondition(*node.Attribute.Operator, string(*node.Attribute.Value))
+		if err != nil {
+			return nil, err
+		}
+		return func(n *parser.ParsingNode, _ map[string]*parser.ParsingNode) bool {
+			value, ok := n.Atom.Attributes[key]
+			return ok && check(value)
+		}, nil
+	case node.Text != nil:
+		check, err := compileCondition(node.Text.Operator, string(node.Text.Value))
+		if err != nil {
+			return nil, err
+		}
+		return func(n *parser.ParsingNode, _ map[string]*parser.ParsingNode) bool {
+			return check(n.Atom.SelectText())
+		}, nil
+	default:
+		nested, err := compilePath(*node.Path)
+		if err != nil {
+			return nil, err
+		}
+		return func(n *parser.ParsingNode, captures map[string]*parser.ParsingNode) bool {
+			matches := nested.match(n, captures, 1)
+			if len(matches) == 0 {
+				return false
+			}
+			for name, captured := range matches[0].Captures {
+				captures[name] = captured
+			}
+			return true
+		}, nil
+	}
+}
+
+func compileCondition(operator, expected string) (func(value []byte) bool, error) {
+	if operator == "=" {
+		return func(value []byte) bool { return string(value) == expected }, nil
+	}
+	regex, err := regexp.Compile(expected)
+	if err != nil {
+		return nil, fmt.Errorf("invalid regex in query: %w", err)
+	}
+	return regex.Match, nil
+}
+
+func (s step) accept(node *parser.ParsingNode, captures map[string]*parser.ParsingNode) (map[string]*parser.ParsingNode, bool) {
+	if s.symbol != "" && node.Atom.Symbol != s.symbol {
+		return nil, false
+	}
+	next := make(map[string]*parser.ParsingNode, len(captures)+1)
+	for name, captured := range captures {
+		next[name] = captured
+	}
+	for _, p := range s.predicates {
+		if !p(node, next) {
+			return nil, false
+		}
+	}
+	if s.capture != "" {
+		next[s.capture] = node
+	}
+	return next, true
+}
+
+// match returns up to limit matches of the path relative to the context node (limit < 0 means no limit)
+func (p path) match(context *parser.ParsingNode, captures map[string]*parser.ParsingNode, limit int) []Match {
+	if len(p) == 0 {
+		return []Match{{Node: context, Captures: captures}}
+	}
+	matches := make([]Match, 0)
+	var visit func(node *parser.ParsingNode) bool
+	visit = func(node *parser.ParsingNode) bool {
+		for _, child := range node.Children {
+			if next, ok := p[0].accept(child, captures); ok {
+				matches = append(matches, p[1:].match(child, next, limit-len(matches))...)
+			}
+			if limit >= 0 && len(matches) >= limit {
+				return false
+			}
+			if p[0].descendant && !visit(child) {
+				return false
+			}
+		}
+		return true
+	}
+	visit(context)
+	return matches
+}
+
+// FindAll returns matches of the query in the tree in the document order
+func (q *Query) FindAll(root *parser.ParsingNode) []Match {
+	document := &parser.ParsingNode{Children: []*parser.ParsingNode{root}}
+	matches := q.path.match(document, map[string]*parser.ParsingNode{}, -1)
+	unique := make([]Match, 0, len(matches))
+	seen := make(map[string]struct{}, len(matches))
+	for _, m := range matches {
+		key := matchKey(m)
+		if _, ok := seen[key]; ok {
+			continue
+		}
+		seen[key] = struct{}{}
+		unique = append(unique, m)
+	}
+	return unique
+}
+
+func matchKey(m Match) string {
+	names := make([]string, 0, len(m.Captures))
+	for name := range m.Captures {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	var key strings.Builder
+	fmt.Fprintf(&key, "%p", m.Node)
+	for _, name := range names {
+		fmt.Fprintf(&key, " %v=%p", name, m.Captures[name])
+	}
+	return key.String()
+}
@@ -0,0 +1,103 @@
+package query
+
+import (
+	"testing"
+	"testing/fstest"
+
+	"github.com/sivukhin/gopeg/definition"
+	"github.com/sivukhin/gopeg/extension"
+	"github.com/sivukhin/gopeg/parser"
+	"github.com/stretchr/testify/require"
+)
+
+func parsePeg(t *testing.T, text string) *parser.ParsingNode {
+	tokens, err := parser.ParseText(extension.PegTokenizerRules, extension.PegText, []byte(text))
+	require.Nil(t, err)
+	atoms := make([]definition.Atom, 0, len(tokens.Children))
+	for _, child := range tokens.Children {
+		atoms = append(atoms, child.Atom)
+	}
+	node, err := parser.ParseAtoms(extension.PegGrammarRules, extension.PegDefinitions, atoms, parser.WithMatchMode(parser.FullMatch))
+	require.Nil(t, err)
+	return node
+}
+
+func texts(nodes ...*parser.ParsingNode) []string {
+	result := make([]string, 0, len(nodes))
+	for _, node := range nodes {
+		result = append(result, node.Atom.SelectString())
+	}
+	return result
+}
+
+func TestFindAll(t *testing.T) {
+	node := parsePeg(t, `A: {Token:"x"}* B+ {Token}*
+B: "b" C* ({Token}*)?
+`)
+	t.Run("descendants", func(t *testing.T) {
+		matches := MustCompile("//Definition/Name").FindAll(node)
+		require.Len(t, matches, 2)
+		require.Equal(t, []string{"A", "B"}, texts(matches[0].Node, matches[1].Node))
+	})
+	t.Run("root", func(t *testing.T) {
+		require.Len(t, MustCompile("Definitions").FindAll(node), 1)
+		require.Len(t, MustCompile("Definition").FindAll(node), 0)
+		require.Len(t, MustCompile("/Definitions/Definition").FindAll(node), 2)
+	})
+	t.Run("junction with map under kleene", func(t *testing.T) {
+		matches := MustCompile(`//Junction[Suffix[text()="*"]][Expression/Map$map] $junction`).FindAll(node)
+		require.Len(t, matches, 3)
+		require.Equal(t, []string{`{Token:"x"}*`, "{Token}*", "{Token}*"}, texts(matches[0].Node, matches[1].Node, matches[2].Node))
+		require.Equal(t, matches[0].Node, matches[0].Captures["junction"])
+		require.Equal(t, `{Token:"x"}`, matches[0].Captures["map"].Atom.SelectString())
+	})
+	t.Run("captures along the path", func(t *testing.T) {
+		matches := MustCompile(`//Definition[Name[text()=~"^B$"]]$definition//Suffix[text()="*"]`).FindAll(node)
+		require.Len(t, matches, 2)
+		for _, m := range matches {
+			require.Equal(t, "B", m.Captures["definition"].MustSelectBySymbol(extension.PegName).Atom.SelectString())
+		}
+	})
+	t.Run("wildcard", func(t *testing.T) {
+		matches := MustCompile(`//Junction/*[text()="+"]`).FindAll(node)
+		require.Len(t, matches, 1)
+		require.Equal(t, extension.PegSuffix, matches[0].Node.Atom.Symbol)
+	})
+}
+
+func TestAttributes(t *testing.T) {
+	rules := definition.Rules{
+		definition.NewRule("A", definition.NewRepetition(definition.NewChoice(
+			definition.NewJunction(definition.NewTextToken("B"), definition.NewSymbol("D", map[string][]byte{"Ctx": []byte("B")})),
+			definition.NewJunction(definition.NewTextToken("C"), definition.NewSymbol("D", map[string][]byte{"Ctx": []byte("C"), "Last": nil})),
+		))),
+		definition.NewRule("D", definition.NewTextToken(".")),
+	}
+	node, err := parser.ParseText(rules, "A", []byte("B.C.B."))
+	require.Nil(t, err)
+	require.Len(t, MustCompile(`A/D[@Ctx="B"]`).FindAll(node), 2)
+	require.Len(t, MustCompile(`A/D[@Ctx=~"[BC]"]`).FindAll(node), 3)
+	require.Len(t, MustCompile(`A/D[ @Last ]`).FindAll(node), 1)
+	require.Len(t, MustCompile(`A/D[@Missing]`).FindAll(node), 0)
+}
+
+func TestQualifiedNames(t *testing.T) {
+	fsys := fstest.MapFS{"common.peg": {Data: []byte("Number: [0-9]+")}}
+	rules, err := extension.Load("@import \"common.peg\" as c\nList<Item, Separator>: Item (Separator Item)*\nValues: List<c.Number, \",\">", extension.WithFS(fsys))
+	require.Nil(t, err)
+	node, err := parser.ParseText(rules, "Values", []byte("1,22,3"))
+	require.Nil(t, err)
+	matches := MustCompile(`//c.Number`).FindAll(node)
+	require.Len(t, matches, 3)
+	require.Equal(t, []string{"1", "22", "3"}, texts(matches[0].Node, matches[1].Node, matches[2].Node))
+	require.Len(t, MustCompile(`Values/"List<c.Number, \",\">"/c.Number`).FindAll(node), 3)
+}
+
+func TestCompileErrors(t *testing.T) {
+	_, err := Compile("//A[")
+	require.ErrorIs(t, err, parser.TextNotMatchErr)
+	_, err = Compile(`//A[text()=~"("]`)
+	require.ErrorContains(t, err, "invalid regex in query")
+	_, err = Compile("")
+	require.NotNil(t, err)
+}